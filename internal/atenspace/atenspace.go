@@ -10,8 +10,15 @@
 package atenspace
 
 import (
+	"container/heap"
 	"context"
+	stderrors "errors"
 	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,12 +34,37 @@ type Space struct {
 	// Links are the edges in the hypergraph (relationships between entities)
 	links []*Link
 
+	// linksByID indexes links by their ID
+	linksByID map[string]*Link
+
+	// outgoing and incoming index links by their Source and Target atom IDs
+	outgoing map[string][]*Link
+	incoming map[string][]*Link
+
+	// atomsByType and linksByType index atoms and links by their Type
+	atomsByType map[AtomType]map[string]*Atom
+	linksByType map[LinkType][]*Link
+
 	// TensorStore maps atoms to their tensor representations
 	tensorStore map[string]*Tensor
 
+	// tensorRefs counts the atoms referencing each tensor ID
+	tensorRefs map[string]int
+
+	// tensorMoves records every MoveTensor in order
+	tensorMoves []TensorMove
+
 	// Boundaries define the domain boundaries (from Boundary domain model)
 	boundaries []*DomainBoundary
 
+	// boundariesByAtom indexes boundaries by the atom IDs they contain
+	boundariesByAtom map[string][]*DomainBoundary
+
+	// authorizer, when set, decides which atoms queries may return; see
+	// SetAuthorizer
+	authorizer          Authorizer
+	authorizationErrors bool
+
 	// mu protects concurrent access
 	mu sync.RWMutex
 }
@@ -57,6 +89,9 @@ type Atom struct {
 
 	// CreatedAt timestamp
 	CreatedAt time.Time
+
+	// UpdatedAt is when the atom was added or its attributes last changed
+	UpdatedAt time.Time
 }
 
 // AtomType defines the type of atom in the space.
@@ -96,6 +131,10 @@ type Link struct {
 	// Strength represents the link strength (0.0 to 1.0)
 	Strength float64
 
+	// Bidirectional marks a symmetric link that can be traversed from
+	// Target to Source as well. Links are directed by default.
+	Bidirectional bool
+
 	// CreatedAt timestamp
 	CreatedAt time.Time
 }
@@ -131,7 +170,8 @@ type Tensor struct {
 	// Data holds the tensor data (flattened)
 	Data []float64
 
-	// DType is the data type
+	// DType is the data type: Float64DType, Float32DType, Int64DType or
+	// Int32DType. Empty means Float64DType.
 	DType string
 
 	// Device specifies where the tensor is stored (cpu, cuda, etc.)
@@ -174,6 +214,36 @@ const (
 	LogicalBoundary BoundaryType = "logical"
 )
 
+// Devices a tensor can be placed on. A tensor with an empty Device is on
+// the CPU.
+const (
+	CPUDevice  = "cpu"
+	CUDADevice = "cuda"
+	MPSDevice  = "mps"
+)
+
+// Data types a tensor can have. Data is always held as float64; a tensor of
+// another type holds only values that type can represent.
+const (
+	Float64DType = "float64"
+	Float32DType = "float32"
+	Int64DType   = "int64"
+	Int32DType   = "int32"
+)
+
+// TensorMove records a tensor being moved between devices by MoveTensor.
+type TensorMove struct {
+	// TensorID is the tensor that was moved
+	TensorID string
+
+	// From and To are the devices before and after the move
+	From string
+	To   string
+
+	// MovedAt is when the move happened
+	MovedAt time.Time
+}
+
 // NewSpace creates a new ATenSpace instance.
 func NewSpace(ctx context.Context) (*Space, error) {
 	const op = "atenspace.NewSpace"
@@ -181,14 +251,23 @@ func NewSpace(ctx context.Context) (*Space, error) {
 	s := &Space{
 		atoms:       make(map[string]*Atom),
 		links:       make([]*Link, 0),
+		linksByID:   make(map[string]*Link),
+		outgoing:    make(map[string][]*Link),
+		incoming:    make(map[string][]*Link),
+		atomsByType: make(map[AtomType]map[string]*Atom),
+		linksByType: make(map[LinkType][]*Link),
 		tensorStore: make(map[string]*Tensor),
+		tensorRefs:  make(map[string]int),
 		boundaries:  make([]*DomainBoundary, 0),
+
+		boundariesByAtom: make(map[string][]*DomainBoundary),
 	}
 
 	return s, nil
 }
 
-// AddAtom adds a new atom to the space.
+// AddAtom adds a new atom to the space. It errors if an atom with the same
+// ID already exists; use UpsertAtom to replace one.
 func (s *Space) AddAtom(ctx context.Context, atom *Atom) error {
 	const op = "atenspace.(Space).AddAtom"
 
@@ -202,22 +281,265 @@ func (s *Space) AddAtom(ctx context.Context, atom *Atom) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if _, ok := s.atoms[atom.ID]; ok {
+		return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("atom %s already exists", atom.ID))
+	}
 	atom.CreatedAt = time.Now()
+	atom.UpdatedAt = atom.CreatedAt
+	s.insertAtom(atom)
+	return nil
+}
+
+// UpsertAtom adds atom to the space, replacing any atom with the same ID.
+// A replacement keeps the replaced atom's CreatedAt and sets UpdatedAt.
+// Links to and from that ID are kept. The replaced atom's tensor is released
+// unless the new atom or another atom still references it.
+func (s *Space) UpsertAtom(ctx context.Context, atom *Atom) error {
+	const op = "atenspace.(Space).UpsertAtom"
+
+	if atom == nil {
+		return errors.New(ctx, errors.InvalidParameter, op, "atom is nil")
+	}
+	if atom.ID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "atom ID is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, replaced := s.atoms[atom.ID]
+	atom.UpdatedAt = time.Now()
+	atom.CreatedAt = atom.UpdatedAt
+	if replaced {
+		atom.CreatedAt = existing.CreatedAt
+	}
+	s.insertAtom(atom)
+	if replaced && existing.TensorID != "" && existing.TensorID != atom.TensorID {
+		s.releaseTensor(existing.TensorID)
+	}
+	return nil
+}
+
+// AddAtoms adds atoms in a single locked pass. An atom that is nil, has an
+// empty ID or repeats the ID of an existing atom or an earlier atom in the
+// batch is skipped and the rest are added. The returned error joins one
+// error per skipped atom, identifying it by position and ID. With
+// WithAllOrNothing no atom is added if any is invalid.
+func (s *Space) AddAtoms(ctx context.Context, atoms []*Atom, opt ...Option) error {
+	const op = "atenspace.(Space).AddAtoms"
+	opts := getOpts(opt...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	valid := make([]*Atom, 0, len(atoms))
+	seen := make(map[string]struct{}, len(atoms))
+	for i, atom := range atoms {
+		var err error
+		switch {
+		case atom == nil:
+			err = errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("atom %d is nil", i))
+		case atom.ID == "":
+			err = errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("atom %d has an empty ID", i))
+		default:
+			_, exists := s.atoms[atom.ID]
+			_, repeated := seen[atom.ID]
+			if exists || repeated {
+				err = errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("atom %d: duplicate atom ID %s", i, atom.ID))
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		seen[atom.ID] = struct{}{}
+		valid = append(valid, atom)
+	}
+	if len(errs) > 0 && opts.withAllOrNothing {
+		return stderrors.Join(errs...)
+	}
+
+	now := time.Now()
+	for _, atom := range valid {
+		atom.CreatedAt = now
+		atom.UpdatedAt = now
+		s.insertAtom(atom)
+	}
+	return stderrors.Join(errs...)
+}
+
+// RenameAtom changes the ID of the atom oldID to newID. Links keep their
+// IDs but their Source and Target follow the atom, as do the AtomIDs of the
+// boundaries it belongs to.
+func (s *Space) RenameAtom(ctx context.Context, oldID, newID string) error {
+	const op = "atenspace.(Space).RenameAtom"
+
+	if newID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "new atom ID is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	atom, ok := s.atoms[oldID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("atom %s not found", oldID))
+	}
+	if _, ok := s.atoms[newID]; ok {
+		return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("atom %s already exists", newID))
+	}
+
+	delete(s.atoms, oldID)
+	s.unindexAtom(atom)
+	atom.ID = newID
+	s.insertAtom(atom)
+
+	// a self-link appears in both indexes, so update the ends after moving
+	// the index entries
+	outgoing, incoming := s.outgoing[oldID], s.incoming[oldID]
+	delete(s.outgoing, oldID)
+	delete(s.incoming, oldID)
+	if outgoing != nil {
+		s.outgoing[newID] = outgoing
+	}
+	if incoming != nil {
+		s.incoming[newID] = incoming
+	}
+	for _, link := range outgoing {
+		link.Source = newID
+	}
+	for _, link := range incoming {
+		link.Target = newID
+	}
+
+	if boundaries, ok := s.boundariesByAtom[oldID]; ok {
+		for _, boundary := range boundaries {
+			for i, id := range boundary.AtomIDs {
+				if id == oldID {
+					boundary.AtomIDs[i] = newID
+				}
+			}
+		}
+		delete(s.boundariesByAtom, oldID)
+		s.boundariesByAtom[newID] = boundaries
+	}
+	return nil
+}
+
+// insertAtom stores atom, replacing any atom with the same ID, and indexes
+// it by type. The caller must hold s.mu.
+func (s *Space) insertAtom(atom *Atom) {
 	if atom.Attributes == nil {
 		atom.Attributes = make(map[string]interface{})
 	}
 
+	if existing, ok := s.atoms[atom.ID]; ok {
+		s.unindexAtom(existing)
+	}
 	s.atoms[atom.ID] = atom
+	if s.atomsByType[atom.Type] == nil {
+		s.atomsByType[atom.Type] = make(map[string]*Atom)
+	}
+	s.atomsByType[atom.Type][atom.ID] = atom
+	s.retainTensor(atom.TensorID)
+}
+
+// UpdateAtom merges attrs into the atom's Attributes, overwriting the
+// values of keys that are already present, and sets its UpdatedAt.
+func (s *Space) UpdateAtom(ctx context.Context, atomID string, attrs map[string]interface{}) error {
+	const op = "atenspace.(Space).UpdateAtom"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	atom, ok := s.atoms[atomID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("atom %s not found", atomID))
+	}
+	maps.Copy(atom.Attributes, attrs)
+	atom.UpdatedAt = time.Now()
+	return nil
+}
+
+// ReplaceAttributes replaces the atom's Attributes with a copy of attrs and
+// sets its UpdatedAt.
+func (s *Space) ReplaceAttributes(ctx context.Context, atomID string, attrs map[string]interface{}) error {
+	const op = "atenspace.(Space).ReplaceAttributes"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	atom, ok := s.atoms[atomID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("atom %s not found", atomID))
+	}
+	atom.Attributes = make(map[string]interface{}, len(attrs))
+	maps.Copy(atom.Attributes, attrs)
+	atom.UpdatedAt = time.Now()
+	return nil
+}
+
+// unindexAtom removes atom from the type index and drops its tensor
+// reference. The tensor itself is left to releaseTensor. The caller must hold
+// s.mu.
+func (s *Space) unindexAtom(atom *Atom) {
+	delete(s.atomsByType[atom.Type], atom.ID)
+	if len(s.atomsByType[atom.Type]) == 0 {
+		delete(s.atomsByType, atom.Type)
+	}
+	s.dropTensorRef(atom.TensorID)
+}
+
+// RemoveAtom removes an atom together with every link it is the Source or
+// Target of, its attached tensor and its membership in any domain boundary.
+// A tensor still referenced by another atom is kept.
+func (s *Space) RemoveAtom(ctx context.Context, atomID string) error {
+	const op = "atenspace.(Space).RemoveAtom"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	atom, ok := s.atoms[atomID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("atom %s not found", atomID))
+	}
+	delete(s.atoms, atomID)
+	s.unindexAtom(atom)
+
+	for _, link := range s.linksForAtom(atomID) {
+		s.removeLink(link)
+	}
+
+	if atom.TensorID != "" {
+		s.releaseTensor(atom.TensorID)
+	}
+
+	for _, boundary := range s.boundariesByAtom[atomID] {
+		atomIDs := make([]string, 0, len(boundary.AtomIDs))
+		for _, id := range boundary.AtomIDs {
+			if id != atomID {
+				atomIDs = append(atomIDs, id)
+			}
+		}
+		boundary.AtomIDs = atomIDs
+	}
+	delete(s.boundariesByAtom, atomID)
+
 	return nil
 }
 
-// AddLink adds a new link between atoms in the space.
+// AddLink adds a new link between atoms in the space. Link IDs must be
+// unique.
 func (s *Space) AddLink(ctx context.Context, link *Link) error {
 	const op = "atenspace.(Space).AddLink"
 
 	if link == nil {
 		return errors.New(ctx, errors.InvalidParameter, op, "link is nil")
 	}
+	if link.ID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "link ID is empty")
+	}
 	if link.Source == "" || link.Target == "" {
 		return errors.New(ctx, errors.InvalidParameter, op, "link source or target is empty")
 	}
@@ -225,154 +547,1652 @@ func (s *Space) AddLink(ctx context.Context, link *Link) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if _, ok := s.linksByID[link.ID]; ok {
+		return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("link %s already exists", link.ID))
+	}
 	// Verify source and target atoms exist
 	if _, ok := s.atoms[link.Source]; !ok {
-		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("source atom %s not found", link.Source))
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("source atom %s not found", link.Source))
 	}
 	if _, ok := s.atoms[link.Target]; !ok {
-		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("target atom %s not found", link.Target))
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("target atom %s not found", link.Target))
 	}
 
 	link.CreatedAt = time.Now()
-	s.links = append(s.links, link)
+	s.insertLink(link)
 	return nil
 }
 
-// AttachTensor attaches an ATen tensor to an atom.
-func (s *Space) AttachTensor(ctx context.Context, atomID string, tensor *Tensor) error {
-	const op = "atenspace.(Space).AttachTensor"
+// AddLinks adds links in a single locked pass. A link that is nil, lacks an
+// ID, source or target, references an atom not in the space or repeats the ID
+// of an existing link or an earlier link in the batch is skipped and the
+// rest are added. The returned error joins one error per skipped link,
+// identifying it by position and ID. With WithAllOrNothing no link is added
+// if any is invalid.
+func (s *Space) AddLinks(ctx context.Context, links []*Link, opt ...Option) error {
+	const op = "atenspace.(Space).AddLinks"
+	opts := getOpts(opt...)
 
-	if tensor == nil {
-		return errors.New(ctx, errors.InvalidParameter, op, "tensor is nil")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]struct{}, len(links))
+	var errs []error
+	valid := make([]*Link, 0, len(links))
+	for i, link := range links {
+		var err error
+		switch {
+		case link == nil:
+			err = errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("link %d is nil", i))
+		case link.ID == "":
+			err = errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("link %d has an empty ID", i))
+		case link.Source == "" || link.Target == "":
+			err = errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("link %d (%s): source or target is empty", i, link.ID))
+		default:
+			_, exists := s.linksByID[link.ID]
+			_, repeated := seen[link.ID]
+			if exists || repeated {
+				err = errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("link %d: duplicate link ID %s", i, link.ID))
+				break
+			}
+			for _, atomID := range []string{link.Source, link.Target} {
+				if _, ok := s.atoms[atomID]; !ok {
+					err = errors.New(ctx, errors.NotFound, op, fmt.Sprintf("link %d (%s): atom %s not found", i, link.ID, atomID))
+					break
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		seen[link.ID] = struct{}{}
+		valid = append(valid, link)
+	}
+	if len(errs) > 0 && opts.withAllOrNothing {
+		return stderrors.Join(errs...)
+	}
+
+	now := time.Now()
+	for _, link := range valid {
+		link.CreatedAt = now
+		s.insertLink(link)
+	}
+	return stderrors.Join(errs...)
+}
+
+// insertLink appends link to the link list and every index. The caller must
+// hold s.mu and have checked that its ID is unique and its Source and Target
+// exist.
+func (s *Space) insertLink(link *Link) {
+	s.links = append(s.links, link)
+	s.linksByID[link.ID] = link
+	s.outgoing[link.Source] = append(s.outgoing[link.Source], link)
+	s.incoming[link.Target] = append(s.incoming[link.Target], link)
+	s.linksByType[link.Type] = append(s.linksByType[link.Type], link)
+}
+
+// RemoveLink removes the link with the given ID.
+func (s *Space) RemoveLink(ctx context.Context, linkID string) error {
+	const op = "atenspace.(Space).RemoveLink"
+
+	if linkID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "link ID is empty")
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	atom, ok := s.atoms[atomID]
+	link, ok := s.linksByID[linkID]
 	if !ok {
-		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("atom %s not found", atomID))
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("link %s not found", linkID))
 	}
-
-	atom.TensorID = tensor.ID
-	s.tensorStore[tensor.ID] = tensor
+	s.removeLink(link)
 	return nil
 }
 
-// DefineBoundary defines a new domain boundary in the space.
-// This is where "Space" is defined by "Boundary" domain model.
-func (s *Space) DefineBoundary(ctx context.Context, boundary *DomainBoundary) error {
-	const op = "atenspace.(Space).DefineBoundary"
+// GetLink retrieves a link by ID.
+func (s *Space) GetLink(ctx context.Context, linkID string) (*Link, error) {
+	const op = "atenspace.(Space).GetLink"
 
-	if boundary == nil {
-		return errors.New(ctx, errors.InvalidParameter, op, "boundary is nil")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	link, ok := s.linksByID[linkID]
+	if !ok {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("link %s not found", linkID))
 	}
-	if boundary.ID == "" {
-		return errors.New(ctx, errors.InvalidParameter, op, "boundary ID is empty")
+	return link, nil
+}
+
+// RenameLink changes the ID of the link oldID to newID.
+func (s *Space) RenameLink(ctx context.Context, oldID, newID string) error {
+	const op = "atenspace.(Space).RenameLink"
+
+	if newID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "new link ID is empty")
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if boundary.Properties == nil {
-		boundary.Properties = make(map[string]interface{})
+	link, ok := s.linksByID[oldID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("link %s not found", oldID))
 	}
-
-	s.boundaries = append(s.boundaries, boundary)
+	if _, ok := s.linksByID[newID]; ok {
+		return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("link %s already exists", newID))
+	}
+	delete(s.linksByID, oldID)
+	link.ID = newID
+	s.linksByID[newID] = link
 	return nil
 }
 
-// GetAtom retrieves an atom by ID.
-func (s *Space) GetAtom(ctx context.Context, atomID string) (*Atom, error) {
-	const op = "atenspace.(Space).GetAtom"
+// UpdateLinkStrength sets the Strength of the link with ID linkID. The
+// strength must be between 0.0 and 1.0.
+func (s *Space) UpdateLinkStrength(ctx context.Context, linkID string, strength float64) error {
+	const op = "atenspace.(Space).UpdateLinkStrength"
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	if linkID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "link ID is empty")
+	}
+	if !(strength >= 0 && strength <= 1) {
+		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("strength %v is not between 0 and 1", strength))
+	}
 
-	atom, ok := s.atoms[atomID]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.linksByID[linkID]
 	if !ok {
-		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("atom %s not found", atomID))
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("link %s not found", linkID))
 	}
-
-	return atom, nil
+	link.Strength = strength
+	return nil
 }
 
-// GetLinksForAtom retrieves all links connected to an atom.
-func (s *Space) GetLinksForAtom(ctx context.Context, atomID string) []*Link {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// DecayLinks multiplies the Strength of every link by factor, which must be
+// between 0.0 and 1.0. With WithStrengthFloor, links whose decayed strength
+// falls below the floor are removed. It returns the number of links
+// removed.
+func (s *Space) DecayLinks(ctx context.Context, factor float64, opt ...Option) (int, error) {
+	const op = "atenspace.(Space).DecayLinks"
+
+	if !(factor >= 0 && factor <= 1) {
+		return 0, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("factor %v is not between 0 and 1", factor))
+	}
+	opts := getOpts(opt...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	links := make([]*Link, 0)
+	var pruned []*Link
 	for _, link := range s.links {
-		if link.Source == atomID || link.Target == atomID {
-			links = append(links, link)
+		link.Strength *= factor
+		if opts.withStrengthFloorSet && link.Strength < opts.withStrengthFloor {
+			pruned = append(pruned, link)
 		}
 	}
+	for _, link := range pruned {
+		s.removeLink(link)
+	}
+	return len(pruned), nil
+}
 
-	return links
+// removeLink removes link from the link list and every index. The caller
+// must hold s.mu.
+func (s *Space) removeLink(link *Link) {
+	without := func(links []*Link) []*Link {
+		out := make([]*Link, 0, len(links))
+		for _, l := range links {
+			if l != link {
+				out = append(out, l)
+			}
+		}
+		return out
+	}
+	s.links = without(s.links)
+	delete(s.linksByID, link.ID)
+	if s.outgoing[link.Source] = without(s.outgoing[link.Source]); len(s.outgoing[link.Source]) == 0 {
+		delete(s.outgoing, link.Source)
+	}
+	if s.incoming[link.Target] = without(s.incoming[link.Target]); len(s.incoming[link.Target]) == 0 {
+		delete(s.incoming, link.Target)
+	}
+	if s.linksByType[link.Type] = without(s.linksByType[link.Type]); len(s.linksByType[link.Type]) == 0 {
+		delete(s.linksByType, link.Type)
+	}
 }
 
-// GetTensor retrieves the tensor for an atom.
-func (s *Space) GetTensor(ctx context.Context, atomID string) (*Tensor, error) {
-	const op = "atenspace.(Space).GetTensor"
+// AttachTensor attaches an ATen tensor to an atom. A tensor carrying only
+// the ID of an already stored tensor attaches the stored tensor, so several
+// atoms can share one tensor without duplicating its Data. A tensor carrying
+// Data or Shape replaces the stored tensor with the same ID, which fails with
+// NotUnique while another atom references it. The atom's previous tensor
+// stays stored until GCTensors reclaims it.
+func (s *Space) AttachTensor(ctx context.Context, atomID string, tensor *Tensor) error {
+	const op = "atenspace.(Space).AttachTensor"
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	if tensor == nil {
+		return errors.New(ctx, errors.InvalidParameter, op, "tensor is nil")
+	}
+	if tensor.DType != "" && !validDType(tensor.DType) {
+		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("tensor %s has unknown dtype %q", tensor.ID, tensor.DType))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	atom, ok := s.atoms[atomID]
 	if !ok {
-		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("atom %s not found", atomID))
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("atom %s not found", atomID))
 	}
 
-	if atom.TensorID == "" {
-		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("atom %s has no tensor", atomID))
+	if stored, ok := s.tensorStore[tensor.ID]; ok && stored != tensor {
+		if tensor.Data == nil && tensor.Shape == nil {
+			tensor = stored
+		} else if s.sharedByOthers(atom, tensor.ID) {
+			return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("tensor %s is shared by other atoms", tensor.ID))
+		}
 	}
+	previous := atom.TensorID
+	if previous != tensor.ID {
+		s.dropTensorRef(previous)
+		atom.TensorID = tensor.ID
+		s.retainTensor(tensor.ID)
+	}
+	s.tensorStore[tensor.ID] = tensor
+	return nil
+}
 
-	tensor, ok := s.tensorStore[atom.TensorID]
+// DetachTensor clears an atom's tensor reference. The tensor is deleted from
+// the space unless another atom still references it.
+func (s *Space) DetachTensor(ctx context.Context, atomID string) error {
+	const op = "atenspace.(Space).DetachTensor"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	atom, ok := s.atoms[atomID]
 	if !ok {
-		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("tensor %s not found", atom.TensorID))
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("atom %s not found", atomID))
+	}
+	if atom.TensorID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("atom %s has no tensor", atomID))
 	}
 
-	return tensor, nil
+	tensorID := atom.TensorID
+	atom.TensorID = ""
+	s.dropTensorRef(tensorID)
+	s.releaseTensor(tensorID)
+	return nil
 }
 
-// GetBoundaries retrieves all domain boundaries in the space.
-func (s *Space) GetBoundaries(ctx context.Context) []*DomainBoundary {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// GCTensors deletes every stored tensor that no atom references and returns
+// the number of tensors reclaimed.
+func (s *Space) GCTensors(ctx context.Context) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	boundaries := make([]*DomainBoundary, len(s.boundaries))
-	copy(boundaries, s.boundaries)
-	return boundaries
+	reclaimed := 0
+	for id := range s.tensorStore {
+		if s.tensorRefs[id] == 0 {
+			delete(s.tensorStore, id)
+			reclaimed++
+		}
+	}
+	return reclaimed
 }
 
-// QueryByBoundary queries atoms within a specific domain boundary.
-func (s *Space) QueryByBoundary(ctx context.Context, boundaryID string) ([]*Atom, error) {
-	const op = "atenspace.(Space).QueryByBoundary"
-
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// releaseTensor deletes the tensor unless an atom still references it. The
+// caller must hold s.mu.
+func (s *Space) releaseTensor(tensorID string) {
+	if s.tensorRefs[tensorID] > 0 {
+		return
+	}
+	delete(s.tensorStore, tensorID)
+}
 
-	var boundary *DomainBoundary
-	for _, b := range s.boundaries {
-		if b.ID == boundaryID {
-			boundary = b
-			break
-		}
+// sharedByOthers reports whether an atom other than atom references
+// tensorID. The caller must hold s.mu.
+func (s *Space) sharedByOthers(atom *Atom, tensorID string) bool {
+	others := s.tensorRefs[tensorID]
+	if atom.TensorID == tensorID {
+		others--
 	}
+	return others > 0
+}
 
-	if boundary == nil {
-		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("boundary %s not found", boundaryID))
+// retainTensor counts a new reference to tensorID. The caller must hold s.mu.
+func (s *Space) retainTensor(tensorID string) {
+	if tensorID != "" {
+		s.tensorRefs[tensorID]++
 	}
+}
 
-	atoms := make([]*Atom, 0, len(boundary.AtomIDs))
-	for _, atomID := range boundary.AtomIDs {
-		if atom, ok := s.atoms[atomID]; ok {
-			atoms = append(atoms, atom)
-		}
+// dropTensorRef removes a reference to tensorID counted by retainTensor. The
+// caller must hold s.mu.
+func (s *Space) dropTensorRef(tensorID string) {
+	if tensorID == "" {
+		return
 	}
+	if s.tensorRefs[tensorID]--; s.tensorRefs[tensorID] <= 0 {
+		delete(s.tensorRefs, tensorID)
+	}
+}
 
-	return atoms, nil
+// DefineBoundary defines a new domain boundary in the space.
+// This is where "Space" is defined by "Boundary" domain model. Change the
+// boundary's AtomIDs later through UpdateBoundary so that
+// FindBoundariesForAtom sees the change.
+func (s *Space) DefineBoundary(ctx context.Context, boundary *DomainBoundary) error {
+	const op = "atenspace.(Space).DefineBoundary"
+
+	if boundary == nil {
+		return errors.New(ctx, errors.InvalidParameter, op, "boundary is nil")
+	}
+	if boundary.ID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "boundary ID is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if boundary.Properties == nil {
+		boundary.Properties = make(map[string]interface{})
+	}
+
+	s.insertBoundary(boundary)
+	return nil
+}
+
+// insertBoundary appends boundary to the space and indexes it by its
+// atoms. The caller must hold s.mu.
+func (s *Space) insertBoundary(boundary *DomainBoundary) {
+	s.boundaries = append(s.boundaries, boundary)
+	s.indexBoundary(boundary)
+}
+
+// indexBoundary adds boundary to the index entry of each of its atoms. The
+// caller must hold s.mu.
+func (s *Space) indexBoundary(boundary *DomainBoundary) {
+	if s.boundariesByAtom == nil {
+		s.boundariesByAtom = make(map[string][]*DomainBoundary)
+	}
+	for _, atomID := range boundary.AtomIDs {
+		if !slices.Contains(s.boundariesByAtom[atomID], boundary) {
+			s.boundariesByAtom[atomID] = append(s.boundariesByAtom[atomID], boundary)
+		}
+	}
+}
+
+// unindexBoundary removes boundary from the index entry of each of its
+// atoms. The caller must hold s.mu.
+func (s *Space) unindexBoundary(boundary *DomainBoundary) {
+	for _, atomID := range boundary.AtomIDs {
+		remaining := slices.DeleteFunc(s.boundariesByAtom[atomID], func(b *DomainBoundary) bool { return b == boundary })
+		if len(remaining) == 0 {
+			delete(s.boundariesByAtom, atomID)
+		} else {
+			s.boundariesByAtom[atomID] = remaining
+		}
+	}
+}
+
+// UpdateBoundary replaces the Name, Type, AtomIDs and Properties of the
+// boundary with the same ID as boundary.
+func (s *Space) UpdateBoundary(ctx context.Context, boundary *DomainBoundary) error {
+	const op = "atenspace.(Space).UpdateBoundary"
+
+	if boundary == nil {
+		return errors.New(ctx, errors.InvalidParameter, op, "boundary is nil")
+	}
+	if boundary.ID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "boundary ID is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.boundaryIndex(boundary.ID)
+	if i < 0 {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("boundary %s not found", boundary.ID))
+	}
+
+	existing := s.boundaries[i]
+	s.unindexBoundary(existing)
+	existing.Name = boundary.Name
+	existing.Type = boundary.Type
+	existing.AtomIDs = slices.Clone(boundary.AtomIDs)
+	existing.Properties = boundary.Properties
+	if existing.Properties == nil {
+		existing.Properties = make(map[string]interface{})
+	}
+	s.indexBoundary(existing)
+	return nil
+}
+
+// RemoveBoundary removes the boundary with the given ID. The atoms within it
+// are left in the space.
+func (s *Space) RemoveBoundary(ctx context.Context, boundaryID string) error {
+	const op = "atenspace.(Space).RemoveBoundary"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.boundaryIndex(boundaryID)
+	if i < 0 {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("boundary %s not found", boundaryID))
+	}
+	s.unindexBoundary(s.boundaries[i])
+	s.boundaries = slices.Delete(s.boundaries, i, i+1)
+	return nil
+}
+
+// FindBoundariesForAtom returns copies of the boundaries whose AtomIDs
+// include atomID, sorted by ID. It uses an index kept up to date by
+// DefineBoundary, UpdateBoundary, RemoveBoundary, RemoveAtom and RenameAtom
+// rather than scanning every boundary.
+func (s *Space) FindBoundariesForAtom(ctx context.Context, atomID string) []*DomainBoundary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	boundaries := make([]*DomainBoundary, 0, len(s.boundariesByAtom[atomID]))
+	for _, boundary := range s.boundariesByAtom[atomID] {
+		boundaries = append(boundaries, copyBoundary(boundary))
+	}
+	slices.SortFunc(boundaries, func(a, b *DomainBoundary) int { return strings.Compare(a.ID, b.ID) })
+	return boundaries
+}
+
+// boundaryIndex returns the position of the boundary with the given ID in
+// s.boundaries, or -1. The caller must hold s.mu.
+func (s *Space) boundaryIndex(boundaryID string) int {
+	return slices.IndexFunc(s.boundaries, func(b *DomainBoundary) bool { return b.ID == boundaryID })
+}
+
+// GetAtom retrieves an atom by ID. An atom denied by the Space's authorizer
+// is reported as not found, or as forbidden with WithAuthorizationErrors.
+func (s *Space) GetAtom(ctx context.Context, atomID string) (*Atom, error) {
+	const op = "atenspace.(Space).GetAtom"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.lookupAtom(ctx, op, atomID)
+}
+
+// GetAtomCopy retrieves a copy of an atom by ID. The copy has its own
+// Attributes map, so it can be read and modified without holding any lock.
+// An atom denied by the Space's authorizer is reported as not found, or as
+// forbidden with WithAuthorizationErrors.
+func (s *Space) GetAtomCopy(ctx context.Context, atomID string) (*Atom, error) {
+	const op = "atenspace.(Space).GetAtomCopy"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	atom, err := s.lookupAtom(ctx, op, atomID)
+	if err != nil {
+		return nil, err
+	}
+	return copyAtom(atom), nil
+}
+
+// ListAtoms returns copies of all atoms readable through the Space's
+// authorizer, sorted by ID.
+func (s *Space) ListAtoms(ctx context.Context) ([]*Atom, error) {
+	const op = "atenspace.(Space).ListAtoms"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	atoms := make([]*Atom, 0, len(s.atoms))
+	for _, atom := range s.atoms {
+		readable, err := s.authorize(ctx, op, atom.ID)
+		if err != nil {
+			return nil, err
+		}
+		if readable {
+			atoms = append(atoms, copyAtom(atom))
+		}
+	}
+	slices.SortFunc(atoms, func(a, b *Atom) int { return strings.Compare(a.ID, b.ID) })
+	return atoms, nil
+}
+
+// QueryAtoms returns copies of the atoms for which filter returns true,
+// sorted by ID. filter is called with a copy of each atom while the space is
+// read-locked, so it must not call back into the space. Atoms denied by the
+// Space's authorizer are left out and never passed to filter.
+func (s *Space) QueryAtoms(ctx context.Context, filter func(*Atom) bool) []*Atom {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var atoms []*Atom
+	for _, atom := range s.atoms {
+		if !s.readable(ctx, atom.ID) {
+			continue
+		}
+		if c := copyAtom(atom); filter(c) {
+			atoms = append(atoms, c)
+		}
+	}
+	slices.SortFunc(atoms, func(a, b *Atom) int { return strings.Compare(a.ID, b.ID) })
+	return atoms
+}
+
+// QueryAtomsByAttribute returns copies of the atoms whose Attributes[key]
+// is deeply equal to value, sorted by ID. Atoms denied by the Space's
+// authorizer are left out.
+func (s *Space) QueryAtomsByAttribute(ctx context.Context, key string, value interface{}) []*Atom {
+	return s.QueryAtoms(ctx, func(atom *Atom) bool {
+		v, ok := atom.Attributes[key]
+		return ok && reflect.DeepEqual(v, value)
+	})
+}
+
+// GetLinksForAtom retrieves all links connected to an atom. Links with an
+// end denied by the Space's authorizer are left out.
+func (s *Space) GetLinksForAtom(ctx context.Context, atomID string) []*Link {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.readableLinks(ctx, s.linksForAtom(atomID))
+}
+
+// GetOutgoingLinks retrieves the links whose Source is the atom, followed
+// by the bidirectional links whose Target is the atom. Links with an end
+// denied by the Space's authorizer are left out.
+func (s *Space) GetOutgoingLinks(ctx context.Context, atomID string) []*Link {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.readableLinks(ctx, s.outgoingLinks(atomID))
+}
+
+// GetIncomingLinks retrieves the links whose Target is the atom, followed
+// by the bidirectional links whose Source is the atom. Links with an end
+// denied by the Space's authorizer are left out.
+func (s *Space) GetIncomingLinks(ctx context.Context, atomID string) []*Link {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.readableLinks(ctx, s.incomingLinks(atomID))
+}
+
+// outgoingLinks returns the links that can be traversed away from an atom.
+// The caller must hold s.mu.
+func (s *Space) outgoingLinks(atomID string) []*Link {
+	return withReversed(s.outgoing[atomID], s.incoming[atomID], atomID)
+}
+
+// incomingLinks returns the links that can be traversed into an atom. The
+// caller must hold s.mu.
+func (s *Space) incomingLinks(atomID string) []*Link {
+	return withReversed(s.incoming[atomID], s.outgoing[atomID], atomID)
+}
+
+// withReversed returns links followed by the bidirectional links in
+// opposite, skipping self-loops already listed in links.
+func withReversed(links, opposite []*Link, atomID string) []*Link {
+	out := append(make([]*Link, 0, len(links)+len(opposite)), links...)
+	for _, link := range opposite {
+		if link.Bidirectional && link.Source != link.Target {
+			out = append(out, link)
+		}
+	}
+	return out
+}
+
+// otherEnd returns the atom at the opposite end of the link from atomID.
+func (l *Link) otherEnd(atomID string) string {
+	if l.Source == atomID {
+		return l.Target
+	}
+	return l.Source
+}
+
+// pathTo returns the links recorded in via leading to targetID, starting
+// from the atom that has no entry.
+func pathTo(via map[string]*Link, targetID string) []*Link {
+	path := make([]*Link, 0)
+	for atomID := targetID; via[atomID] != nil; atomID = via[atomID].otherEnd(atomID) {
+		path = append(path, via[atomID])
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// linksForAtom returns the outgoing links of an atom followed by its
+// incoming links, listing a self-loop once. The caller must hold s.mu.
+func (s *Space) linksForAtom(atomID string) []*Link {
+	links := make([]*Link, 0, len(s.outgoing[atomID])+len(s.incoming[atomID]))
+	links = append(links, s.outgoing[atomID]...)
+	for _, link := range s.incoming[atomID] {
+		if link.Source != atomID {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// GetAtomsByType retrieves all atoms of the given type, ordered by ID. Atoms
+// denied by the Space's authorizer are left out.
+func (s *Space) GetAtomsByType(ctx context.Context, t AtomType) []*Atom {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	atoms := make([]*Atom, 0, len(s.atomsByType[t]))
+	for _, atom := range s.atomsByType[t] {
+		if s.readable(ctx, atom.ID) {
+			atoms = append(atoms, atom)
+		}
+	}
+	slices.SortFunc(atoms, func(a, b *Atom) int { return strings.Compare(a.ID, b.ID) })
+	return atoms
+}
+
+// GetLinksByType retrieves all links of the given type in the order they
+// were added.
+func (s *Space) GetLinksByType(ctx context.Context, t LinkType) []*Link {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append(make([]*Link, 0, len(s.linksByType[t])), s.linksByType[t]...)
+}
+
+// SpaceStats summarizes the contents of a Space.
+type SpaceStats struct {
+	// Atoms is the number of atoms
+	Atoms int
+
+	// AtomsByType counts the atoms of each type present in the space
+	AtomsByType map[AtomType]int
+
+	// Links is the number of links
+	Links int
+
+	// LinksByType counts the links of each type present in the space
+	LinksByType map[LinkType]int
+
+	// Tensors is the number of stored tensors
+	Tensors int
+
+	// Boundaries is the number of domain boundaries
+	Boundaries int
+
+	// TensorBytes is the memory held by tensor data, 8 bytes per element
+	TensorBytes int64
+}
+
+// Stats returns the number of atoms, links, tensors and boundaries in the
+// space and the memory held by tensor data. Atoms and links are counted
+// from the type indexes; only the tensors are iterated.
+func (s *Space) Stats(ctx context.Context) SpaceStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := SpaceStats{
+		Atoms:       len(s.atoms),
+		AtomsByType: make(map[AtomType]int, len(s.atomsByType)),
+		Links:       len(s.links),
+		LinksByType: make(map[LinkType]int, len(s.linksByType)),
+		Tensors:     len(s.tensorStore),
+		Boundaries:  len(s.boundaries),
+	}
+	for t, atoms := range s.atomsByType {
+		if len(atoms) > 0 {
+			stats.AtomsByType[t] = len(atoms)
+		}
+	}
+	for t, links := range s.linksByType {
+		if len(links) > 0 {
+			stats.LinksByType[t] = len(links)
+		}
+	}
+	for _, tensor := range s.tensorStore {
+		stats.TensorBytes += int64(len(tensor.Data)) * 8
+	}
+	return stats
+}
+
+// GetTensor retrieves the tensor for an atom.
+func (s *Space) GetTensor(ctx context.Context, atomID string) (*Tensor, error) {
+	const op = "atenspace.(Space).GetTensor"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tensorOf(ctx, op, atomID)
+}
+
+// SetTensorValues writes values into the tensor attached to an atom, keyed
+// by flat index into its Data. No value is written unless every index is in
+// range.
+func (s *Space) SetTensorValues(ctx context.Context, atomID string, values map[int]float64) error {
+	const op = "atenspace.(Space).SetTensorValues"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tensor, err := s.tensorOf(ctx, op, atomID)
+	if err != nil {
+		return err
+	}
+	for i := range values {
+		if i < 0 || i >= len(tensor.Data) {
+			return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("index %d out of range for tensor %s of length %d", i, tensor.ID, len(tensor.Data)))
+		}
+	}
+	for i, v := range values {
+		tensor.Data[i] = v
+	}
+	return nil
+}
+
+// AddTensors computes the element-wise sum of the tensors attached to
+// atomID1 and atomID2, which must have the same Shape, and attaches the
+// result to the existing atom resultAtomID as a new tensor. It fails with
+// NotUnique if the result's tensor ID is referenced by another atom.
+func (s *Space) AddTensors(ctx context.Context, atomID1, atomID2, resultAtomID string) error {
+	const op = "atenspace.(Space).AddTensors"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t1, err := s.tensorOf(ctx, op, atomID1)
+	if err != nil {
+		return err
+	}
+	t2, err := s.tensorOf(ctx, op, atomID2)
+	if err != nil {
+		return err
+	}
+	result, ok := s.atoms[resultAtomID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("atom %s not found", resultAtomID))
+	}
+	if !slices.Equal(t1.Shape, t2.Shape) {
+		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("shape mismatch: %v and %v", t1.Shape, t2.Shape))
+	}
+	if len(t1.Data) != len(t2.Data) {
+		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("data length mismatch: %d and %d", len(t1.Data), len(t2.Data)))
+	}
+	if d1, d2 := deviceOf(t1), deviceOf(t2); d1 != d2 {
+		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("device mismatch: tensor %s is on %s and tensor %s is on %s", t1.ID, d1, t2.ID, d2))
+	}
+	if sumID := resultAtomID + "_tensor"; s.sharedByOthers(result, sumID) {
+		return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("tensor %s is shared by other atoms", sumID))
+	}
+
+	sum := &Tensor{
+		ID:     resultAtomID + "_tensor",
+		Shape:  slices.Clone(t1.Shape),
+		Data:   make([]float64, len(t1.Data)),
+		DType:  t1.DType,
+		Device: t1.Device,
+	}
+	for i := range sum.Data {
+		sum.Data[i] = t1.Data[i] + t2.Data[i]
+	}
+
+	previous := result.TensorID
+	if previous != sum.ID {
+		s.dropTensorRef(previous)
+		result.TensorID = sum.ID
+		s.retainTensor(sum.ID)
+	}
+	s.tensorStore[sum.ID] = sum
+	if previous != "" && previous != sum.ID {
+		s.releaseTensor(previous)
+	}
+	return nil
+}
+
+// MoveTensor places the tensor with ID tensorID on device, which must be
+// one of CPUDevice, CUDADevice or MPSDevice, and records the move. Moving a
+// tensor to the device it is already on is not recorded.
+func (s *Space) MoveTensor(ctx context.Context, tensorID, device string) error {
+	const op = "atenspace.(Space).MoveTensor"
+
+	switch device {
+	case CPUDevice, CUDADevice, MPSDevice:
+	default:
+		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("unknown device %q", device))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tensor, ok := s.tensorStore[tensorID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("tensor %s not found", tensorID))
+	}
+	from := deviceOf(tensor)
+	if from == device {
+		return nil
+	}
+	tensor.Device = device
+	s.tensorMoves = append(s.tensorMoves, TensorMove{TensorID: tensorID, From: from, To: device, MovedAt: time.Now()})
+	return nil
+}
+
+// GetTensorMoves returns the moves of the tensor with ID tensorID in the
+// order they happened.
+func (s *Space) GetTensorMoves(ctx context.Context, tensorID string) []TensorMove {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var moves []TensorMove
+	for _, move := range s.tensorMoves {
+		if move.TensorID == tensorID {
+			moves = append(moves, move)
+		}
+	}
+	return moves
+}
+
+// ConvertTensor changes the DType of the tensor with ID tensorID to dtype
+// and converts its Data to match. Converting to an integer type truncates
+// each value toward zero, so 2.7 becomes 2 and -2.7 becomes -2; converting to
+// Float32DType rounds each value to the nearest float32. The tensor is left
+// unchanged if a value is NaN or infinite and dtype is an integer type, or
+// if a value is out of dtype's range.
+func (s *Space) ConvertTensor(ctx context.Context, tensorID, dtype string) error {
+	const op = "atenspace.(Space).ConvertTensor"
+
+	if !validDType(dtype) {
+		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("unknown dtype %q", dtype))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tensor, ok := s.tensorStore[tensorID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("tensor %s not found", tensorID))
+	}
+
+	data := make([]float64, len(tensor.Data))
+	for i, v := range tensor.Data {
+		converted, ok := convertValue(v, dtype)
+		if !ok {
+			return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("tensor %s: value %v at %d cannot be converted to %s", tensorID, v, i, dtype))
+		}
+		data[i] = converted
+	}
+	tensor.Data = data
+	tensor.DType = dtype
+	return nil
+}
+
+// validDType reports whether dtype is one of the supported data types.
+func validDType(dtype string) bool {
+	switch dtype {
+	case Float64DType, Float32DType, Int64DType, Int32DType:
+		return true
+	default:
+		return false
+	}
+}
+
+// convertValue returns v converted to dtype, reporting false if dtype
+// cannot represent it.
+func convertValue(v float64, dtype string) (float64, bool) {
+	switch dtype {
+	case Float32DType:
+		f := float64(float32(v))
+		return f, !math.IsInf(f, 0) || math.IsInf(v, 0)
+	case Int64DType:
+		t := math.Trunc(v)
+		// float64(math.MaxInt64) rounds up to 2^63, which is out of range
+		return t, t >= math.MinInt64 && t < math.MaxInt64
+	case Int32DType:
+		t := math.Trunc(v)
+		return t, t >= math.MinInt32 && t <= math.MaxInt32
+	default:
+		return v, true
+	}
+}
+
+// deviceOf returns the device the tensor is on.
+func deviceOf(tensor *Tensor) string {
+	if tensor.Device == "" {
+		return CPUDevice
+	}
+	return tensor.Device
+}
+
+// GetTensorByID retrieves a stored tensor by its ID.
+func (s *Space) GetTensorByID(ctx context.Context, tensorID string) (*Tensor, error) {
+	const op = "atenspace.(Space).GetTensorByID"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tensor, ok := s.tensorStore[tensorID]
+	if !ok {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("tensor %s not found", tensorID))
+	}
+	return tensor, nil
+}
+
+// RenameTensor changes the ID of the stored tensor oldID to newID. Every
+// atom referencing the tensor and its MoveTensor history follow it.
+func (s *Space) RenameTensor(ctx context.Context, oldID, newID string) error {
+	const op = "atenspace.(Space).RenameTensor"
+
+	if newID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "new tensor ID is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tensor, ok := s.tensorStore[oldID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("tensor %s not found", oldID))
+	}
+	if _, ok := s.tensorStore[newID]; ok {
+		return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("tensor %s already exists", newID))
+	}
+
+	delete(s.tensorStore, oldID)
+	tensor.ID = newID
+	s.tensorStore[newID] = tensor
+	for _, atom := range s.atoms {
+		if atom.TensorID == oldID {
+			atom.TensorID = newID
+		}
+	}
+	if refs, ok := s.tensorRefs[oldID]; ok {
+		delete(s.tensorRefs, oldID)
+		s.tensorRefs[newID] = refs
+	}
+	for i := range s.tensorMoves {
+		if s.tensorMoves[i].TensorID == oldID {
+			s.tensorMoves[i].TensorID = newID
+		}
+	}
+	return nil
+}
+
+// tensorOf returns the tensor attached to an atom. The caller must hold s.mu.
+func (s *Space) tensorOf(ctx context.Context, op errors.Op, atomID string) (*Tensor, error) {
+	atom, ok := s.atoms[atomID]
+	if !ok {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("atom %s not found", atomID))
+	}
+
+	if atom.TensorID == "" {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("atom %s has no tensor", atomID))
+	}
+
+	tensor, ok := s.tensorStore[atom.TensorID]
+	if !ok {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("tensor %s not found", atom.TensorID))
+	}
+
+	return tensor, nil
+}
+
+// GetBoundaries returns copies of all domain boundaries in the space, each
+// with its own AtomIDs and Properties, so callers may read and modify them
+// without affecting the space or racing with its updates.
+func (s *Space) GetBoundaries(ctx context.Context) []*DomainBoundary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	boundaries := make([]*DomainBoundary, 0, len(s.boundaries))
+	for _, boundary := range s.boundaries {
+		boundaries = append(boundaries, copyBoundary(boundary))
+	}
+	return boundaries
+}
+
+// QueryByBoundary queries atoms within a specific domain boundary. Atoms
+// denied by the Space's authorizer are left out.
+func (s *Space) QueryByBoundary(ctx context.Context, boundaryID string) ([]*Atom, error) {
+	const op = "atenspace.(Space).QueryByBoundary"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var boundary *DomainBoundary
+	for _, b := range s.boundaries {
+		if b.ID == boundaryID {
+			boundary = b
+			break
+		}
+	}
+
+	if boundary == nil {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("boundary %s not found", boundaryID))
+	}
+
+	atoms := make([]*Atom, 0, len(boundary.AtomIDs))
+	for _, atomID := range boundary.AtomIDs {
+		atom, ok := s.atoms[atomID]
+		if !ok {
+			continue
+		}
+		readable, err := s.authorize(ctx, op, atomID)
+		if err != nil {
+			return nil, err
+		}
+		if readable {
+			atoms = append(atoms, atom)
+		}
+	}
+
+	return atoms, nil
+}
+
+// ExtractSubgraph returns a new Space holding copies of the boundary's
+// atoms, the links whose Source and Target are both within the boundary,
+// the tensors attached to those atoms and the boundary itself. Links that
+// cross the boundary are left out, as are atoms denied by the Space's
+// authorizer and their links.
+func (s *Space) ExtractSubgraph(ctx context.Context, boundaryID string) (*Space, error) {
+	const op = "atenspace.(Space).ExtractSubgraph"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.boundaryIndex(boundaryID)
+	if i < 0 {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("boundary %s not found", boundaryID))
+	}
+	boundary := s.boundaries[i]
+
+	sub, err := NewSpace(ctx)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, op)
+	}
+
+	atomIDs := make([]string, 0, len(boundary.AtomIDs))
+	for _, atomID := range boundary.AtomIDs {
+		atom, ok := s.atoms[atomID]
+		if !ok {
+			continue
+		}
+		if _, dup := sub.atoms[atomID]; dup {
+			continue
+		}
+		readable, err := s.authorize(ctx, op, atomID)
+		if err != nil {
+			return nil, err
+		}
+		if !readable {
+			continue
+		}
+		atomIDs = append(atomIDs, atomID)
+		sub.insertAtom(copyAtom(atom))
+		if tensor, ok := s.tensorStore[atom.TensorID]; ok {
+			sub.tensorStore[tensor.ID] = copyTensor(tensor)
+		}
+	}
+	for _, link := range s.links {
+		_, inSource := sub.atoms[link.Source]
+		_, inTarget := sub.atoms[link.Target]
+		if inSource && inTarget {
+			l := *link
+			sub.insertLink(&l)
+		}
+	}
+	sub.insertBoundary(&DomainBoundary{
+		ID:         boundary.ID,
+		Name:       boundary.Name,
+		Type:       boundary.Type,
+		AtomIDs:    atomIDs,
+		Properties: maps.Clone(boundary.Properties),
+	})
+
+	return sub, nil
+}
+
+// copyAtom returns a copy of atom with its own Attributes map.
+func copyAtom(atom *Atom) *Atom {
+	c := *atom
+	c.Attributes = maps.Clone(atom.Attributes)
+	return &c
+}
+
+// copyBoundary returns a copy of boundary with its own AtomIDs and
+// Properties.
+func copyBoundary(boundary *DomainBoundary) *DomainBoundary {
+	c := *boundary
+	c.AtomIDs = slices.Clone(boundary.AtomIDs)
+	c.Properties = maps.Clone(boundary.Properties)
+	return &c
+}
+
+// copyTensor returns a copy of tensor with its own Shape and Data.
+func copyTensor(tensor *Tensor) *Tensor {
+	c := *tensor
+	c.Shape = slices.Clone(tensor.Shape)
+	c.Data = slices.Clone(tensor.Data)
+	return &c
+}
+
+// Reshape changes the tensor's Shape to newShape without touching Data,
+// which keeps its row-major order. It errors unless every dimension is
+// positive and their product equals len(Data). Tensors attached to a Space
+// are shared, so reshaping one is seen by every atom it is attached to.
+func (t *Tensor) Reshape(newShape []int) error {
+	if len(newShape) == 0 {
+		return stderrors.New("shape is empty")
+	}
+	size := 1
+	for i, dim := range newShape {
+		if dim <= 0 {
+			return fmt.Errorf("dimension %d has non-positive size %d", i, dim)
+		}
+		size *= dim
+	}
+	if size != len(t.Data) {
+		return fmt.Errorf("shape %v holds %d elements but tensor %s has %d", newShape, size, t.ID, len(t.Data))
+	}
+	t.Shape = slices.Clone(newShape)
+	return nil
+}
+
+// Flatten reshapes the tensor to a single dimension of len(Data).
+func (t *Tensor) Flatten() {
+	t.Shape = []int{len(t.Data)}
+}
+
+// At returns the element at indices, one per dimension of Shape, reading
+// Data in row-major order.
+func (t *Tensor) At(indices ...int) (float64, error) {
+	if len(indices) != len(t.Shape) {
+		return 0, fmt.Errorf("got %d indices for tensor %s of rank %d", len(indices), t.ID, len(t.Shape))
+	}
+	offset := 0
+	for i, idx := range indices {
+		if idx < 0 || idx >= t.Shape[i] {
+			return 0, fmt.Errorf("index %d out of range for dimension %d of size %d", idx, i, t.Shape[i])
+		}
+		offset = offset*t.Shape[i] + idx
+	}
+	if offset >= len(t.Data) {
+		return 0, fmt.Errorf("tensor %s has %d elements, fewer than its shape %v", t.ID, len(t.Data), t.Shape)
+	}
+	return t.Data[offset], nil
+}
+
+// TransitiveMembers returns every atom reachable from atomID by following
+// MembershipLink and ScopeLink edges from Source to Target, answering
+// effective membership across nested scopes. Each atom is returned once in
+// breadth-first order and the starting atom is not included. Atoms denied
+// by the Space's authorizer are neither returned nor traversed, and a denied
+// starting atom is reported as GetAtom reports it.
+func (s *Space) TransitiveMembers(ctx context.Context, atomID string) ([]*Atom, error) {
+	const op = "atenspace.(Space).TransitiveMembers"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.lookupAtom(ctx, op, atomID); err != nil {
+		return nil, err
+	}
+
+	visited := map[string]bool{atomID: true}
+	queue := []string{atomID}
+	members := make([]*Atom, 0)
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(ctx, err, op)
+		}
+		current := queue[0]
+		queue = queue[1:]
+		for _, link := range s.outgoing[current] {
+			if link.Type != MembershipLink && link.Type != ScopeLink {
+				continue
+			}
+			next := link.Target
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			if atom, ok := s.atoms[next]; ok {
+				readable, err := s.authorize(ctx, op, next)
+				if err != nil {
+					return nil, err
+				}
+				if !readable {
+					continue
+				}
+				members = append(members, atom)
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return members, nil
+}
+
+// GetNeighbors returns the atoms reachable from atomID within hops links,
+// following links in either direction. When linkTypes is non-empty only
+// links of those types are followed. Each atom is returned once, nearest
+// first, and the starting atom is excluded. Atoms denied by the Space's
+// authorizer are neither returned nor traversed, and a denied starting atom
+// is reported as GetAtom reports it.
+func (s *Space) GetNeighbors(ctx context.Context, atomID string, hops int, linkTypes []LinkType) ([]*Atom, error) {
+	const op = "atenspace.(Space).GetNeighbors"
+
+	if hops < 0 {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "hops must not be negative")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.lookupAtom(ctx, op, atomID); err != nil {
+		return nil, err
+	}
+
+	visited := map[string]bool{atomID: true}
+	frontier := []string{atomID}
+	neighbors := make([]*Atom, 0)
+	for hop := 0; hop < hops && len(frontier) > 0; hop++ {
+		next := make([]string, 0)
+		for _, current := range frontier {
+			for _, link := range s.linksForAtom(current) {
+				if len(linkTypes) > 0 && !slices.Contains(linkTypes, link.Type) {
+					continue
+				}
+				other := link.Target
+				if other == current {
+					other = link.Source
+				}
+				if visited[other] {
+					continue
+				}
+				visited[other] = true
+				if atom, ok := s.atoms[other]; ok {
+					readable, err := s.authorize(ctx, op, other)
+					if err != nil {
+						return nil, err
+					}
+					if !readable {
+						continue
+					}
+					neighbors = append(neighbors, atom)
+				}
+				next = append(next, other)
+			}
+		}
+		frontier = next
+	}
+
+	return neighbors, nil
+}
+
+// Walk visits the atoms reachable from startID breadth first, following
+// links from Source to Target and bidirectional links either way. visit is
+// called once per atom, starting with startID at depth 0, and receives a
+// copy of the atom; returning false stops the walk from descending past
+// that atom. The space is not locked while visit runs, so it may call back
+// into the space, and changes made meanwhile are seen by the rest of the
+// walk. Atoms denied by the Space's authorizer are neither visited nor
+// traversed, and a denied starting atom is reported as GetAtom reports it.
+func (s *Space) Walk(ctx context.Context, startID string, visit func(atom *Atom, depth int) bool) error {
+	const op = "atenspace.(Space).Walk"
+
+	if visit == nil {
+		return errors.New(ctx, errors.InvalidParameter, op, "visit is nil")
+	}
+
+	s.mu.RLock()
+	_, err := s.lookupAtom(ctx, op, startID)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	type step struct {
+		atomID string
+		depth  int
+	}
+	visited := map[string]bool{startID: true}
+	queue := []step{{atomID: startID}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		s.mu.RLock()
+		atom, ok := s.atoms[current.atomID]
+		var next []string
+		if ok {
+			ok, err = s.authorize(ctx, op, current.atomID)
+		}
+		if ok {
+			atom = copyAtom(atom)
+			for _, link := range s.outgoingLinks(current.atomID) {
+				next = append(next, link.otherEnd(current.atomID))
+			}
+		}
+		s.mu.RUnlock()
+
+		if err != nil {
+			return err
+		}
+		// the atom was removed after it was queued or is not readable
+		if !ok {
+			continue
+		}
+		if !visit(atom, current.depth) {
+			continue
+		}
+		for _, id := range next {
+			if !visited[id] {
+				visited[id] = true
+				queue = append(queue, step{atomID: id, depth: current.depth + 1})
+			}
+		}
+	}
+	return nil
+}
+
+// FindPath returns the links of a shortest directed path from sourceID to
+// targetID, following links from Source to Target and bidirectional links
+// either way. Paths longer than maxDepth links are not considered. If no
+// such path exists a NotFound error is returned. A path from an atom to
+// itself is empty. Paths never pass through atoms denied by the Space's
+// authorizer, and a denied source or target is reported as GetAtom reports
+// it.
+func (s *Space) FindPath(ctx context.Context, sourceID, targetID string, maxDepth int) ([]*Link, error) {
+	const op = "atenspace.(Space).FindPath"
+
+	if maxDepth <= 0 {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "max depth must be positive")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range []string{sourceID, targetID} {
+		if _, err := s.lookupAtom(ctx, op, id); err != nil {
+			return nil, err
+		}
+	}
+	if sourceID == targetID {
+		return []*Link{}, nil
+	}
+
+	// via records the link used to first reach each atom
+	via := map[string]*Link{sourceID: nil}
+	frontier := []string{sourceID}
+	for depth := 0; depth < maxDepth && len(frontier) > 0; depth++ {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(ctx, err, op)
+		}
+		next := make([]string, 0)
+		for _, current := range frontier {
+			for _, link := range s.outgoingLinks(current) {
+				other := link.otherEnd(current)
+				if _, seen := via[other]; seen {
+					continue
+				}
+				readable, err := s.authorize(ctx, op, other)
+				if err != nil {
+					return nil, err
+				}
+				if !readable {
+					continue
+				}
+				via[other] = link
+				if other == targetID {
+					return pathTo(via, targetID), nil
+				}
+				next = append(next, other)
+			}
+		}
+		frontier = next
+	}
+
+	return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("no path from %s to %s within %d links", sourceID, targetID, maxDepth))
+}
+
+// FindStrongestPath returns the directed path from sourceID to targetID
+// whose product of link strengths is greatest, together with that product.
+// Bidirectional links may be followed either way. Only links with a
+// Strength in (0, 1] are followed. If no such path exists a NotFound error
+// is returned. A path from an atom to itself is empty with strength 1. Paths
+// never pass through atoms denied by the Space's authorizer, and a denied
+// source or target is reported as GetAtom reports it.
+func (s *Space) FindStrongestPath(ctx context.Context, sourceID, targetID string) ([]*Link, float64, error) {
+	const op = "atenspace.(Space).FindStrongestPath"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range []string{sourceID, targetID} {
+		if _, err := s.lookupAtom(ctx, op, id); err != nil {
+			return nil, 0, err
+		}
+	}
+	if sourceID == targetID {
+		return []*Link{}, 1, nil
+	}
+
+	// Maximizing the product of strengths is minimizing the sum of their
+	// negative logarithms, which are non-negative for strengths in (0, 1].
+	cost := map[string]float64{sourceID: 0}
+	via := map[string]*Link{sourceID: nil}
+	done := make(map[string]bool)
+	queue := &costQueue{{atomID: sourceID}}
+	for queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, errors.Wrap(ctx, err, op)
+		}
+		current := heap.Pop(queue).(costItem)
+		if done[current.atomID] {
+			continue
+		}
+		done[current.atomID] = true
+		if current.atomID == targetID {
+			return pathTo(via, targetID), math.Exp(-current.cost), nil
+		}
+		for _, link := range s.outgoingLinks(current.atomID) {
+			other := link.otherEnd(current.atomID)
+			if link.Strength <= 0 || link.Strength > 1 || done[other] {
+				continue
+			}
+			readable, err := s.authorize(ctx, op, other)
+			if err != nil {
+				return nil, 0, err
+			}
+			if !readable {
+				continue
+			}
+			c := current.cost - math.Log(link.Strength)
+			if best, ok := cost[other]; ok && best <= c {
+				continue
+			}
+			cost[other] = c
+			via[other] = link
+			heap.Push(queue, costItem{atomID: other, cost: c})
+		}
+	}
+
+	return nil, 0, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("no path from %s to %s", sourceID, targetID))
+}
+
+// costItem is an atom queued by FindStrongestPath with its path cost.
+type costItem struct {
+	atomID string
+	cost   float64
+}
+
+// costQueue is a min-heap of costItems.
+type costQueue []costItem
+
+func (q costQueue) Len() int           { return len(q) }
+func (q costQueue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q costQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *costQueue) Push(x any)        { *q = append(*q, x.(costItem)) }
+func (q *costQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// GetNeighborsAboveStrength returns the atoms linked to atomID in either
+// direction by a link whose Strength is greater than threshold. Each atom is
+// returned once. Atoms denied by the Space's authorizer are left out, and a
+// denied atomID has no neighbors.
+func (s *Space) GetNeighborsAboveStrength(ctx context.Context, atomID string, threshold float64) []*Atom {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := map[string]bool{atomID: true}
+	neighbors := make([]*Atom, 0)
+	if !s.readable(ctx, atomID) {
+		return neighbors
+	}
+	for _, link := range s.linksForAtom(atomID) {
+		if link.Strength <= threshold {
+			continue
+		}
+		other := link.Target
+		if other == atomID {
+			other = link.Source
+		}
+		if seen[other] {
+			continue
+		}
+		seen[other] = true
+		if atom, ok := s.atoms[other]; ok && s.readable(ctx, other) {
+			neighbors = append(neighbors, atom)
+		}
+	}
+	return neighbors
+}
+
+// ConnectedComponents groups atom IDs into components whose atoms are
+// reachable from each other when links are followed in either direction.
+// Each component is sorted by ID and components are ordered by their first
+// ID. An atom without links forms a component of its own.
+func (s *Space) ConnectedComponents(ctx context.Context) [][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.atoms))
+	for id := range s.atoms {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	visited := make(map[string]bool, len(ids))
+	components := make([][]string, 0)
+	for _, id := range ids {
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		component := []string{id}
+		for i := 0; i < len(component); i++ {
+			for _, link := range s.linksForAtom(component[i]) {
+				for _, other := range []string{link.Source, link.Target} {
+					if !visited[other] {
+						visited[other] = true
+						component = append(component, other)
+					}
+				}
+			}
+		}
+		slices.Sort(component)
+		components = append(components, component)
+	}
+	return components
+}
+
+// DegreeCentrality returns the number of links each atom is the Source or
+// Target of. A self-loop counts twice.
+func (s *Space) DegreeCentrality(ctx context.Context) map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	degrees := make(map[string]int, len(s.atoms))
+	for id := range s.atoms {
+		degrees[id] = len(s.outgoing[id]) + len(s.incoming[id])
+	}
+	return degrees
+}
+
+// Triple is a link together with its Source and Target atoms.
+type Triple struct {
+	Source *Atom
+	Link   *Link
+	Target *Atom
+}
+
+// MatchTriples returns every (source)-[link]->(target) triple whose atoms and
+// link have the given types. An empty type matches any type. Triples are
+// ordered by source ID, then target ID, then the order the links were added.
+// Triples with an atom denied by the Space's authorizer are left out.
+func (s *Space) MatchTriples(ctx context.Context, sourceType AtomType, linkType LinkType, targetType AtomType) ([]Triple, error) {
+	const op = "atenspace.(Space).MatchTriples"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Start from whichever type index is given to avoid scanning every link.
+	var candidates []*Link
+	switch {
+	case linkType != "":
+		candidates = s.linksByType[linkType]
+	case sourceType != "":
+		for _, atom := range s.atomsByType[sourceType] {
+			candidates = append(candidates, s.outgoing[atom.ID]...)
+		}
+	case targetType != "":
+		for _, atom := range s.atomsByType[targetType] {
+			candidates = append(candidates, s.incoming[atom.ID]...)
+		}
+	default:
+		candidates = s.links
+	}
+
+	triples := make([]Triple, 0)
+	for _, link := range candidates {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(ctx, err, op)
+		}
+		if linkType != "" && link.Type != linkType {
+			continue
+		}
+		source, target := s.atoms[link.Source], s.atoms[link.Target]
+		if source == nil || target == nil {
+			continue
+		}
+		if (sourceType != "" && source.Type != sourceType) || (targetType != "" && target.Type != targetType) {
+			continue
+		}
+		readable, err := s.authorize(ctx, op, source.ID)
+		if err == nil && readable {
+			readable, err = s.authorize(ctx, op, target.ID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !readable {
+			continue
+		}
+		triples = append(triples, Triple{Source: source, Link: link, Target: target})
+	}
+	slices.SortStableFunc(triples, func(a, b Triple) int {
+		if c := strings.Compare(a.Source.ID, b.Source.ID); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Target.ID, b.Target.ID)
+	})
+	return triples, nil
 }
 
 // IntegrateWithBoundary integrates ATenSpace with Boundary's domain model.
@@ -4,9 +4,17 @@
 package atenspace
 
 import (
+	"bytes"
 	"context"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/boundary/internal/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -622,3 +630,2182 @@ func TestSpace_ComplexScenario(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "tensor-1", retrievedTensor.ID)
 }
+
+func TestSpace_TransitiveMembers(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *Space {
+		s, err := NewSpace(ctx)
+		require.NoError(t, err)
+		for _, id := range []string{"global", "org-1", "project-1", "user-1", "user-2", "resource-1"} {
+			require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom}))
+		}
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "l1", Type: ScopeLink, Source: "global", Target: "org-1"}))
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "l2", Type: ScopeLink, Source: "org-1", Target: "project-1"}))
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "l3", Type: MembershipLink, Source: "org-1", Target: "user-1"}))
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "l4", Type: MembershipLink, Source: "project-1", Target: "user-2"}))
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "l5", Type: DependencyLink, Source: "project-1", Target: "resource-1"}))
+		// membership cycle back to the org must not loop forever
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "l6", Type: MembershipLink, Source: "user-2", Target: "org-1"}))
+		return s
+	}
+
+	t.Run("follows membership and scope links", func(t *testing.T) {
+		s := setup(t)
+		members, err := s.TransitiveMembers(ctx, "org-1")
+		require.NoError(t, err)
+
+		ids := make([]string, 0, len(members))
+		for _, a := range members {
+			ids = append(ids, a.ID)
+		}
+		assert.ElementsMatch(t, []string{"project-1", "user-1", "user-2"}, ids)
+	})
+
+	t.Run("error on non-existent atom", func(t *testing.T) {
+		s := setup(t)
+		_, err := s.TransitiveMembers(ctx, "nonexistent")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("honors context cancellation", func(t *testing.T) {
+		s := setup(t)
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := s.TransitiveMembers(cctx, "global")
+		require.Error(t, err)
+	})
+}
+
+func TestSpace_RemoveAtom(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *Space {
+		s, err := NewSpace(ctx)
+		require.NoError(t, err)
+		for _, id := range []string{"org-1", "user-1", "user-2"} {
+			require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom}))
+		}
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "l1", Type: MembershipLink, Source: "org-1", Target: "user-1"}))
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "l2", Type: MembershipLink, Source: "org-1", Target: "user-2"}))
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "l3", Type: AssociationLink, Source: "user-1", Target: "user-2"}))
+		require.NoError(t, s.AttachTensor(ctx, "user-1", &Tensor{ID: "t1", Shape: []int{1}, Data: []float64{1}}))
+		require.NoError(t, s.DefineBoundary(ctx, &DomainBoundary{ID: "b1", Type: ScopeBoundary, AtomIDs: []string{"org-1", "user-1", "user-2"}}))
+		return s
+	}
+
+	t.Run("cascades to links, tensor and boundaries", func(t *testing.T) {
+		s := setup(t)
+		require.NoError(t, s.RemoveAtom(ctx, "user-1"))
+
+		_, err := s.GetAtom(ctx, "user-1")
+		assert.Error(t, err)
+		assert.Empty(t, s.GetLinksForAtom(ctx, "user-1"))
+		links := s.GetLinksForAtom(ctx, "org-1")
+		require.Len(t, links, 1)
+		assert.Equal(t, "l2", links[0].ID)
+		assert.NotContains(t, s.tensorStore, "t1")
+
+		atoms, err := s.QueryByBoundary(ctx, "b1")
+		require.NoError(t, err)
+		require.Len(t, atoms, 2)
+		assert.Equal(t, []string{"org-1", "user-2"}, s.GetBoundaries(ctx)[0].AtomIDs)
+	})
+
+	t.Run("shared tensor is kept", func(t *testing.T) {
+		s := setup(t)
+		require.NoError(t, s.AttachTensor(ctx, "user-2", &Tensor{ID: "t1"}))
+		require.NoError(t, s.RemoveAtom(ctx, "user-1"))
+		_, err := s.GetTensor(ctx, "user-2")
+		assert.NoError(t, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		s := setup(t)
+		err := s.RemoveAtom(ctx, "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "atom missing not found")
+	})
+}
+
+func TestSpace_DirectionalLinks(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom}))
+	}
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "ab", Type: AssociationLink, Source: "a", Target: "b"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "cb", Type: AssociationLink, Source: "c", Target: "b"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "bc", Type: DependencyLink, Source: "b", Target: "c"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "bb", Type: AssociationLink, Source: "b", Target: "b"}))
+
+	linkIDs := func(links []*Link) []string {
+		ids := make([]string, 0, len(links))
+		for _, l := range links {
+			ids = append(ids, l.ID)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{"bc", "bb"}, linkIDs(s.GetOutgoingLinks(ctx, "b")))
+	assert.ElementsMatch(t, []string{"ab", "cb", "bb"}, linkIDs(s.GetIncomingLinks(ctx, "b")))
+	assert.ElementsMatch(t, []string{"ab", "cb", "bc", "bb"}, linkIDs(s.GetLinksForAtom(ctx, "b")))
+	assert.Empty(t, s.GetIncomingLinks(ctx, "a"))
+
+	t.Run("indexes stay consistent after removal", func(t *testing.T) {
+		require.NoError(t, s.RemoveLink(ctx, "cb"))
+		assert.ElementsMatch(t, []string{"ab", "bb"}, linkIDs(s.GetIncomingLinks(ctx, "b")))
+		assert.Empty(t, s.GetOutgoingLinks(ctx, "c"))
+		assert.NotContains(t, s.outgoing, "c")
+		assert.ElementsMatch(t, []string{"bc"}, linkIDs(s.GetLinksForAtom(ctx, "c")))
+
+		require.NoError(t, s.RemoveAtom(ctx, "b"))
+		assert.Empty(t, s.links)
+		assert.Empty(t, s.outgoing)
+		assert.Empty(t, s.incoming)
+	})
+
+	t.Run("remove unknown link", func(t *testing.T) {
+		err := s.RemoveLink(ctx, "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "link missing not found")
+	})
+}
+
+func TestSpace_FindPath(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	for _, id := range []string{"global", "org-1", "project-1", "resource-1", "isolated"} {
+		require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom}))
+	}
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "g-o", Type: ScopeLink, Source: "global", Target: "org-1"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "o-p", Type: ScopeLink, Source: "org-1", Target: "project-1"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "p-r", Type: ScopeLink, Source: "project-1", Target: "resource-1"}))
+	// a cycle and a longer detour must not affect the result
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "p-g", Type: AssociationLink, Source: "project-1", Target: "global"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "o-g", Type: AssociationLink, Source: "org-1", Target: "global"}))
+
+	linkIDs := func(links []*Link) []string {
+		ids := make([]string, 0, len(links))
+		for _, l := range links {
+			ids = append(ids, l.ID)
+		}
+		return ids
+	}
+
+	t.Run("shortest path", func(t *testing.T) {
+		path, err := s.FindPath(ctx, "global", "resource-1", 5)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"g-o", "o-p", "p-r"}, linkIDs(path))
+	})
+
+	t.Run("path longer than max depth", func(t *testing.T) {
+		_, err := s.FindPath(ctx, "global", "resource-1", 2)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no path from global to resource-1 within 2 links")
+	})
+
+	t.Run("no path", func(t *testing.T) {
+		_, err := s.FindPath(ctx, "resource-1", "global", 10)
+		require.Error(t, err)
+		_, err = s.FindPath(ctx, "global", "isolated", 10)
+		require.Error(t, err)
+	})
+
+	t.Run("same atom", func(t *testing.T) {
+		path, err := s.FindPath(ctx, "org-1", "org-1", 1)
+		require.NoError(t, err)
+		assert.Empty(t, path)
+	})
+
+	t.Run("unknown atom", func(t *testing.T) {
+		_, err := s.FindPath(ctx, "global", "missing", 3)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "atom missing not found")
+	})
+}
+
+func TestSpace_GetNeighbors(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	for _, id := range []string{"global", "org-1", "org-2", "project-1", "user-1", "group-1"} {
+		require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom}))
+	}
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "1", Type: ScopeLink, Source: "global", Target: "org-1"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "2", Type: ScopeLink, Source: "global", Target: "org-2"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "3", Type: ScopeLink, Source: "org-1", Target: "project-1"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "4", Type: MembershipLink, Source: "org-1", Target: "user-1"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "5", Type: MembershipLink, Source: "group-1", Target: "user-1"}))
+	// second path to project-1 must not duplicate it
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "6", Type: AssociationLink, Source: "user-1", Target: "project-1"}))
+
+	atomIDs := func(atoms []*Atom) []string {
+		ids := make([]string, 0, len(atoms))
+		for _, a := range atoms {
+			ids = append(ids, a.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name      string
+		hops      int
+		linkTypes []LinkType
+		want      []string
+	}{
+		{name: "one hop", hops: 1, want: []string{"org-1", "group-1", "project-1"}},
+		{name: "two hops", hops: 2, want: []string{"org-1", "group-1", "project-1", "global"}},
+		{name: "three hops", hops: 3, want: []string{"org-1", "group-1", "project-1", "global", "org-2"}},
+		{name: "exclude membership links", hops: 3, linkTypes: []LinkType{ScopeLink, AssociationLink}, want: []string{"project-1", "org-1", "global"}},
+		{name: "zero hops", hops: 0, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			neighbors, err := s.GetNeighbors(ctx, "user-1", tt.hops, tt.linkTypes)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, atomIDs(neighbors))
+		})
+	}
+
+	t.Run("unknown atom", func(t *testing.T) {
+		_, err := s.GetNeighbors(ctx, "missing", 1, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "atom missing not found")
+	})
+}
+
+func TestSpace_DetachTensor(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *Space {
+		s, err := NewSpace(ctx)
+		require.NoError(t, err)
+		for _, id := range []string{"a", "b", "c"} {
+			require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom}))
+		}
+		return s
+	}
+
+	t.Run("detach reclaims tensor", func(t *testing.T) {
+		s := setup(t)
+		require.NoError(t, s.AttachTensor(ctx, "a", &Tensor{ID: "t1", Shape: []int{1}, Data: []float64{1}}))
+		require.NoError(t, s.DetachTensor(ctx, "a"))
+
+		_, err := s.GetTensor(ctx, "a")
+		require.Error(t, err)
+		assert.NotContains(t, s.tensorStore, "t1")
+
+		err = s.DetachTensor(ctx, "a")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "atom a has no tensor")
+	})
+
+	t.Run("shared tensor survives until all referencers detach", func(t *testing.T) {
+		s := setup(t)
+		shared := &Tensor{ID: "shared", Shape: []int{1}, Data: []float64{1}}
+		require.NoError(t, s.AttachTensor(ctx, "a", shared))
+		require.NoError(t, s.AttachTensor(ctx, "b", shared))
+
+		require.NoError(t, s.DetachTensor(ctx, "a"))
+		tensor, err := s.GetTensor(ctx, "b")
+		require.NoError(t, err)
+		assert.Same(t, shared, tensor)
+
+		require.NoError(t, s.DetachTensor(ctx, "b"))
+		assert.Empty(t, s.tensorStore)
+	})
+
+	t.Run("gc reclaims unreferenced tensors", func(t *testing.T) {
+		s := setup(t)
+		require.NoError(t, s.AttachTensor(ctx, "a", &Tensor{ID: "t1"}))
+		require.NoError(t, s.AttachTensor(ctx, "b", &Tensor{ID: "t2"}))
+		// re-attaching replaces the reference but leaves t1 stored
+		require.NoError(t, s.AttachTensor(ctx, "a", &Tensor{ID: "t3"}))
+		s.tensorStore["orphan"] = &Tensor{ID: "orphan"}
+
+		assert.Equal(t, 2, s.GCTensors(ctx))
+		assert.Len(t, s.tensorStore, 2)
+		assert.Contains(t, s.tensorStore, "t2")
+		assert.Contains(t, s.tensorStore, "t3")
+		assert.Equal(t, 0, s.GCTensors(ctx))
+	})
+
+	t.Run("unknown atom", func(t *testing.T) {
+		s := setup(t)
+		err := s.DetachTensor(ctx, "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "atom missing not found")
+	})
+}
+
+func TestSpace_SharedTensors(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *Space {
+		s, err := NewSpace(ctx)
+		require.NoError(t, err)
+		for _, id := range []string{"a", "b", "c"} {
+			require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom}))
+		}
+		stored := &Tensor{ID: "t1", Shape: []int{2}, Data: []float64{1, 2}}
+		require.NoError(t, s.AttachTensor(ctx, "a", stored))
+		return s
+	}
+
+	t.Run("get by id", func(t *testing.T) {
+		s := setup(t)
+		tensor, err := s.GetTensorByID(ctx, "t1")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{1, 2}, tensor.Data)
+
+		_, err = s.GetTensorByID(ctx, "missing")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+		assert.Contains(t, err.Error(), "tensor missing not found")
+	})
+
+	t.Run("attach by id shares the stored tensor", func(t *testing.T) {
+		s := setup(t)
+		require.NoError(t, s.AttachTensor(ctx, "b", &Tensor{ID: "t1"}))
+
+		ta, err := s.GetTensor(ctx, "a")
+		require.NoError(t, err)
+		tb, err := s.GetTensor(ctx, "b")
+		require.NoError(t, err)
+		assert.Same(t, ta, tb)
+		assert.Equal(t, []float64{1, 2}, tb.Data)
+		assert.Equal(t, 2, s.tensorRefs["t1"])
+		assert.Len(t, s.tensorStore, 1)
+	})
+
+	t.Run("attach with data cannot replace a shared tensor", func(t *testing.T) {
+		s := setup(t)
+		require.NoError(t, s.AttachTensor(ctx, "b", &Tensor{ID: "t1"}))
+
+		err := s.AttachTensor(ctx, "c", &Tensor{ID: "t1", Shape: []int{1}, Data: []float64{9}})
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotUnique), err))
+		err = s.AttachTensor(ctx, "a", &Tensor{ID: "t1", Shape: []int{1}, Data: []float64{9}})
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotUnique), err))
+
+		tb, err := s.GetTensor(ctx, "b")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{1, 2}, tb.Data)
+		c, err := s.GetAtomCopy(ctx, "c")
+		require.NoError(t, err)
+		assert.Empty(t, c.TensorID)
+
+		// The sole holder can still replace its tensor
+		require.NoError(t, s.DetachTensor(ctx, "b"))
+		require.NoError(t, s.AttachTensor(ctx, "a", &Tensor{ID: "t1", Shape: []int{1}, Data: []float64{9}}))
+		ta, err := s.GetTensor(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{9}, ta.Data)
+	})
+
+	t.Run("detach and remove release the last reference", func(t *testing.T) {
+		s := setup(t)
+		require.NoError(t, s.AttachTensor(ctx, "b", &Tensor{ID: "t1"}))
+		require.NoError(t, s.AttachTensor(ctx, "c", &Tensor{ID: "t1"}))
+
+		require.NoError(t, s.DetachTensor(ctx, "a"))
+		require.NoError(t, s.RemoveAtom(ctx, "b"))
+		tensor, err := s.GetTensorByID(ctx, "t1")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{1, 2}, tensor.Data)
+		assert.Equal(t, 1, s.tensorRefs["t1"])
+
+		require.NoError(t, s.RemoveAtom(ctx, "c"))
+		_, err = s.GetTensorByID(ctx, "t1")
+		require.Error(t, err)
+		assert.Empty(t, s.tensorRefs)
+	})
+
+	t.Run("rename and upsert keep the count", func(t *testing.T) {
+		s := setup(t)
+		require.NoError(t, s.AttachTensor(ctx, "b", &Tensor{ID: "t1"}))
+		require.NoError(t, s.RenameAtom(ctx, "a", "a2"))
+		assert.Equal(t, 2, s.tensorRefs["t1"])
+
+		require.NoError(t, s.UpsertAtom(ctx, &Atom{ID: "a2", Type: EntityAtom}))
+		assert.Equal(t, 1, s.tensorRefs["t1"])
+		_, err := s.GetTensorByID(ctx, "t1")
+		require.NoError(t, err)
+
+		require.NoError(t, s.UpsertAtom(ctx, &Atom{ID: "b", Type: EntityAtom}))
+		_, err = s.GetTensorByID(ctx, "t1")
+		require.Error(t, err)
+	})
+}
+
+func TestSpace_AddTensors(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *Space {
+		s, err := NewSpace(ctx)
+		require.NoError(t, err)
+		for _, id := range []string{"a", "b", "c", "sum"} {
+			require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom}))
+		}
+		require.NoError(t, s.AttachTensor(ctx, "a", &Tensor{ID: "ta", Shape: []int{2, 2}, Data: []float64{1, 2, 3, 4}, DType: "float64", Device: "cpu"}))
+		require.NoError(t, s.AttachTensor(ctx, "b", &Tensor{ID: "tb", Shape: []int{2, 2}, Data: []float64{10, 20, 30, 40}, DType: "float64", Device: "cpu"}))
+		require.NoError(t, s.AttachTensor(ctx, "c", &Tensor{ID: "tc", Shape: []int{4}, Data: []float64{1, 1, 1, 1}}))
+		return s
+	}
+
+	t.Run("sum attached to result atom", func(t *testing.T) {
+		s := setup(t)
+		require.NoError(t, s.AddTensors(ctx, "a", "b", "sum"))
+		tensor, err := s.GetTensor(ctx, "sum")
+		require.NoError(t, err)
+		assert.Equal(t, []int{2, 2}, tensor.Shape)
+		assert.Equal(t, []float64{11, 22, 33, 44}, tensor.Data)
+		assert.Equal(t, "float64", tensor.DType)
+		assert.Equal(t, "cpu", tensor.Device)
+	})
+
+	t.Run("shared result tensor is not replaced", func(t *testing.T) {
+		s := setup(t)
+		require.NoError(t, s.AddTensors(ctx, "a", "b", "sum"))
+		require.NoError(t, s.AttachTensor(ctx, "c", &Tensor{ID: "sum_tensor"}))
+
+		err := s.AddTensors(ctx, "b", "b", "sum")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotUnique), err))
+		assert.Contains(t, err.Error(), "tensor sum_tensor is shared by other atoms")
+		tensor, err := s.GetTensor(ctx, "c")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{11, 22, 33, 44}, tensor.Data)
+
+		// the sole holder can still recompute its sum
+		require.NoError(t, s.DetachTensor(ctx, "c"))
+		require.NoError(t, s.AddTensors(ctx, "b", "b", "sum"))
+		tensor, err = s.GetTensor(ctx, "sum")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{20, 40, 60, 80}, tensor.Data)
+	})
+
+	tests := []struct {
+		name      string
+		a1, a2, r string
+		wantErr   string
+	}{
+		{name: "shape mismatch", a1: "a", a2: "c", r: "sum", wantErr: "shape mismatch: [2 2] and [4]"},
+		{name: "missing tensor", a1: "a", a2: "sum", r: "sum", wantErr: "atom sum has no tensor"},
+		{name: "missing result atom", a1: "a", a2: "b", r: "missing", wantErr: "atom missing not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := setup(t)
+			err := s.AddTensors(ctx, tt.a1, tt.a2, tt.r)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestSpace_GetByType(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "r2", Type: ResourceAtom}))
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "r1", Type: ResourceAtom}))
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "e1", Type: EntityAtom}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "m1", Type: MembershipLink, Source: "e1", Target: "r1"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "d1", Type: DependencyLink, Source: "r1", Target: "r2"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "m2", Type: MembershipLink, Source: "e1", Target: "r2"}))
+
+	atomIDs := func(atoms []*Atom) []string {
+		ids := make([]string, 0, len(atoms))
+		for _, a := range atoms {
+			ids = append(ids, a.ID)
+		}
+		return ids
+	}
+	linkIDs := func(links []*Link) []string {
+		ids := make([]string, 0, len(links))
+		for _, l := range links {
+			ids = append(ids, l.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"r1", "r2"}, atomIDs(s.GetAtomsByType(ctx, ResourceAtom)))
+	assert.Equal(t, []string{"e1"}, atomIDs(s.GetAtomsByType(ctx, EntityAtom)))
+	assert.Empty(t, s.GetAtomsByType(ctx, ConceptAtom))
+	assert.Equal(t, []string{"m1", "m2"}, linkIDs(s.GetLinksByType(ctx, MembershipLink)))
+	assert.Equal(t, []string{"d1"}, linkIDs(s.GetLinksByType(ctx, DependencyLink)))
+
+	// Upserting an atom under a new type moves it between indexes.
+	require.NoError(t, s.UpsertAtom(ctx, &Atom{ID: "r2", Type: ConceptAtom}))
+	assert.Equal(t, []string{"r1"}, atomIDs(s.GetAtomsByType(ctx, ResourceAtom)))
+	assert.Equal(t, []string{"r2"}, atomIDs(s.GetAtomsByType(ctx, ConceptAtom)))
+
+	require.NoError(t, s.RemoveLink(ctx, "m1"))
+	assert.Equal(t, []string{"m2"}, linkIDs(s.GetLinksByType(ctx, MembershipLink)))
+
+	require.NoError(t, s.RemoveAtom(ctx, "r1"))
+	assert.Empty(t, s.GetAtomsByType(ctx, ResourceAtom))
+	assert.Empty(t, s.GetLinksByType(ctx, DependencyLink))
+	assert.Equal(t, []string{"m2"}, linkIDs(s.GetLinksByType(ctx, MembershipLink)))
+}
+
+func TestSpace_UpdateAndRemoveBoundary(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom}))
+	}
+	require.NoError(t, s.DefineBoundary(ctx, &DomainBoundary{ID: "b1", Name: "first", Type: LogicalBoundary, AtomIDs: []string{"a", "b"}}))
+
+	require.NoError(t, s.UpdateBoundary(ctx, &DomainBoundary{ID: "b1", Name: "renamed", Type: SecurityBoundary, AtomIDs: []string{"c"}}))
+	atoms, err := s.QueryByBoundary(ctx, "b1")
+	require.NoError(t, err)
+	require.Len(t, atoms, 1)
+	assert.Equal(t, "c", atoms[0].ID)
+
+	boundaries := s.GetBoundaries(ctx)
+	require.Len(t, boundaries, 1)
+	assert.Equal(t, "renamed", boundaries[0].Name)
+	assert.Equal(t, SecurityBoundary, boundaries[0].Type)
+	assert.NotNil(t, boundaries[0].Properties)
+
+	err = s.UpdateBoundary(ctx, &DomainBoundary{ID: "missing"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boundary missing not found")
+
+	require.NoError(t, s.RemoveBoundary(ctx, "b1"))
+	_, err = s.QueryByBoundary(ctx, "b1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boundary b1 not found")
+	assert.Empty(t, s.GetBoundaries(ctx))
+
+	err = s.RemoveBoundary(ctx, "b1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boundary b1 not found")
+}
+
+func TestSpace_Export(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "org", Type: AggregateAtom, Name: "Org"}))
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "user", Type: EntityAtom, Name: "User", Attributes: map[string]interface{}{"email": "u@example.com"}}))
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "host", Type: ResourceAtom, Name: "Host"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "l1", Type: MembershipLink, Source: "user", Target: "org", Strength: 0.9}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "l2", Type: ScopeLink, Source: "org", Target: "host", Strength: 0.5}))
+	require.NoError(t, s.AttachTensor(ctx, "org", &Tensor{ID: "t1", Shape: []int{2}, Data: []float64{1, 2}, DType: "float64", Device: "cpu"}))
+	require.NoError(t, s.DefineBoundary(ctx, &DomainBoundary{ID: "b1", Name: "b1", Type: ScopeBoundary, AtomIDs: []string{"org", "host"}}))
+
+	t.Run("json round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, s.ExportJSON(ctx, &buf))
+
+		imported, err := ImportJSON(ctx, &buf)
+		require.NoError(t, err)
+
+		user, err := imported.GetAtom(ctx, "user")
+		require.NoError(t, err)
+		assert.Equal(t, EntityAtom, user.Type)
+		assert.Equal(t, "u@example.com", user.Attributes["email"])
+
+		links := imported.GetOutgoingLinks(ctx, "org")
+		require.Len(t, links, 1)
+		assert.Equal(t, "l2", links[0].ID)
+		assert.Equal(t, 0.5, links[0].Strength)
+
+		tensor, err := imported.GetTensor(ctx, "org")
+		require.NoError(t, err)
+		assert.Equal(t, []int{2}, tensor.Shape)
+		assert.Equal(t, []float64{1, 2}, tensor.Data)
+
+		atoms, err := imported.QueryByBoundary(ctx, "b1")
+		require.NoError(t, err)
+		assert.Len(t, atoms, 2)
+	})
+
+	t.Run("graphml", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, s.ExportGraphML(ctx, &buf))
+		assert.True(t, strings.HasPrefix(buf.String(), xml.Header))
+
+		var doc graphML
+		require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "directed", doc.Graph.EdgeDefault)
+		assert.Len(t, doc.Graph.Nodes, 3)
+		assert.Len(t, doc.Graph.Edges, 2)
+		assert.Contains(t, buf.String(), `<data key="tensor_shape">[2]</data>`)
+		assert.Contains(t, buf.String(), `<data key="boundaries">b1</data>`)
+	})
+
+	t.Run("nil writer", func(t *testing.T) {
+		err := s.ExportJSON(ctx, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "writer is nil")
+	})
+}
+
+func TestImportJSON(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("round trip preserves query results", func(t *testing.T) {
+		s, err := NewSpace(ctx)
+		require.NoError(t, err)
+		for _, a := range []*Atom{
+			{ID: "global", Type: AggregateAtom},
+			{ID: "org-1", Type: AggregateAtom},
+			{ID: "proj-1", Type: AggregateAtom},
+			{ID: "alice", Type: EntityAtom},
+			{ID: "bob", Type: EntityAtom},
+			{ID: "host-1", Type: ResourceAtom},
+			{ID: "host-2", Type: ResourceAtom},
+		} {
+			require.NoError(t, s.AddAtom(ctx, a))
+		}
+		for _, l := range []*Link{
+			{ID: "s1", Type: ScopeLink, Source: "global", Target: "org-1", Strength: 1},
+			{ID: "s2", Type: ScopeLink, Source: "org-1", Target: "proj-1", Strength: 1},
+			{ID: "m1", Type: MembershipLink, Source: "proj-1", Target: "alice", Strength: 0.8},
+			{ID: "m2", Type: MembershipLink, Source: "org-1", Target: "bob", Strength: 0.6},
+			{ID: "d1", Type: DependencyLink, Source: "host-1", Target: "host-2", Strength: 0.3},
+			{ID: "a1", Type: AssociationLink, Source: "alice", Target: "host-1", Strength: 0.5},
+		} {
+			require.NoError(t, s.AddLink(ctx, l))
+		}
+		shared := &Tensor{ID: "shared", Shape: []int{2}, Data: []float64{1, 2}, DType: "float64", Device: "cpu"}
+		require.NoError(t, s.AttachTensor(ctx, "host-1", shared))
+		require.NoError(t, s.AttachTensor(ctx, "host-2", shared))
+		require.NoError(t, s.AttachTensor(ctx, "org-1", &Tensor{ID: "org", Shape: []int{1}, Data: []float64{3}}))
+		require.NoError(t, s.DefineBoundary(ctx, &DomainBoundary{ID: "sec", Type: SecurityBoundary, AtomIDs: []string{"alice", "host-1"}}))
+		require.NoError(t, s.DefineBoundary(ctx, &DomainBoundary{ID: "tx", Type: TransactionalBoundary, AtomIDs: []string{"org-1"}}))
+
+		var buf bytes.Buffer
+		require.NoError(t, s.ExportJSON(ctx, &buf))
+		imported, err := ImportJSON(ctx, &buf)
+		require.NoError(t, err)
+
+		atomIDs := func(atoms []*Atom) []string {
+			ids := make([]string, 0, len(atoms))
+			for _, a := range atoms {
+				ids = append(ids, a.ID)
+			}
+			return ids
+		}
+		linkIDs := func(links []*Link) []string {
+			ids := make([]string, 0, len(links))
+			for _, l := range links {
+				ids = append(ids, l.ID)
+			}
+			return ids
+		}
+
+		for _, typ := range []AtomType{AggregateAtom, EntityAtom, ResourceAtom} {
+			assert.Equal(t, atomIDs(s.GetAtomsByType(ctx, typ)), atomIDs(imported.GetAtomsByType(ctx, typ)))
+		}
+		for _, typ := range []LinkType{ScopeLink, MembershipLink, DependencyLink, AssociationLink} {
+			assert.Equal(t, linkIDs(s.GetLinksByType(ctx, typ)), linkIDs(imported.GetLinksByType(ctx, typ)))
+		}
+		for _, id := range []string{"global", "org-1", "alice", "host-2"} {
+			assert.Equal(t, linkIDs(s.GetLinksForAtom(ctx, id)), linkIDs(imported.GetLinksForAtom(ctx, id)))
+		}
+
+		want, err := s.TransitiveMembers(ctx, "global")
+		require.NoError(t, err)
+		got, err := imported.TransitiveMembers(ctx, "global")
+		require.NoError(t, err)
+		assert.Equal(t, atomIDs(want), atomIDs(got))
+
+		wantPath, err := s.FindPath(ctx, "global", "host-2", 10)
+		require.NoError(t, err)
+		gotPath, err := imported.FindPath(ctx, "global", "host-2", 10)
+		require.NoError(t, err)
+		assert.Equal(t, linkIDs(wantPath), linkIDs(gotPath))
+
+		for _, id := range []string{"sec", "tx"} {
+			want, err := s.QueryByBoundary(ctx, id)
+			require.NoError(t, err)
+			got, err := imported.QueryByBoundary(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, atomIDs(want), atomIDs(got))
+		}
+
+		for _, id := range []string{"host-1", "host-2", "org-1"} {
+			want, err := s.GetTensor(ctx, id)
+			require.NoError(t, err)
+			got, err := imported.GetTensor(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		}
+
+		orig, err := s.GetAtom(ctx, "alice")
+		require.NoError(t, err)
+		copied, err := imported.GetAtom(ctx, "alice")
+		require.NoError(t, err)
+		assert.True(t, orig.CreatedAt.Equal(copied.CreatedAt))
+	})
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "link to missing atom",
+			input:   `{"atoms":[{"ID":"a"}],"links":[{"ID":"l1","Source":"a","Target":"b"}]}`,
+			wantErr: "link l1 references missing atom b",
+		},
+		{
+			name:    "tensor on missing atom",
+			input:   `{"atoms":[{"ID":"a"}],"tensors":[{"ID":"t1","AtomIDs":["b"]}]}`,
+			wantErr: "tensor t1 references missing atom b",
+		},
+		{
+			name:    "atom with missing tensor",
+			input:   `{"atoms":[{"ID":"a","TensorID":"t1"}]}`,
+			wantErr: "atom a references missing tensor t1",
+		},
+		{
+			name:    "boundary with missing atom",
+			input:   `{"atoms":[{"ID":"a"}],"boundaries":[{"ID":"b1","AtomIDs":["a","b"]}]}`,
+			wantErr: "boundary b1 references missing atom b",
+		},
+		{
+			name:    "duplicate atom",
+			input:   `{"atoms":[{"ID":"a"},{"ID":"a"}]}`,
+			wantErr: "duplicate atom a",
+		},
+		{
+			name:    "malformed",
+			input:   `{"atoms":`,
+			wantErr: "decoding space",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ImportJSON(ctx, strings.NewReader(tt.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestSpace_FindStrongestPath(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+
+	for _, id := range []string{"a", "b", "c", "d", "e", "island"} {
+		require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom}))
+	}
+	// a -> d directly is short but weak; a -> b -> c -> d is longer but
+	// stronger overall (0.9^3 = 0.729 > 0.2).
+	for _, l := range []*Link{
+		{ID: "ad", Type: AssociationLink, Source: "a", Target: "d", Strength: 0.2},
+		{ID: "ab", Type: AssociationLink, Source: "a", Target: "b", Strength: 0.9},
+		{ID: "bc", Type: AssociationLink, Source: "b", Target: "c", Strength: 0.9},
+		{ID: "cd", Type: AssociationLink, Source: "c", Target: "d", Strength: 0.9},
+		{ID: "de", Type: AssociationLink, Source: "d", Target: "e", Strength: 0},
+	} {
+		require.NoError(t, s.AddLink(ctx, l))
+	}
+
+	t.Run("prefers stronger longer path", func(t *testing.T) {
+		path, strength, err := s.FindStrongestPath(ctx, "a", "d")
+		require.NoError(t, err)
+		ids := make([]string, 0, len(path))
+		for _, l := range path {
+			ids = append(ids, l.ID)
+		}
+		assert.Equal(t, []string{"ab", "bc", "cd"}, ids)
+		assert.InDelta(t, 0.729, strength, 1e-9)
+	})
+
+	t.Run("same atom", func(t *testing.T) {
+		path, strength, err := s.FindStrongestPath(ctx, "a", "a")
+		require.NoError(t, err)
+		assert.Empty(t, path)
+		assert.Equal(t, 1.0, strength)
+	})
+
+	t.Run("zero strength links are not followed", func(t *testing.T) {
+		_, _, err := s.FindStrongestPath(ctx, "a", "e")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no path from a to e")
+	})
+
+	t.Run("unknown atom", func(t *testing.T) {
+		_, _, err := s.FindStrongestPath(ctx, "a", "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "atom missing not found")
+	})
+
+	t.Run("neighbors above strength", func(t *testing.T) {
+		ids := func(atoms []*Atom) []string {
+			out := make([]string, 0, len(atoms))
+			for _, a := range atoms {
+				out = append(out, a.ID)
+			}
+			return out
+		}
+		assert.Equal(t, []string{"b"}, ids(s.GetNeighborsAboveStrength(ctx, "a", 0.5)))
+		assert.Equal(t, []string{"d", "b"}, ids(s.GetNeighborsAboveStrength(ctx, "a", 0.1)))
+		assert.Equal(t, []string{"a", "c"}, ids(s.GetNeighborsAboveStrength(ctx, "d", 0.1)))
+		assert.Empty(t, s.GetNeighborsAboveStrength(ctx, "island", 0))
+	})
+}
+
+func TestSpace_ConnectedComponentsAndDegree(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+
+	for _, id := range []string{"a", "b", "c", "x", "y", "z"} {
+		require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom}))
+	}
+	for _, l := range []*Link{
+		{ID: "ab", Type: AssociationLink, Source: "a", Target: "b"},
+		{ID: "cb", Type: AssociationLink, Source: "c", Target: "b"},
+		{ID: "ac", Type: AssociationLink, Source: "a", Target: "c"},
+		{ID: "yx", Type: AssociationLink, Source: "y", Target: "x"},
+		{ID: "zz", Type: AssociationLink, Source: "z", Target: "z"},
+	} {
+		require.NoError(t, s.AddLink(ctx, l))
+	}
+
+	assert.Equal(t, [][]string{{"a", "b", "c"}, {"x", "y"}, {"z"}}, s.ConnectedComponents(ctx))
+	assert.Equal(t, map[string]int{"a": 2, "b": 2, "c": 2, "x": 1, "y": 1, "z": 2}, s.DegreeCentrality(ctx))
+
+	require.NoError(t, s.RemoveLink(ctx, "zz"))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "cx", Type: AssociationLink, Source: "c", Target: "x"}))
+	assert.Equal(t, [][]string{{"a", "b", "c", "x", "y"}, {"z"}}, s.ConnectedComponents(ctx))
+	assert.Equal(t, 0, s.DegreeCentrality(ctx)["z"])
+}
+
+func TestSpace_MatchTriples(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+
+	for _, a := range []*Atom{
+		{ID: "org-1", Type: AggregateAtom},
+		{ID: "org-2", Type: AggregateAtom},
+		{ID: "alice", Type: EntityAtom},
+		{ID: "bob", Type: EntityAtom},
+		{ID: "host", Type: ResourceAtom},
+	} {
+		require.NoError(t, s.AddAtom(ctx, a))
+	}
+	for _, l := range []*Link{
+		{ID: "m1", Type: MembershipLink, Source: "org-2", Target: "bob"},
+		{ID: "m2", Type: MembershipLink, Source: "org-1", Target: "alice"},
+		{ID: "m3", Type: MembershipLink, Source: "org-1", Target: "host"},
+		{ID: "s1", Type: ScopeLink, Source: "org-1", Target: "org-2"},
+		{ID: "a1", Type: AssociationLink, Source: "alice", Target: "host"},
+	} {
+		require.NoError(t, s.AddLink(ctx, l))
+	}
+
+	describe := func(triples []Triple) []string {
+		out := make([]string, 0, len(triples))
+		for _, tr := range triples {
+			out = append(out, tr.Source.ID+"-"+tr.Link.ID+"->"+tr.Target.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name       string
+		sourceType AtomType
+		linkType   LinkType
+		targetType AtomType
+		want       []string
+	}{
+		{
+			name:       "org membership user",
+			sourceType: AggregateAtom,
+			linkType:   MembershipLink,
+			targetType: EntityAtom,
+			want:       []string{"org-1-m2->alice", "org-2-m1->bob"},
+		},
+		{
+			name:       "wildcard target",
+			sourceType: AggregateAtom,
+			linkType:   MembershipLink,
+			want:       []string{"org-1-m2->alice", "org-1-m3->host", "org-2-m1->bob"},
+		},
+		{
+			name:       "wildcard link",
+			sourceType: AggregateAtom,
+			want:       []string{"org-1-m2->alice", "org-1-m3->host", "org-1-s1->org-2", "org-2-m1->bob"},
+		},
+		{
+			name:       "target type only",
+			targetType: ResourceAtom,
+			want:       []string{"alice-a1->host", "org-1-m3->host"},
+		},
+		{
+			name: "all wildcards",
+			want: []string{"alice-a1->host", "org-1-m2->alice", "org-1-m3->host", "org-1-s1->org-2", "org-2-m1->bob"},
+		},
+		{
+			name:       "no match",
+			sourceType: ResourceAtom,
+			want:       []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			triples, err := s.MatchTriples(ctx, tt.sourceType, tt.linkType, tt.targetType)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, describe(triples))
+		})
+	}
+}
+
+func TestSpace_ExtractSubgraph(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+
+	for _, id := range []string{"a", "b", "c", "outside"} {
+		require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom, Attributes: map[string]interface{}{"k": id}}))
+	}
+	for _, l := range []*Link{
+		{ID: "ab", Type: AssociationLink, Source: "a", Target: "b"},
+		{ID: "bc", Type: AssociationLink, Source: "b", Target: "c"},
+		{ID: "c-out", Type: AssociationLink, Source: "c", Target: "outside"},
+		{ID: "out-a", Type: AssociationLink, Source: "outside", Target: "a"},
+	} {
+		require.NoError(t, s.AddLink(ctx, l))
+	}
+	require.NoError(t, s.AttachTensor(ctx, "a", &Tensor{ID: "ta", Shape: []int{1}, Data: []float64{1}}))
+	require.NoError(t, s.AttachTensor(ctx, "outside", &Tensor{ID: "to", Shape: []int{1}, Data: []float64{2}}))
+	require.NoError(t, s.DefineBoundary(ctx, &DomainBoundary{ID: "b1", Type: LogicalBoundary, AtomIDs: []string{"a", "b", "c", "gone"}}))
+
+	sub, err := s.ExtractSubgraph(ctx, "b1")
+	require.NoError(t, err)
+
+	assert.Len(t, sub.GetAtomsByType(ctx, EntityAtom), 3)
+	links := sub.GetLinksByType(ctx, AssociationLink)
+	ids := make([]string, 0, len(links))
+	for _, l := range links {
+		ids = append(ids, l.ID)
+	}
+	assert.Equal(t, []string{"ab", "bc"}, ids)
+	_, err = sub.GetAtom(ctx, "outside")
+	require.Error(t, err)
+
+	tensor, err := sub.GetTensor(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1}, tensor.Data)
+
+	atoms, err := sub.QueryByBoundary(ctx, "b1")
+	require.NoError(t, err)
+	assert.Len(t, atoms, 3)
+
+	// The subgraph is independent of the original space.
+	atom, err := sub.GetAtom(ctx, "a")
+	require.NoError(t, err)
+	atom.Attributes["k"] = "changed"
+	orig, err := s.GetAtom(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", orig.Attributes["k"])
+
+	_, err = s.ExtractSubgraph(ctx, "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boundary missing not found")
+}
+
+func TestSpace_UpdateAtom(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "a", Type: EntityAtom, Attributes: map[string]interface{}{"keep": 1, "change": 1}}))
+
+	t.Run("merge", func(t *testing.T) {
+		require.NoError(t, s.UpdateAtom(ctx, "a", map[string]interface{}{"change": 2, "new": 3}))
+		atom, err := s.GetAtom(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"keep": 1, "change": 2, "new": 3}, atom.Attributes)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		attrs := map[string]interface{}{"only": true}
+		require.NoError(t, s.ReplaceAttributes(ctx, "a", attrs))
+		attrs["only"] = false
+		atom, err := s.GetAtom(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"only": true}, atom.Attributes)
+	})
+
+	t.Run("missing atom", func(t *testing.T) {
+		err := s.UpdateAtom(ctx, "missing", map[string]interface{}{"k": 1})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "atom missing not found")
+		err = s.ReplaceAttributes(ctx, "missing", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "atom missing not found")
+	})
+
+	t.Run("concurrent updates and reads", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, s.UpdateAtom(ctx, "a", map[string]interface{}{fmt.Sprintf("k%d", i): i}))
+			}(i)
+			go func() {
+				defer wg.Done()
+				_, err := s.GetAtom(ctx, "a")
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		atom, err := s.GetAtom(ctx, "a")
+		require.NoError(t, err)
+		for i := 0; i < 20; i++ {
+			assert.Equal(t, i, atom.Attributes[fmt.Sprintf("k%d", i)])
+		}
+	})
+}
+
+func TestSpace_GetAtomCopy(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "a", Type: EntityAtom, Name: "A", Attributes: map[string]interface{}{"k": "v"}}))
+
+	c, err := s.GetAtomCopy(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "A", c.Name)
+	c.Attributes["k"] = "changed"
+	c.Name = "changed"
+
+	orig, err := s.GetAtom(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "A", orig.Name)
+	assert.Equal(t, "v", orig.Attributes["k"])
+
+	_, err = s.GetAtomCopy(ctx, "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "atom missing not found")
+}
+
+func TestSpace_BidirectionalLinks(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom}))
+	}
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "ab", Type: AssociationLink, Source: "a", Target: "b", Strength: 0.5, Bidirectional: true}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "bc", Type: DependencyLink, Source: "b", Target: "c", Strength: 0.5}))
+
+	ids := func(links []*Link) []string {
+		out := make([]string, 0, len(links))
+		for _, l := range links {
+			out = append(out, l.ID)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"ab"}, ids(s.GetOutgoingLinks(ctx, "a")))
+	assert.Equal(t, []string{"ab"}, ids(s.GetIncomingLinks(ctx, "a")))
+	assert.Equal(t, []string{"bc", "ab"}, ids(s.GetOutgoingLinks(ctx, "b")))
+	assert.Equal(t, []string{"ab"}, ids(s.GetIncomingLinks(ctx, "b")))
+	assert.Equal(t, []string{"bc", "ab"}, ids(s.GetLinksForAtom(ctx, "b")))
+
+	t.Run("bidirectional link traversed in reverse", func(t *testing.T) {
+		path, err := s.FindPath(ctx, "b", "a", 3)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ab"}, ids(path))
+
+		path, err = s.FindPath(ctx, "a", "c", 3)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ab", "bc"}, ids(path))
+
+		path, strength, err := s.FindStrongestPath(ctx, "b", "a")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ab"}, ids(path))
+		assert.Equal(t, 0.5, strength)
+	})
+
+	t.Run("directed link is not traversed in reverse", func(t *testing.T) {
+		_, err := s.FindPath(ctx, "c", "b", 3)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no path from c to b")
+	})
+
+	t.Run("graphml marks undirected edges", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, s.ExportGraphML(ctx, &buf))
+		assert.Contains(t, buf.String(), `<edge id="ab" source="a" target="b" directed="false">`)
+		assert.Contains(t, buf.String(), `<edge id="bc" source="b" target="c">`)
+	})
+}
+
+func TestSpace_ListAtoms(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	atoms, err := s.ListAtoms(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, atoms)
+
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "b", Type: EntityAtom}))
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "a", Type: ResourceAtom, Attributes: map[string]interface{}{"k": "v"}}))
+
+	atoms, err = s.ListAtoms(ctx)
+	require.NoError(t, err)
+	require.Len(t, atoms, 2)
+	assert.Equal(t, "a", atoms[0].ID)
+	assert.Equal(t, "b", atoms[1].ID)
+
+	atoms[0].Attributes["k"] = "changed"
+	orig, err := s.GetAtom(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "v", orig.Attributes["k"])
+}
+
+func TestSpace_SetTensorValues(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "a", Type: EntityAtom}))
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "bare", Type: EntityAtom}))
+	require.NoError(t, s.AttachTensor(ctx, "a", &Tensor{ID: "t", Shape: []int{3}, Data: []float64{0, 0, 0}}))
+
+	require.NoError(t, s.SetTensorValues(ctx, "a", map[int]float64{0: 1.5, 2: 3}))
+	tensor, err := s.GetTensor(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.5, 0, 3}, tensor.Data)
+
+	err = s.SetTensorValues(ctx, "a", map[int]float64{1: 9, 3: 9})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "index 3 out of range for tensor t of length 3")
+	assert.Equal(t, []float64{1.5, 0, 3}, tensor.Data)
+
+	err = s.SetTensorValues(ctx, "bare", map[int]float64{0: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "atom bare has no tensor")
+}
+
+func TestSpace_ErrorCodes(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "a", Type: EntityAtom}))
+
+	notFound := map[string]error{}
+	_, notFound["GetAtom"] = s.GetAtom(ctx, "missing")
+	_, notFound["GetTensor"] = s.GetTensor(ctx, "missing")
+	_, notFound["QueryByBoundary"] = s.QueryByBoundary(ctx, "missing")
+	notFound["AttachTensor"] = s.AttachTensor(ctx, "missing", &Tensor{ID: "t"})
+	notFound["AddLink"] = s.AddLink(ctx, &Link{ID: "l", Source: "a", Target: "missing"})
+	for name, err := range notFound {
+		require.Error(t, err, name)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err), name)
+	}
+
+	invalid := map[string]error{
+		"AddAtom":         s.AddAtom(ctx, nil),
+		"AddLink":         s.AddLink(ctx, nil),
+		"AttachTensor":    s.AttachTensor(ctx, "a", nil),
+		"DefineBoundary":  s.DefineBoundary(ctx, nil),
+		"AddLink(source)": s.AddLink(ctx, &Link{ID: "l", Target: "a"}),
+	}
+	for name, err := range invalid {
+		require.Error(t, err, name)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err), name)
+	}
+}
+
+func TestSpace_AddAtoms(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) *Space {
+		t.Helper()
+		s, err := NewSpace(ctx)
+		require.NoError(t, err)
+		require.NoError(t, s.AddAtom(ctx, &Atom{ID: "existing", Type: EntityAtom}))
+		return s
+	}
+	batch := func() []*Atom {
+		return []*Atom{
+			{ID: "a", Type: EntityAtom},
+			nil,
+			{ID: "existing", Type: EntityAtom},
+			{ID: "", Type: EntityAtom},
+			{ID: "b", Type: ResourceAtom},
+			{ID: "a", Type: ResourceAtom},
+		}
+	}
+
+	t.Run("partial", func(t *testing.T) {
+		s := setup(t)
+		err := s.AddAtoms(ctx, batch())
+		require.Error(t, err)
+		joined, ok := err.(interface{ Unwrap() []error })
+		require.True(t, ok)
+		assert.Len(t, joined.Unwrap(), 4)
+		assert.ErrorContains(t, err, "atom 1 is nil")
+		assert.ErrorContains(t, err, "atom 2: duplicate atom ID existing")
+		assert.ErrorContains(t, err, "atom 3 has an empty ID")
+		assert.ErrorContains(t, err, "atom 5: duplicate atom ID a")
+
+		a, err := s.GetAtom(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, EntityAtom, a.Type)
+		assert.False(t, a.CreatedAt.IsZero())
+		assert.NotNil(t, a.Attributes)
+		_, err = s.GetAtom(ctx, "b")
+		require.NoError(t, err)
+		assert.Len(t, s.GetAtomsByType(ctx, ResourceAtom), 1)
+	})
+
+	t.Run("all-or-nothing", func(t *testing.T) {
+		s := setup(t)
+		err := s.AddAtoms(ctx, batch(), WithAllOrNothing())
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "atom 1 is nil")
+		atoms, err := s.ListAtoms(ctx)
+		require.NoError(t, err)
+		assert.Len(t, atoms, 1)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		s := setup(t)
+		err := s.AddAtoms(ctx, []*Atom{{ID: "a"}, {ID: "b"}}, WithAllOrNothing())
+		require.NoError(t, err)
+		atoms, err := s.ListAtoms(ctx)
+		require.NoError(t, err)
+		assert.Len(t, atoms, 3)
+	})
+}
+
+func TestSpace_AddLinks(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) *Space {
+		t.Helper()
+		s, err := NewSpace(ctx)
+		require.NoError(t, err)
+		require.NoError(t, s.AddAtoms(ctx, []*Atom{{ID: "a"}, {ID: "b"}, {ID: "c"}}))
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "existing", Type: InheritanceLink, Source: "a", Target: "b"}))
+		return s
+	}
+	batch := func() []*Link {
+		return []*Link{
+			{ID: "l1", Type: InheritanceLink, Source: "b", Target: "c"},
+			{ID: "l2", Type: InheritanceLink, Source: "a", Target: "missing"},
+			nil,
+			{ID: "existing", Type: InheritanceLink, Source: "a", Target: "c"},
+			{ID: "l3", Type: InheritanceLink, Source: "a"},
+			{ID: "l1", Type: InheritanceLink, Source: "c", Target: "a"},
+			{ID: "l4", Type: InheritanceLink, Source: "c", Target: "a"},
+		}
+	}
+
+	t.Run("partial", func(t *testing.T) {
+		s := setup(t)
+		err := s.AddLinks(ctx, batch())
+		require.Error(t, err)
+		joined, ok := err.(interface{ Unwrap() []error })
+		require.True(t, ok)
+		assert.Len(t, joined.Unwrap(), 5)
+		assert.ErrorContains(t, err, "link 1 (l2): atom missing not found")
+		assert.ErrorContains(t, err, "link 2 is nil")
+		assert.ErrorContains(t, err, "link 3: duplicate link ID existing")
+		assert.ErrorContains(t, err, "link 4 (l3): source or target is empty")
+		assert.ErrorContains(t, err, "link 5: duplicate link ID l1")
+
+		var ids []string
+		for _, link := range s.GetLinksByType(ctx, InheritanceLink) {
+			ids = append(ids, link.ID)
+			assert.False(t, link.CreatedAt.IsZero())
+		}
+		assert.Equal(t, []string{"existing", "l1", "l4"}, ids)
+		assert.Len(t, s.GetOutgoingLinks(ctx, "c"), 1)
+	})
+
+	t.Run("all-or-nothing", func(t *testing.T) {
+		s := setup(t)
+		err := s.AddLinks(ctx, batch(), WithAllOrNothing())
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "link 2 is nil")
+		assert.Len(t, s.GetLinksByType(ctx, InheritanceLink), 1)
+	})
+}
+
+func TestTensor_ReshapeAndAt(t *testing.T) {
+	tensor := &Tensor{ID: "t", Shape: []int{2, 3}, Data: []float64{0, 1, 2, 3, 4, 5}}
+
+	v, err := tensor.At(1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, v)
+	v, err = tensor.At(0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, v)
+
+	newShape := []int{3, 2}
+	require.NoError(t, tensor.Reshape(newShape))
+	newShape[0] = 6
+	assert.Equal(t, []int{3, 2}, tensor.Shape)
+	v, err = tensor.At(1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, v)
+	v, err = tensor.At(2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, v)
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr string
+	}{
+		{
+			name:    "reshape size mismatch",
+			call:    func() error { return tensor.Reshape([]int{4, 2}) },
+			wantErr: "shape [4 2] holds 8 elements but tensor t has 6",
+		},
+		{
+			name:    "reshape non-positive dimension",
+			call:    func() error { return tensor.Reshape([]int{-2, -3}) },
+			wantErr: "dimension 0 has non-positive size -2",
+		},
+		{
+			name:    "reshape empty",
+			call:    func() error { return tensor.Reshape(nil) },
+			wantErr: "shape is empty",
+		},
+		{
+			name: "at out of range",
+			call: func() error {
+				_, err := tensor.At(3, 0)
+				return err
+			},
+			wantErr: "index 3 out of range for dimension 0 of size 3",
+		},
+		{
+			name: "at negative",
+			call: func() error {
+				_, err := tensor.At(0, -1)
+				return err
+			},
+			wantErr: "index -1 out of range for dimension 1 of size 2",
+		},
+		{
+			name: "at rank mismatch",
+			call: func() error {
+				_, err := tensor.At(1)
+				return err
+			},
+			wantErr: "got 1 indices for tensor t of rank 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+	assert.Equal(t, []int{3, 2}, tensor.Shape)
+
+	tensor.Flatten()
+	assert.Equal(t, []int{6}, tensor.Shape)
+	v, err = tensor.At(4)
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, v)
+}
+
+func TestSpace_LinkStrength(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) *Space {
+		t.Helper()
+		s, err := NewSpace(ctx)
+		require.NoError(t, err)
+		require.NoError(t, s.AddAtoms(ctx, []*Atom{{ID: "a"}, {ID: "b"}, {ID: "c"}}))
+		require.NoError(t, s.AddLinks(ctx, []*Link{
+			{ID: "ab", Type: InheritanceLink, Source: "a", Target: "b", Strength: 1.0},
+			{ID: "bc", Type: InheritanceLink, Source: "b", Target: "c", Strength: 0.5},
+			{ID: "ca", Type: InheritanceLink, Source: "c", Target: "a", Strength: 0.2},
+		}))
+		return s
+	}
+	strengths := func(s *Space) map[string]float64 {
+		got := make(map[string]float64)
+		for _, link := range s.GetLinksByType(ctx, InheritanceLink) {
+			got[link.ID] = link.Strength
+		}
+		return got
+	}
+
+	t.Run("update", func(t *testing.T) {
+		s := setup(t)
+		require.NoError(t, s.UpdateLinkStrength(ctx, "bc", 0.9))
+		assert.Equal(t, 0.9, strengths(s)["bc"])
+
+		err := s.UpdateLinkStrength(ctx, "missing", 0.5)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+
+		for _, strength := range []float64{-0.1, 1.1, math.NaN()} {
+			err := s.UpdateLinkStrength(ctx, "bc", strength)
+			require.Error(t, err)
+			assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		}
+		assert.Equal(t, 0.9, strengths(s)["bc"])
+	})
+
+	t.Run("decay", func(t *testing.T) {
+		s := setup(t)
+		removed, err := s.DecayLinks(ctx, 0.5)
+		require.NoError(t, err)
+		assert.Zero(t, removed)
+		assert.InDeltaMapValues(t, map[string]float64{"ab": 0.5, "bc": 0.25, "ca": 0.1}, strengths(s), 1e-9)
+
+		_, err = s.DecayLinks(ctx, 1.5)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+
+	t.Run("decay prunes below floor", func(t *testing.T) {
+		s := setup(t)
+		removed, err := s.DecayLinks(ctx, 0.5, WithStrengthFloor(0.2))
+		require.NoError(t, err)
+		assert.Equal(t, 1, removed)
+		assert.InDeltaMapValues(t, map[string]float64{"ab": 0.5, "bc": 0.25}, strengths(s), 1e-9)
+		assert.Empty(t, s.GetOutgoingLinks(ctx, "c"))
+		assert.Empty(t, s.GetIncomingLinks(ctx, "a"))
+	})
+}
+
+func TestSpace_DuplicateAtom(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.AddAtoms(ctx, []*Atom{{ID: "a", Name: "original"}, {ID: "b"}}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "ab", Type: InheritanceLink, Source: "a", Target: "b"}))
+	require.NoError(t, s.AttachTensor(ctx, "a", &Tensor{ID: "t1", Shape: []int{1}, Data: []float64{1}}))
+
+	t.Run("add rejects duplicate", func(t *testing.T) {
+		err := s.AddAtom(ctx, &Atom{ID: "a", Name: "replacement"})
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotUnique), err))
+		assert.Contains(t, err.Error(), "atom a already exists")
+
+		atom, err := s.GetAtom(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, "original", atom.Name)
+		assert.Equal(t, "t1", atom.TensorID)
+	})
+
+	t.Run("upsert replaces", func(t *testing.T) {
+		original, err := s.GetAtomCopy(ctx, "a")
+		require.NoError(t, err)
+		before := time.Now()
+		require.NoError(t, s.UpsertAtom(ctx, &Atom{ID: "a", Name: "replacement", Type: ConceptAtom}))
+		atom, err := s.GetAtom(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, "replacement", atom.Name)
+		assert.Equal(t, original.CreatedAt, atom.CreatedAt)
+		assert.False(t, atom.UpdatedAt.Before(before))
+		concepts := s.GetAtomsByType(ctx, ConceptAtom)
+		require.Len(t, concepts, 1)
+		assert.Equal(t, "a", concepts[0].ID)
+
+		// links to the ID survive while the unreferenced tensor is released
+		assert.Len(t, s.GetOutgoingLinks(ctx, "a"), 1)
+		_, err = s.GetTensor(ctx, "a")
+		require.Error(t, err)
+		assert.Zero(t, s.GCTensors(ctx))
+	})
+
+	t.Run("upsert adds", func(t *testing.T) {
+		require.NoError(t, s.UpsertAtom(ctx, &Atom{ID: "c"}))
+		_, err := s.GetAtom(ctx, "c")
+		require.NoError(t, err)
+
+		err = s.UpsertAtom(ctx, nil)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+}
+
+func TestSpace_MoveTensor(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.AddAtoms(ctx, []*Atom{{ID: "a"}, {ID: "b"}, {ID: "sum"}}))
+	require.NoError(t, s.AttachTensor(ctx, "a", &Tensor{ID: "ta", Shape: []int{2}, Data: []float64{1, 2}, Device: CPUDevice}))
+	require.NoError(t, s.AttachTensor(ctx, "b", &Tensor{ID: "tb", Shape: []int{2}, Data: []float64{3, 4}}))
+
+	// an empty device is the CPU
+	require.NoError(t, s.AddTensors(ctx, "a", "b", "sum"))
+
+	require.NoError(t, s.MoveTensor(ctx, "ta", CUDADevice))
+	tensor, err := s.GetTensor(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, CUDADevice, tensor.Device)
+
+	err = s.AddTensors(ctx, "a", "b", "sum")
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	assert.Contains(t, err.Error(), "device mismatch: tensor ta is on cuda and tensor tb is on cpu")
+
+	require.NoError(t, s.MoveTensor(ctx, "tb", CUDADevice))
+	require.NoError(t, s.AddTensors(ctx, "a", "b", "sum"))
+	sum, err := s.GetTensor(ctx, "sum")
+	require.NoError(t, err)
+	assert.Equal(t, CUDADevice, sum.Device)
+
+	require.NoError(t, s.MoveTensor(ctx, "ta", CUDADevice))
+	require.NoError(t, s.MoveTensor(ctx, "ta", MPSDevice))
+	moves := s.GetTensorMoves(ctx, "ta")
+	require.Len(t, moves, 2)
+	assert.Equal(t, CPUDevice, moves[0].From)
+	assert.Equal(t, CUDADevice, moves[0].To)
+	assert.Equal(t, CUDADevice, moves[1].From)
+	assert.Equal(t, MPSDevice, moves[1].To)
+	assert.False(t, moves[0].MovedAt.IsZero())
+
+	err = s.MoveTensor(ctx, "ta", "tpu")
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	err = s.MoveTensor(ctx, "missing", CPUDevice)
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+}
+
+func TestSpace_QueryAtoms(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.AddAtoms(ctx, []*Atom{
+		{ID: "h3", Type: ResourceAtom, Attributes: map[string]interface{}{"region": "us-west", "cores": 8}},
+		{ID: "h1", Type: ResourceAtom, Attributes: map[string]interface{}{"region": "us-west", "cores": 2}},
+		{ID: "h2", Type: ResourceAtom, Attributes: map[string]interface{}{"region": "eu-central", "cores": 4}},
+		{ID: "u1", Type: EntityAtom, Attributes: map[string]interface{}{"tags": []string{"admin"}}},
+		{ID: "u2", Type: EntityAtom},
+	}))
+
+	ids := func(atoms []*Atom) []string {
+		out := make([]string, 0, len(atoms))
+		for _, a := range atoms {
+			out = append(out, a.ID)
+		}
+		return out
+	}
+
+	t.Run("by attribute", func(t *testing.T) {
+		assert.Equal(t, []string{"h1", "h3"}, ids(s.QueryAtomsByAttribute(ctx, "region", "us-west")))
+		assert.Equal(t, []string{"h2"}, ids(s.QueryAtomsByAttribute(ctx, "cores", 4)))
+		assert.Equal(t, []string{"u1"}, ids(s.QueryAtomsByAttribute(ctx, "tags", []string{"admin"})))
+		assert.Empty(t, s.QueryAtomsByAttribute(ctx, "region", "ap-south"))
+		assert.Empty(t, s.QueryAtomsByAttribute(ctx, "cores", "4"))
+	})
+
+	t.Run("by predicate", func(t *testing.T) {
+		bigHosts := s.QueryAtoms(ctx, func(a *Atom) bool {
+			cores, ok := a.Attributes["cores"].(int)
+			return a.Type == ResourceAtom && ok && cores >= 4
+		})
+		assert.Equal(t, []string{"h2", "h3"}, ids(bigHosts))
+	})
+
+	t.Run("returns copies", func(t *testing.T) {
+		s.QueryAtoms(ctx, func(a *Atom) bool {
+			a.Attributes["region"] = "mutated"
+			return false
+		})
+		found := s.QueryAtomsByAttribute(ctx, "region", "us-west")
+		require.Len(t, found, 2)
+		found[0].Attributes["region"] = "mutated"
+		found[0].Name = "mutated"
+
+		atom, err := s.GetAtom(ctx, "h1")
+		require.NoError(t, err)
+		assert.Equal(t, "us-west", atom.Attributes["region"])
+		assert.Empty(t, atom.Name)
+	})
+}
+
+func TestSpace_GetLink(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.AddAtoms(ctx, []*Atom{{ID: "a"}, {ID: "b"}}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "ab", Type: MembershipLink, Source: "a", Target: "b", Strength: 0.7}))
+
+	link, err := s.GetLink(ctx, "ab")
+	require.NoError(t, err)
+	assert.Equal(t, "a", link.Source)
+	assert.Equal(t, "b", link.Target)
+	assert.Equal(t, 0.7, link.Strength)
+
+	_, err = s.GetLink(ctx, "missing")
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+
+	t.Run("duplicate ID", func(t *testing.T) {
+		err := s.AddLink(ctx, &Link{ID: "ab", Type: InheritanceLink, Source: "b", Target: "a"})
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotUnique), err))
+		assert.Contains(t, err.Error(), "link ab already exists")
+		assert.Empty(t, s.GetOutgoingLinks(ctx, "b"))
+	})
+
+	t.Run("empty ID", func(t *testing.T) {
+		err := s.AddLink(ctx, &Link{Type: InheritanceLink, Source: "b", Target: "a"})
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		assert.Contains(t, err.Error(), "link ID is empty")
+
+		err = s.AddLinks(ctx, []*Link{{Type: InheritanceLink, Source: "b", Target: "a"}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "link 0 has an empty ID")
+	})
+
+	t.Run("removal frees the ID", func(t *testing.T) {
+		require.NoError(t, s.RemoveLink(ctx, "ab"))
+		_, err := s.GetLink(ctx, "ab")
+		require.Error(t, err)
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "ab", Type: InheritanceLink, Source: "b", Target: "a"}))
+		assert.Len(t, s.GetLinksForAtom(ctx, "a"), 1)
+
+		require.NoError(t, s.RemoveAtom(ctx, "a"))
+		_, err = s.GetLink(ctx, "ab")
+		require.Error(t, err)
+	})
+}
+
+func TestSpace_RenameAtom(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "a", Type: ConceptAtom}))
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "b", Type: ConceptAtom}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "a-b", Type: InheritanceLink, Source: "a", Target: "b"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "b-a", Type: InheritanceLink, Source: "b", Target: "a"}))
+	require.NoError(t, s.DefineBoundary(ctx, &DomainBoundary{ID: "bd", Type: ScopeBoundary, AtomIDs: []string{"a", "b"}}))
+
+	require.NoError(t, s.RenameAtom(ctx, "a", "c"))
+
+	_, err = s.GetAtom(ctx, "a")
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+	atom, err := s.GetAtom(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, "c", atom.ID)
+	assert.Len(t, s.GetAtomsByType(ctx, ConceptAtom), 2)
+	assert.Empty(t, s.GetLinksForAtom(ctx, "a"))
+	assert.Len(t, s.GetLinksForAtom(ctx, "c"), 2)
+	out, err := s.GetLink(ctx, "a-b")
+	require.NoError(t, err)
+	assert.Equal(t, "c", out.Source)
+	in, err := s.GetLink(ctx, "b-a")
+	require.NoError(t, err)
+	assert.Equal(t, "c", in.Target)
+	boundaries := s.GetBoundaries(ctx)
+	require.Len(t, boundaries, 1)
+	assert.Equal(t, []string{"c", "b"}, boundaries[0].AtomIDs)
+
+	err = s.RenameAtom(ctx, "c", "b")
+	assert.True(t, errors.Match(errors.T(errors.NotUnique), err))
+	err = s.RenameAtom(ctx, "missing", "d")
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+
+	require.NoError(t, s.RenameLink(ctx, "a-b", "c-b"))
+	_, err = s.GetLink(ctx, "a-b")
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+	_, err = s.GetLink(ctx, "c-b")
+	assert.NoError(t, err)
+	err = s.RenameLink(ctx, "c-b", "b-a")
+	assert.True(t, errors.Match(errors.T(errors.NotUnique), err))
+}
+
+func TestSpace_RenameTensor(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: ConceptAtom}))
+	}
+	require.NoError(t, s.AttachTensor(ctx, "a", &Tensor{ID: "t1", Shape: []int{1}, Data: []float64{1}}))
+	require.NoError(t, s.AttachTensor(ctx, "b", &Tensor{ID: "t1"}))
+	require.NoError(t, s.AttachTensor(ctx, "c", &Tensor{ID: "t2", Shape: []int{1}, Data: []float64{2}}))
+	require.NoError(t, s.MoveTensor(ctx, "t1", CUDADevice))
+
+	require.NoError(t, s.RenameTensor(ctx, "t1", "t3"))
+
+	_, err = s.GetTensorByID(ctx, "t1")
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+	for _, id := range []string{"a", "b"} {
+		tensor, err := s.GetTensor(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "t3", tensor.ID)
+	}
+	assert.Equal(t, 2, s.tensorRefs["t3"])
+	assert.NotContains(t, s.tensorRefs, "t1")
+	history := s.GetTensorMoves(ctx, "t3")
+	require.Len(t, history, 1)
+	assert.Equal(t, "t3", history[0].TensorID)
+
+	err = s.RenameTensor(ctx, "t3", "t2")
+	assert.True(t, errors.Match(errors.T(errors.NotUnique), err))
+	err = s.RenameTensor(ctx, "missing", "t4")
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+	err = s.RenameTensor(ctx, "t3", "")
+	assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+}
+
+func TestSpace_AtomUpdatedAt(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "a", Type: ConceptAtom}))
+
+	added, err := s.GetAtomCopy(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, added.CreatedAt.IsZero())
+	assert.Equal(t, added.CreatedAt, added.UpdatedAt)
+
+	before := time.Now()
+	require.NoError(t, s.UpdateAtom(ctx, "a", map[string]interface{}{"k": 1}))
+	updated, err := s.GetAtomCopy(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, added.CreatedAt, updated.CreatedAt)
+	assert.False(t, updated.UpdatedAt.Before(before))
+
+	before = time.Now()
+	require.NoError(t, s.ReplaceAttributes(ctx, "a", map[string]interface{}{"k": 2}))
+	replaced, err := s.GetAtom(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, added.CreatedAt, replaced.CreatedAt)
+	assert.False(t, replaced.UpdatedAt.Before(before))
+}
+
+func TestSpace_Walk(t *testing.T) {
+	ctx := context.Background()
+
+	// root -> a -> c, root -> b -> c, c -> d, d -> root
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	for _, id := range []string{"root", "a", "b", "c", "d"} {
+		require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: ConceptAtom}))
+	}
+	for _, l := range [][2]string{{"root", "a"}, {"root", "b"}, {"a", "c"}, {"b", "c"}, {"c", "d"}, {"d", "root"}} {
+		require.NoError(t, s.AddLink(ctx, &Link{ID: l[0] + "-" + l[1], Type: InheritanceLink, Source: l[0], Target: l[1]}))
+	}
+
+	t.Run("visits each reachable atom once", func(t *testing.T) {
+		depths := make(map[string]int)
+		require.NoError(t, s.Walk(ctx, "root", func(atom *Atom, depth int) bool {
+			_, seen := depths[atom.ID]
+			assert.False(t, seen, "atom %s visited twice", atom.ID)
+			depths[atom.ID] = depth
+			return true
+		}))
+		assert.Equal(t, map[string]int{"root": 0, "a": 1, "b": 1, "c": 2, "d": 3}, depths)
+	})
+
+	t.Run("pruning stops descent", func(t *testing.T) {
+		var visited []string
+		require.NoError(t, s.Walk(ctx, "root", func(atom *Atom, depth int) bool {
+			visited = append(visited, atom.ID)
+			return atom.ID != "a" && atom.ID != "b"
+		}))
+		assert.ElementsMatch(t, []string{"root", "a", "b"}, visited)
+	})
+
+	t.Run("visit may call back into the space", func(t *testing.T) {
+		count := 0
+		require.NoError(t, s.Walk(ctx, "c", func(atom *Atom, depth int) bool {
+			count++
+			return assert.NoError(t, s.UpdateAtom(ctx, atom.ID, map[string]interface{}{"walked": true}))
+		}))
+		assert.Equal(t, 5, count)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		err := s.Walk(ctx, "missing", func(*Atom, int) bool { return true })
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+		err = s.Walk(ctx, "root", nil)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+}
+
+func TestSpace_TensorDType(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "a", Type: ConceptAtom}))
+
+	t.Run("attach validates dtype", func(t *testing.T) {
+		require.NoError(t, s.AttachTensor(ctx, "a", &Tensor{ID: "t32", Shape: []int{1}, Data: []float64{1}, DType: Int32DType}))
+
+		err := s.AttachTensor(ctx, "a", &Tensor{ID: "bad", Shape: []int{1}, Data: []float64{1}, DType: "complex128"})
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		assert.Contains(t, err.Error(), `unknown dtype "complex128"`)
+		tensor, err := s.GetTensor(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, "t32", tensor.ID)
+	})
+
+	t.Run("float64 to int64 truncates toward zero", func(t *testing.T) {
+		require.NoError(t, s.AttachTensor(ctx, "a", &Tensor{ID: "tf", Shape: []int{4}, Data: []float64{2.7, -2.7, 0.5, 3}, DType: Float64DType}))
+
+		require.NoError(t, s.ConvertTensor(ctx, "tf", Int64DType))
+
+		tensor, err := s.GetTensor(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, Int64DType, tensor.DType)
+		assert.Equal(t, []float64{2, -2, 0, 3}, tensor.Data)
+	})
+
+	t.Run("out of range values leave the tensor unchanged", func(t *testing.T) {
+		require.NoError(t, s.AttachTensor(ctx, "a", &Tensor{ID: "big", Shape: []int{2}, Data: []float64{1.5, 1e10}}))
+
+		err := s.ConvertTensor(ctx, "big", Int32DType)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		tensor, err := s.GetTensor(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, "", tensor.DType)
+		assert.Equal(t, []float64{1.5, 1e10}, tensor.Data)
+
+		err = s.ConvertTensor(ctx, "big", "uint8")
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		err = s.ConvertTensor(ctx, "missing", Int64DType)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+	})
+}
+
+func TestSpace_Stats(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, SpaceStats{AtomsByType: map[AtomType]int{}, LinksByType: map[LinkType]int{}}, s.Stats(ctx))
+
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "org", Type: AggregateAtom}))
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "user-1", Type: EntityAtom}))
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "user-2", Type: EntityAtom}))
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "gone", Type: ConceptAtom}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "m1", Type: MembershipLink, Source: "user-1", Target: "org"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "m2", Type: MembershipLink, Source: "user-2", Target: "org"}))
+	require.NoError(t, s.AddLink(ctx, &Link{ID: "d1", Type: DependencyLink, Source: "user-1", Target: "user-2"}))
+	require.NoError(t, s.AttachTensor(ctx, "org", &Tensor{ID: "t-org", Shape: []int{2, 3}, Data: make([]float64, 6)}))
+	require.NoError(t, s.AttachTensor(ctx, "user-1", &Tensor{ID: "t-user", Shape: []int{4}, Data: make([]float64, 4)}))
+	require.NoError(t, s.DefineBoundary(ctx, &DomainBoundary{ID: "b1", Type: ScopeBoundary, AtomIDs: []string{"org", "user-1"}}))
+	require.NoError(t, s.RemoveAtom(ctx, "gone"))
+
+	assert.Equal(t, SpaceStats{
+		Atoms:       3,
+		AtomsByType: map[AtomType]int{AggregateAtom: 1, EntityAtom: 2},
+		Links:       3,
+		LinksByType: map[LinkType]int{MembershipLink: 2, DependencyLink: 1},
+		Tensors:     2,
+		Boundaries:  1,
+		TensorBytes: 80,
+	}, s.Stats(ctx))
+}
+
+func TestSpace_GetBoundariesConcurrent(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.AddAtom(ctx, &Atom{ID: "a", Type: ConceptAtom}))
+	require.NoError(t, s.DefineBoundary(ctx, &DomainBoundary{ID: "b1", Type: ScopeBoundary, AtomIDs: []string{"a"}, Properties: map[string]interface{}{"k": 1}}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				for _, boundary := range s.GetBoundaries(ctx) {
+					boundary.AtomIDs[0] = "mutated"
+					boundary.Properties["k"] = "mutated"
+				}
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				id := fmt.Sprintf("atom-%d-%d", i, j)
+				assert.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: ConceptAtom}))
+				assert.NoError(t, s.RemoveAtom(ctx, id))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	boundaries := s.GetBoundaries(ctx)
+	require.Len(t, boundaries, 1)
+	assert.Equal(t, []string{"a"}, boundaries[0].AtomIDs)
+	assert.Equal(t, 1, boundaries[0].Properties["k"])
+}
+
+func TestSpace_FindBoundariesForAtom(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := NewSpace(ctx)
+	require.NoError(t, err)
+	for _, id := range []string{"a", "b"} {
+		require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: ConceptAtom}))
+	}
+	require.NoError(t, s.DefineBoundary(ctx, &DomainBoundary{ID: "security", Type: SecurityBoundary, AtomIDs: []string{"a", "b"}}))
+	require.NoError(t, s.DefineBoundary(ctx, &DomainBoundary{ID: "scope", Type: ScopeBoundary, AtomIDs: []string{"a"}}))
+	require.NoError(t, s.DefineBoundary(ctx, &DomainBoundary{ID: "other", Type: LogicalBoundary, AtomIDs: []string{"b"}}))
+
+	boundaryIDs := func(atomID string) []string {
+		ids := make([]string, 0)
+		for _, boundary := range s.FindBoundariesForAtom(ctx, atomID) {
+			ids = append(ids, boundary.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"scope", "security"}, boundaryIDs("a"))
+	assert.Equal(t, []string{"other", "security"}, boundaryIDs("b"))
+
+	// Removing the atom from one boundary leaves it in the other.
+	require.NoError(t, s.UpdateBoundary(ctx, &DomainBoundary{ID: "security", Type: SecurityBoundary, AtomIDs: []string{"b"}}))
+	assert.Equal(t, []string{"scope"}, boundaryIDs("a"))
+	assert.Equal(t, []string{"other", "security"}, boundaryIDs("b"))
+
+	require.NoError(t, s.RemoveBoundary(ctx, "scope"))
+	assert.Empty(t, boundaryIDs("a"))
+
+	require.NoError(t, s.RenameAtom(ctx, "b", "c"))
+	assert.Empty(t, boundaryIDs("b"))
+	assert.Equal(t, []string{"other", "security"}, boundaryIDs("c"))
+
+	require.NoError(t, s.RemoveAtom(ctx, "c"))
+	assert.Empty(t, boundaryIDs("c"))
+	for _, boundary := range s.GetBoundaries(ctx) {
+		assert.Empty(t, boundary.AtomIDs)
+	}
+}
+
+// denyAtoms is an Authorizer that denies the atoms in the map.
+type denyAtoms map[string]bool
+
+func (d denyAtoms) CanRead(_ context.Context, atomID string) bool {
+	return !d[atomID]
+}
+
+func TestSpace_Authorizer(t *testing.T) {
+	ctx := context.Background()
+
+	// a -> b -> c -> d, with b's boundary holding a, b and c
+	setup := func(t *testing.T) *Space {
+		s, err := NewSpace(ctx)
+		require.NoError(t, err)
+		for _, id := range []string{"a", "b", "c", "d"} {
+			require.NoError(t, s.AddAtom(ctx, &Atom{ID: id, Type: EntityAtom, Attributes: map[string]interface{}{"team": "red"}}))
+		}
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "ab", Source: "a", Target: "b", Type: MembershipLink, Strength: 0.5}))
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "bc", Source: "b", Target: "c", Type: MembershipLink, Strength: 0.5}))
+		require.NoError(t, s.AddLink(ctx, &Link{ID: "cd", Source: "c", Target: "d", Type: MembershipLink, Strength: 0.5}))
+		require.NoError(t, s.DefineBoundary(ctx, &DomainBoundary{ID: "scope", Type: TransactionalBoundary, AtomIDs: []string{"a", "b", "c"}}))
+		return s
+	}
+	ids := func(atoms []*Atom) []string {
+		out := make([]string, 0, len(atoms))
+		for _, atom := range atoms {
+			out = append(out, atom.ID)
+		}
+		return out
+	}
+	linkIDs := func(links []*Link) []string {
+		out := make([]string, 0, len(links))
+		for _, link := range links {
+			out = append(out, link.ID)
+		}
+		return out
+	}
+	walked := func(s *Space, startID string) ([]string, error) {
+		var visited []string
+		err := s.Walk(ctx, startID, func(atom *Atom, _ int) bool {
+			visited = append(visited, atom.ID)
+			return true
+		})
+		return visited, err
+	}
+	all := func(*Atom) bool { return true }
+
+	t.Run("allow all by default", func(t *testing.T) {
+		s := setup(t)
+		_, err := s.GetAtom(ctx, "b")
+		require.NoError(t, err)
+		atoms, err := s.QueryByBoundary(ctx, "scope")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, ids(atoms))
+		atoms, err = s.GetNeighbors(ctx, "a", 3, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b", "c", "d"}, ids(atoms))
+	})
+
+	t.Run("denied atoms are filtered out", func(t *testing.T) {
+		s := setup(t)
+		s.SetAuthorizer(ctx, denyAtoms{"c": true})
+
+		_, err := s.GetAtom(ctx, "c")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+		_, err = s.GetAtom(ctx, "b")
+		require.NoError(t, err)
+
+		_, err = s.GetAtomCopy(ctx, "c")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+
+		atoms, err := s.ListAtoms(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "d"}, ids(atoms))
+		assert.Equal(t, []string{"a", "b", "d"}, ids(s.GetAtomsByType(ctx, EntityAtom)))
+		assert.Equal(t, []string{"a", "b", "d"}, ids(s.QueryAtoms(ctx, all)))
+		assert.Equal(t, []string{"a", "b", "d"}, ids(s.QueryAtomsByAttribute(ctx, "team", "red")))
+
+		assert.Equal(t, []string{"ab"}, linkIDs(s.GetLinksForAtom(ctx, "b")))
+		assert.Empty(t, s.GetLinksForAtom(ctx, "c"))
+		assert.Empty(t, s.GetOutgoingLinks(ctx, "b"))
+		assert.Empty(t, s.GetIncomingLinks(ctx, "d"))
+
+		atoms, err = s.QueryByBoundary(ctx, "scope")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, ids(atoms))
+
+		sub, err := s.ExtractSubgraph(ctx, "scope")
+		require.NoError(t, err)
+		atoms, err = sub.ListAtoms(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, ids(atoms))
+		assert.Equal(t, []string{"ab"}, linkIDs(sub.GetLinksByType(ctx, MembershipLink)))
+		assert.Equal(t, []string{"a", "b"}, sub.GetBoundaries(ctx)[0].AtomIDs)
+
+		// d is only reachable through c
+		atoms, err = s.GetNeighbors(ctx, "a", 3, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b"}, ids(atoms))
+		atoms, err = s.TransitiveMembers(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b"}, ids(atoms))
+		visited, err := walked(s, "a")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, visited)
+		_, err = s.FindPath(ctx, "a", "d", 5)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+		_, _, err = s.FindStrongestPath(ctx, "a", "d")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+		path, err := s.FindPath(ctx, "a", "b", 5)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ab"}, linkIDs(path))
+
+		assert.Equal(t, []string{"a"}, ids(s.GetNeighborsAboveStrength(ctx, "b", 0)))
+		assert.Empty(t, s.GetNeighborsAboveStrength(ctx, "c", 0))
+
+		triples, err := s.MatchTriples(ctx, "", MembershipLink, "")
+		require.NoError(t, err)
+		require.Len(t, triples, 1)
+		assert.Equal(t, "ab", triples[0].Link.ID)
+
+		// a denied starting atom is reported as GetAtom reports it
+		_, err = s.GetNeighbors(ctx, "c", 1, nil)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+		_, err = s.TransitiveMembers(ctx, "c")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+		_, err = walked(s, "c")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+		_, err = s.FindPath(ctx, "a", "c", 5)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+		_, _, err = s.FindStrongestPath(ctx, "c", "d")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+	})
+
+	t.Run("denied atoms fail with authorization errors", func(t *testing.T) {
+		s := setup(t)
+		s.SetAuthorizer(ctx, denyAtoms{"c": true}, WithAuthorizationErrors())
+
+		_, err := s.GetAtom(ctx, "c")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Forbidden), err))
+		assert.Contains(t, err.Error(), "atom c is not readable")
+
+		_, err = s.GetAtomCopy(ctx, "c")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Forbidden), err))
+		_, err = s.ListAtoms(ctx)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Forbidden), err))
+
+		_, err = s.QueryByBoundary(ctx, "scope")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Forbidden), err))
+		_, err = s.ExtractSubgraph(ctx, "scope")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Forbidden), err))
+
+		_, err = s.GetNeighbors(ctx, "a", 3, nil)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Forbidden), err))
+		_, err = s.TransitiveMembers(ctx, "a")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Forbidden), err))
+		_, err = walked(s, "a")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Forbidden), err))
+		_, err = s.FindPath(ctx, "a", "d", 5)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Forbidden), err))
+		_, _, err = s.FindStrongestPath(ctx, "a", "d")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Forbidden), err))
+		_, err = s.MatchTriples(ctx, "", MembershipLink, "")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Forbidden), err))
+
+		// queries without an error result still leave denied atoms out
+		assert.Equal(t, []string{"a", "b", "d"}, ids(s.GetAtomsByType(ctx, EntityAtom)))
+		assert.Equal(t, []string{"a", "b", "d"}, ids(s.QueryAtoms(ctx, all)))
+		assert.Equal(t, []string{"a", "b", "d"}, ids(s.QueryAtomsByAttribute(ctx, "team", "red")))
+		assert.Equal(t, []string{"ab"}, linkIDs(s.GetLinksForAtom(ctx, "b")))
+		assert.Equal(t, []string{"a"}, ids(s.GetNeighborsAboveStrength(ctx, "b", 0)))
+
+		atoms, err := s.GetNeighbors(ctx, "a", 1, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b"}, ids(atoms))
+	})
+
+	t.Run("nil authorizer allows all", func(t *testing.T) {
+		s := setup(t)
+		s.SetAuthorizer(ctx, denyAtoms{"c": true})
+		s.SetAuthorizer(ctx, nil)
+		_, err := s.GetAtom(ctx, "c")
+		require.NoError(t, err)
+	})
+}
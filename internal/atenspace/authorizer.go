@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package atenspace
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/boundary/internal/errors"
+)
+
+// Authorizer decides which atoms a caller may read. GetAtom, GetAtomCopy,
+// ListAtoms, QueryAtoms, QueryAtomsByAttribute, GetAtomsByType,
+// GetLinksForAtom, GetOutgoingLinks, GetIncomingLinks, QueryByBoundary,
+// ExtractSubgraph, TransitiveMembers, GetNeighbors, Walk, FindPath,
+// FindStrongestPath, GetNeighborsAboveStrength and MatchTriples consult the
+// Space's authorizer for every atom they return or traverse. Other methods,
+// such as GetLink, GetTensor, Stats and the exports, do not.
+type Authorizer interface {
+	// CanRead reports whether the atom identified by atomID may be read. It
+	// is called with the Space's lock held and must not call back into the
+	// Space.
+	CanRead(ctx context.Context, atomID string) bool
+}
+
+// SetAuthorizer sets the authorizer consulted by the Space's queries. Atoms
+// the authorizer denies are filtered out of query results and reported as
+// not found by GetAtom and GetAtomCopy; with WithAuthorizationErrors a query
+// that returns an error fails with a Forbidden error instead. A nil
+// authorizer allows every atom, which is the default.
+//
+// Supported options: WithAuthorizationErrors
+func (s *Space) SetAuthorizer(ctx context.Context, a Authorizer, opt ...Option) {
+	opts := getOpts(opt...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.authorizer = a
+	s.authorizationErrors = opts.withAuthorizationErrors
+}
+
+// authorize reports whether the atom identified by atomID may be read. A
+// denied atom also yields a Forbidden error when the authorizer was set with
+// WithAuthorizationErrors. The caller must hold s.mu.
+func (s *Space) authorize(ctx context.Context, op errors.Op, atomID string) (bool, error) {
+	if s.readable(ctx, atomID) {
+		return true, nil
+	}
+	if s.authorizationErrors {
+		return false, errors.New(ctx, errors.Forbidden, op, fmt.Sprintf("atom %s is not readable", atomID))
+	}
+	return false, nil
+}
+
+// readable reports whether the atom identified by atomID may be read,
+// ignoring WithAuthorizationErrors. Queries that cannot return an error use
+// it to leave denied atoms out. The caller must hold s.mu.
+func (s *Space) readable(ctx context.Context, atomID string) bool {
+	return s.authorizer == nil || s.authorizer.CanRead(ctx, atomID)
+}
+
+// readableLinks removes the links with a denied Source or Target from links,
+// which must not be one of the Space's own slices, and returns the result.
+// The caller must hold s.mu.
+func (s *Space) readableLinks(ctx context.Context, links []*Link) []*Link {
+	return slices.DeleteFunc(links, func(link *Link) bool {
+		return !s.readable(ctx, link.Source) || !s.readable(ctx, link.Target)
+	})
+}
+
+// lookupAtom returns the atom identified by atomID. A missing atom, or one
+// the authorizer denies, is reported as not found, or as forbidden with
+// WithAuthorizationErrors. The caller must hold s.mu.
+func (s *Space) lookupAtom(ctx context.Context, op errors.Op, atomID string) (*Atom, error) {
+	atom, ok := s.atoms[atomID]
+	if ok {
+		var err error
+		if ok, err = s.authorize(ctx, op, atomID); err != nil {
+			return nil, err
+		}
+	}
+	if !ok {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("atom %s not found", atomID))
+	}
+	return atom, nil
+}
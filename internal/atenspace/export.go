@@ -0,0 +1,305 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package atenspace
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/boundary/internal/errors"
+)
+
+// snapshot is the JSON representation of a Space written by ExportJSON and
+// read by ImportJSON.
+type snapshot struct {
+	Atoms      []*Atom           `json:"atoms"`
+	Links      []*Link           `json:"links"`
+	Tensors    []*tensorSnapshot `json:"tensors"`
+	Boundaries []*DomainBoundary `json:"boundaries"`
+}
+
+// tensorSnapshot is a tensor together with the atoms it is attached to.
+type tensorSnapshot struct {
+	*Tensor
+	AtomIDs []string
+}
+
+// snapshot captures the space's atoms ordered by ID, links in insertion
+// order, tensors ordered by ID and boundaries in definition order. The
+// caller must hold s.mu.
+func (s *Space) snapshot() *snapshot {
+	snap := &snapshot{
+		Atoms:      make([]*Atom, 0, len(s.atoms)),
+		Links:      slices.Clone(s.links),
+		Tensors:    make([]*tensorSnapshot, 0, len(s.tensorStore)),
+		Boundaries: slices.Clone(s.boundaries),
+	}
+
+	attached := make(map[string][]string, len(s.tensorStore))
+	for _, atom := range s.atoms {
+		snap.Atoms = append(snap.Atoms, atom)
+		if atom.TensorID != "" {
+			attached[atom.TensorID] = append(attached[atom.TensorID], atom.ID)
+		}
+	}
+	slices.SortFunc(snap.Atoms, func(a, b *Atom) int { return strings.Compare(a.ID, b.ID) })
+
+	for _, tensor := range s.tensorStore {
+		atomIDs := attached[tensor.ID]
+		slices.Sort(atomIDs)
+		snap.Tensors = append(snap.Tensors, &tensorSnapshot{Tensor: tensor, AtomIDs: atomIDs})
+	}
+	slices.SortFunc(snap.Tensors, func(a, b *tensorSnapshot) int { return strings.Compare(a.ID, b.ID) })
+
+	return snap
+}
+
+// ExportJSON writes every atom, link, tensor and boundary in the space to w
+// as JSON. The output can be read back with ImportJSON.
+func (s *Space) ExportJSON(ctx context.Context, w io.Writer) error {
+	const op = "atenspace.(Space).ExportJSON"
+
+	if w == nil {
+		return errors.New(ctx, errors.InvalidParameter, op, "writer is nil")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.snapshot()); err != nil {
+		return errors.Wrap(ctx, err, op, errors.WithMsg("encoding space"))
+	}
+	return nil
+}
+
+// ImportJSON builds a new Space from JSON written by ExportJSON. Atoms and
+// links keep their CreatedAt timestamps, atoms their UpdatedAt, and every
+// index is rebuilt. It errors if a link, tensor or boundary references an
+// atom that is not in the snapshot, or an atom references a tensor that is
+// not.
+func ImportJSON(ctx context.Context, r io.Reader) (*Space, error) {
+	const op = "atenspace.ImportJSON"
+
+	if r == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "reader is nil")
+	}
+
+	var snap snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, errors.Wrap(ctx, err, op, errors.WithMsg("decoding space"))
+	}
+
+	s, err := NewSpace(ctx)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, op)
+	}
+
+	for _, atom := range snap.Atoms {
+		switch {
+		case atom == nil:
+			return nil, errors.New(ctx, errors.InvalidParameter, op, "atom is nil")
+		case atom.ID == "":
+			return nil, errors.New(ctx, errors.InvalidParameter, op, "atom ID is empty")
+		}
+		if _, ok := s.atoms[atom.ID]; ok {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("duplicate atom %s", atom.ID))
+		}
+		s.insertAtom(atom)
+	}
+
+	for _, link := range snap.Links {
+		switch {
+		case link == nil:
+			return nil, errors.New(ctx, errors.InvalidParameter, op, "link is nil")
+		case link.ID == "":
+			return nil, errors.New(ctx, errors.InvalidParameter, op, "link ID is empty")
+		}
+		if _, ok := s.linksByID[link.ID]; ok {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("duplicate link %s", link.ID))
+		}
+		for _, atomID := range []string{link.Source, link.Target} {
+			if _, ok := s.atoms[atomID]; !ok {
+				return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("link %s references missing atom %s", link.ID, atomID))
+			}
+		}
+		s.insertLink(link)
+	}
+
+	for _, tensor := range snap.Tensors {
+		if tensor == nil || tensor.Tensor == nil {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, "tensor is nil")
+		}
+		for _, atomID := range tensor.AtomIDs {
+			atom, ok := s.atoms[atomID]
+			if !ok {
+				return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("tensor %s references missing atom %s", tensor.ID, atomID))
+			}
+			if atom.TensorID != tensor.ID {
+				return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("tensor %s is not attached to atom %s", tensor.ID, atomID))
+			}
+		}
+		s.tensorStore[tensor.ID] = tensor.Tensor
+	}
+	for _, atom := range snap.Atoms {
+		if _, ok := s.tensorStore[atom.TensorID]; atom.TensorID != "" && !ok {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("atom %s references missing tensor %s", atom.ID, atom.TensorID))
+		}
+	}
+
+	for _, boundary := range snap.Boundaries {
+		if boundary == nil {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, "boundary is nil")
+		}
+		for _, atomID := range boundary.AtomIDs {
+			if _, ok := s.atoms[atomID]; !ok {
+				return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("boundary %s references missing atom %s", boundary.ID, atomID))
+			}
+		}
+		if err := s.DefineBoundary(ctx, boundary); err != nil {
+			return nil, errors.Wrap(ctx, err, op)
+		}
+	}
+
+	return s, nil
+}
+
+// graphML is the root element of a GraphML document.
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+// graphMLKey declares a data attribute of nodes or edges.
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID       string        `xml:"id,attr"`
+	Source   string        `xml:"source,attr"`
+	Target   string        `xml:"target,attr"`
+	Directed string        `xml:"directed,attr,omitempty"`
+	Data     []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphMLKeys are the node and edge attributes written by ExportGraphML.
+var graphMLKeys = []graphMLKey{
+	{ID: "type", For: "node", AttrName: "type", AttrType: "string"},
+	{ID: "name", For: "node", AttrName: "name", AttrType: "string"},
+	{ID: "tensor_shape", For: "node", AttrName: "tensor_shape", AttrType: "string"},
+	{ID: "tensor_dtype", For: "node", AttrName: "tensor_dtype", AttrType: "string"},
+	{ID: "boundaries", For: "node", AttrName: "boundaries", AttrType: "string"},
+	{ID: "link_type", For: "edge", AttrName: "link_type", AttrType: "string"},
+	{ID: "strength", For: "edge", AttrName: "strength", AttrType: "double"},
+}
+
+// ExportGraphML writes the space to w as a directed GraphML graph for
+// visualization in tools such as Gephi or yEd. Atoms become nodes carrying
+// their type, name, tensor shape and dtype and the boundaries they belong
+// to; links become edges carrying their type and strength, with
+// bidirectional links marked as undirected. Tensor data is not included.
+func (s *Space) ExportGraphML(ctx context.Context, w io.Writer) error {
+	const op = "atenspace.(Space).ExportGraphML"
+
+	if w == nil {
+		return errors.New(ctx, errors.InvalidParameter, op, "writer is nil")
+	}
+
+	s.mu.RLock()
+	snap := s.snapshot()
+	tensors := make(map[string]*Tensor, len(snap.Tensors))
+	for _, tensor := range snap.Tensors {
+		tensors[tensor.ID] = tensor.Tensor
+	}
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphMLKeys,
+		Graph: graphMLGraph{
+			ID:          "atenspace",
+			EdgeDefault: "directed",
+			Nodes:       make([]graphMLNode, 0, len(snap.Atoms)),
+			Edges:       make([]graphMLEdge, 0, len(snap.Links)),
+		},
+	}
+	for _, atom := range snap.Atoms {
+		node := graphMLNode{
+			ID: atom.ID,
+			Data: []graphMLData{
+				{Key: "type", Value: string(atom.Type)},
+				{Key: "name", Value: atom.Name},
+			},
+		}
+		if tensor, ok := tensors[atom.TensorID]; ok {
+			node.Data = append(node.Data,
+				graphMLData{Key: "tensor_shape", Value: fmt.Sprint(tensor.Shape)},
+				graphMLData{Key: "tensor_dtype", Value: tensor.DType},
+			)
+		}
+		var boundaryIDs []string
+		for _, boundary := range snap.Boundaries {
+			if slices.Contains(boundary.AtomIDs, atom.ID) {
+				boundaryIDs = append(boundaryIDs, boundary.ID)
+			}
+		}
+		if len(boundaryIDs) > 0 {
+			node.Data = append(node.Data, graphMLData{Key: "boundaries", Value: strings.Join(boundaryIDs, ",")})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for _, link := range snap.Links {
+		edge := graphMLEdge{
+			ID:     link.ID,
+			Source: link.Source,
+			Target: link.Target,
+			Data: []graphMLData{
+				{Key: "link_type", Value: string(link.Type)},
+				{Key: "strength", Value: fmt.Sprint(link.Strength)},
+			},
+		}
+		if link.Bidirectional {
+			edge.Directed = "false"
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+	s.mu.RUnlock()
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.Wrap(ctx, err, op, errors.WithMsg("writing graphml header"))
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return errors.Wrap(ctx, err, op, errors.WithMsg("encoding space"))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package atenspace
+
+// getOpts - iterate the inbound Options and return a struct
+func getOpts(opt ...Option) options {
+	opts := getDefaultOptions()
+	for _, o := range opt {
+		o(&opts)
+	}
+	return opts
+}
+
+// Option - how Options are passed as arguments
+type Option func(*options)
+
+// options = how options are represented
+type options struct {
+	withAllOrNothing     bool
+	withStrengthFloor    float64
+	withStrengthFloorSet bool
+
+	withAuthorizationErrors bool
+}
+
+func getDefaultOptions() options {
+	return options{}
+}
+
+// WithAllOrNothing makes a batch operation add nothing when any item in the
+// batch is invalid. Without this option the valid items are still added.
+func WithAllOrNothing() Option {
+	return func(o *options) {
+		o.withAllOrNothing = true
+	}
+}
+
+// WithStrengthFloor makes DecayLinks remove links whose strength falls
+// below floor. Without this option no link is removed.
+func WithStrengthFloor(floor float64) Option {
+	return func(o *options) {
+		o.withStrengthFloor = floor
+		o.withStrengthFloorSet = true
+	}
+}
+
+// WithAuthorizationErrors makes the Space's queries fail with a Forbidden
+// error when the authorizer set by SetAuthorizer denies an atom. Without
+// this option denied atoms are filtered out.
+func WithAuthorizationErrors() Option {
+	return func(o *options) {
+		o.withAuthorizationErrors = true
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package hypermind
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/errors"
+)
+
+// Snapshot returns a copy of the state the architecture holds for a scope.
+// It is a StateHandler, so registering it with a LoopbackTransport lets other
+// architectures sync the scope from this one.
+func (m *MultiScopeArchitecture) Snapshot(ctx context.Context, scopeID string) (ScopeSnapshot, error) {
+	const op = "hypermind.(MultiScopeArchitecture).Snapshot"
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	scope, ok := m.scopes[scopeID]
+	if !ok {
+		return ScopeSnapshot{}, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
+	}
+	return ScopeSnapshot{
+		ScopeID:   scopeID,
+		State:     deepCopyState(scope.State),
+		UpdatedAt: scope.UpdatedAt,
+		Version:   scope.Version,
+	}, nil
+}
+
+// SyncScope fetches the full state the peer identified by peerID holds for
+// scopeID and merges it into the local copy of the scope. Keys missing
+// locally are added. A key held by both with different values takes the
+// peer's value only if the peer's state is newer: it has the higher Version
+// or, with equal versions, the later UpdatedAt. Keys the peer lacks are
+// kept, and the merged keys are not gossiped any further. The local Version
+// is advanced past the peer's, so the merged state orders after both. The
+// configured transport must implement StateTransport.
+func (m *MultiScopeArchitecture) SyncScope(ctx context.Context, peerID, scopeID string) error {
+	const op = "hypermind.(MultiScopeArchitecture).SyncScope"
+
+	if peerID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "peer ID is empty")
+	}
+	st, ok := m.transport.(StateTransport)
+	if !ok {
+		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("transport %T cannot fetch state", m.transport))
+	}
+
+	m.mu.RLock()
+	_, ok = m.scopes[scopeID]
+	m.mu.RUnlock()
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
+	}
+
+	// fetch without holding m.mu, the peer may be reading from this
+	// architecture at the same time
+	remote, err := st.FetchState(ctx, peerID, scopeID)
+	if err != nil {
+		return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("syncing scope %s from peer %s", scopeID, peerID)))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	scope, ok := m.scopes[scopeID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
+	}
+	newer := remote.Version > scope.Version ||
+		(remote.Version == scope.Version && remote.UpdatedAt.After(scope.UpdatedAt))
+	changed := make(map[string]interface{})
+	for k, v := range remote.State {
+		local, ok := scope.State[k]
+		if !ok || (newer && !reflect.DeepEqual(local, v)) {
+			changed[k] = v
+		}
+	}
+	scope.Version = max(scope.Version, remote.Version)
+	if len(changed) > 0 {
+		m.applyState(scope, changed, nil, peerID)
+	}
+	return nil
+}
+
+// antiEntropyLoop is a running anti-entropy loop.
+type antiEntropyLoop struct {
+	done   chan struct{}
+	exited chan struct{}
+	once   sync.Once
+}
+
+// stop signals the loop to exit and waits until it has.
+func (l *antiEntropyLoop) stop() {
+	l.once.Do(func() { close(l.done) })
+	<-l.exited
+}
+
+// StartAntiEntropy starts a loop that, every interval, syncs each scope
+// from one of its peers chosen at random, so scopes that missed gossip while
+// offline catch up. Sync failures are retried on the next round. The loop
+// runs until ctx is done, the returned stop function is called or the
+// architecture is closed; stop waits for a round in progress to finish. It
+// fails with Closed after Close.
+func (m *MultiScopeArchitecture) StartAntiEntropy(ctx context.Context, interval time.Duration) (func(), error) {
+	const op = "hypermind.(MultiScopeArchitecture).StartAntiEntropy"
+
+	if interval <= 0 {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "interval must be positive")
+	}
+
+	loop := &antiEntropyLoop{done: make(chan struct{}), exited: make(chan struct{})}
+
+	m.subsMu.Lock()
+	if m.subsClosed {
+		m.subsMu.Unlock()
+		return nil, errors.New(ctx, errors.Closed, op, "architecture is closed")
+	}
+	m.antiEntropyLoops[loop] = struct{}{}
+	m.subsMu.Unlock()
+
+	go func() {
+		defer close(loop.exited)
+		defer func() {
+			m.subsMu.Lock()
+			delete(m.antiEntropyLoops, loop)
+			m.subsMu.Unlock()
+		}()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-loop.done:
+				return
+			case <-ticker.C:
+				_ = m.antiEntropyRound(ctx)
+			}
+		}
+	}()
+
+	return loop.stop, nil
+}
+
+// antiEntropyRound syncs every scope that has peers from one of them, chosen
+// at random. Every scope is attempted and all failures are returned
+// together.
+func (m *MultiScopeArchitecture) antiEntropyRound(ctx context.Context) error {
+	const op = "hypermind.(MultiScopeArchitecture).antiEntropyRound"
+
+	m.mu.RLock()
+	scopeIDs := make([]string, 0, len(m.scopes))
+	for id := range m.scopes {
+		scopeIDs = append(scopeIDs, id)
+	}
+	m.mu.RUnlock()
+	sort.Strings(scopeIDs)
+
+	var errs []error
+	for _, scopeID := range scopeIDs {
+		peerIDs := m.peerNetwork.dht.lookup(scopeID)
+		if len(peerIDs) == 0 {
+			continue
+		}
+		peerID := peerIDs[rand.Intn(len(peerIDs))]
+		if err := m.SyncScope(ctx, peerID, scopeID); err != nil {
+			errs = append(errs, errors.Wrap(ctx, err, op))
+		}
+	}
+	return stderrors.Join(errs...)
+}
@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package hypermind
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/errors"
+)
+
+// maxScopeEvents bounds the number of events retained per scope. Once the
+// bound is reached the oldest events are discarded.
+const maxScopeEvents = 1024
+
+// ScopeEventKind identifies the kind of a ScopeEvent.
+type ScopeEventKind string
+
+const (
+	// StateChangedEvent is recorded when state is propagated to a scope
+	StateChangedEvent ScopeEventKind = "state_changed"
+
+	// PeerConnectedEvent is recorded when a peer joins a scope
+	PeerConnectedEvent ScopeEventKind = "peer_connected"
+
+	// PeerDisconnectedEvent is recorded when a peer leaves a scope
+	PeerDisconnectedEvent ScopeEventKind = "peer_disconnected"
+
+	// ReplicationWarningEvent is recorded when a scope falls below the
+	// configured replication factor
+	ReplicationWarningEvent ScopeEventKind = "replication_warning"
+
+	// MissingParentEvent is recorded when a scope is registered with a
+	// ParentID that does not refer to a registered scope
+	MissingParentEvent ScopeEventKind = "missing_parent"
+)
+
+// ScopeEvent is a single entry in a scope's activity feed. Kind determines
+// which of the optional fields are populated.
+type ScopeEvent struct {
+	// Kind is the event kind
+	Kind ScopeEventKind
+
+	// ScopeID is the scope the event was recorded against
+	ScopeID string
+
+	// Timestamp is when the event occurred
+	Timestamp time.Time
+
+	// PeerID is set for peer connected/disconnected events
+	PeerID string
+
+	// ParentID is the unregistered parent for missing parent events
+	ParentID string
+
+	// State is a copy of the propagated state for state changed events
+	State map[string]interface{}
+
+	// RemovedKeys lists the keys deleted by a delta for state changed events
+	RemovedKeys []string
+
+	// PeerCount is the scope's remaining peer count for replication warnings
+	PeerCount int
+
+	// ReplicationFactor is the configured factor for replication warnings
+	ReplicationFactor int
+}
+
+// recordEvent timestamps and appends an event to the scope's activity feed,
+// evicting the oldest entry once maxScopeEvents is reached.
+func (m *MultiScopeArchitecture) recordEvent(e ScopeEvent) {
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+
+	e.Timestamp = m.now()
+	events := append(m.events[e.ScopeID], e)
+	if len(events) > maxScopeEvents {
+		events = events[len(events)-maxScopeEvents:]
+	}
+	m.events[e.ScopeID] = events
+}
+
+// ScopeFeed returns the chronological activity feed for a scope, merging
+// state changes, peer connects/disconnects and replication warnings recorded
+// at or after since. A zero since returns the full retained feed.
+func (m *MultiScopeArchitecture) ScopeFeed(ctx context.Context, scopeID string, since time.Time) ([]ScopeEvent, error) {
+	const op = "hypermind.(MultiScopeArchitecture).ScopeFeed"
+
+	m.mu.RLock()
+	_, ok := m.scopes[scopeID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
+	}
+
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+
+	feed := make([]ScopeEvent, 0, len(m.events[scopeID]))
+	for _, e := range m.events[scopeID] {
+		if e.Timestamp.Before(since) {
+			continue
+		}
+		feed = append(feed, e)
+	}
+
+	return feed, nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package hypermind
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/errors"
+)
+
+// StateEvent is a single entry in a scope's state history.
+type StateEvent struct {
+	// Timestamp is when the state change was applied
+	Timestamp time.Time
+
+	// OriginPeer is the peer the change came from, or empty for local changes
+	OriginPeer string
+
+	// ChangedKeys are the keys set or removed by the change, sorted
+	ChangedKeys []string
+}
+
+// stateRing is a fixed-size FIFO buffer of state events.
+type stateRing struct {
+	events []StateEvent
+	next   int
+	full   bool
+}
+
+func newStateRing(size int) *stateRing {
+	return &stateRing{events: make([]StateEvent, max(size, 0))}
+}
+
+// push appends e, overwriting the oldest event once the buffer is full.
+func (r *stateRing) push(e StateEvent) {
+	if len(r.events) == 0 {
+		return
+	}
+	r.events[r.next] = e
+	r.next = (r.next + 1) % len(r.events)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// ordered returns the buffered events, oldest first.
+func (r *stateRing) ordered() []StateEvent {
+	if !r.full {
+		return append([]StateEvent(nil), r.events[:r.next]...)
+	}
+	return append(append([]StateEvent(nil), r.events[r.next:]...), r.events[:r.next]...)
+}
+
+// GetStateHistory returns up to limit of the most recent state changes of a
+// scope, oldest first. A limit <= 0 returns the whole retained history. At
+// most the configured state history size is retained per scope.
+func (m *MultiScopeArchitecture) GetStateHistory(ctx context.Context, scopeID string, limit int) ([]StateEvent, error) {
+	const op = "hypermind.(MultiScopeArchitecture).GetStateHistory"
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.scopes[scopeID]; !ok {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
+	}
+
+	ring, ok := m.history[scopeID]
+	if !ok {
+		return []StateEvent{}, nil
+	}
+	events := ring.ordered()
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	return events, nil
+}
@@ -10,7 +10,12 @@ package hypermind
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
@@ -28,6 +33,65 @@ type MultiScopeArchitecture struct {
 
 	// mu protects concurrent access to scopes
 	mu sync.RWMutex
+
+	// replicationFactor is the minimum expected peer count per scope
+	replicationFactor int
+
+	// events holds the per-scope activity feed
+	events map[string][]ScopeEvent
+
+	// eventsMu protects concurrent access to events
+	eventsMu sync.Mutex
+
+	// now returns the current time
+	now func() time.Time
+
+	// transport delivers gossiped state to peers
+	transport Transport
+
+	// ownsTransport is set when transport is the default loopback created
+	// by NewMultiScopeArchitecture, which Close then closes
+	ownsTransport bool
+
+	// gossipFanout bounds the number of peers each update is sent to
+	gossipFanout int
+
+	// subscribers holds the state-change subscribers of each scope
+	subscribers map[string][]*subscription
+
+	// subsClosed is set by Close; no new subscriptions or anti-entropy
+	// loops are accepted after it
+	subsClosed bool
+
+	// antiEntropyLoops holds the running anti-entropy loops, which Close
+	// stops
+	antiEntropyLoops map[*antiEntropyLoop]struct{}
+
+	// subsMu protects concurrent access to subscribers, subsClosed and
+	// antiEntropyLoops
+	subsMu sync.Mutex
+
+	// store persists scopes and peers; nil when persistence is disabled
+	store Store
+
+	// suspectAfter and deadAfter are the LastSeen ages at which a peer
+	// becomes Suspect and Dead
+	suspectAfter time.Duration
+	deadAfter    time.Duration
+
+	// history holds the bounded state history of each scope, protected by mu
+	history map[string]*stateRing
+
+	// historySize is the number of state events retained per scope
+	historySize int
+
+	// maxPeersPerScope is the peer limit of scopes without an override in
+	// peerLimits; zero means unlimited
+	maxPeersPerScope int
+
+	// peerLimits holds the per-scope peer limit overrides, protected by
+	// peerNetwork.mu
+	peerLimits map[string]int
 }
 
 // DistributedScope represents a scope in the hypermind distributed architecture.
@@ -52,6 +116,12 @@ type DistributedScope struct {
 
 	// UpdatedAt timestamp
 	UpdatedAt time.Time
+
+	// Version is a logical clock over the scope's state: it is incremented
+	// by every state change and, on SyncScope, advanced past the version of
+	// the state merged in. It orders state across architectures whose wall
+	// clocks may disagree.
+	Version uint64
 }
 
 // PeerNetwork manages the P2P network connections using hypermind's
@@ -80,30 +150,132 @@ type Peer struct {
 
 	// ScopeIDs are the scopes this peer participates in
 	ScopeIDs []string
+
+	// Health is derived from LastSeen when the peer is returned by
+	// GetActivePeers or GetPeersByHealth
+	Health PeerHealth `json:"-"`
 }
 
-// DistributedHashTable implements a simplified DHT for peer discovery.
+// PeerHealth classifies a peer by how recently it was seen.
+type PeerHealth string
+
+const (
+	// Healthy peers were seen within the suspect threshold
+	Healthy PeerHealth = "healthy"
+
+	// Suspect peers were last seen between the suspect and dead thresholds
+	Suspect PeerHealth = "suspect"
+
+	// Dead peers were last seen longer ago than the dead threshold
+	Dead PeerHealth = "dead"
+)
+
+// DistributedHashTable implements a DHT for peer discovery. Each key holds
+// the peers registered under it, and every connected or registered peer is
+// placed on a consistent-hash ring through a number of virtual nodes.
+// Lookups return a key's peers in ring order starting from the key's hash,
+// so the peers closest to a key stay the same as other peers join and
+// leave. A key is owned by the peer of the first virtual node at or after
+// its hash, whether or not that peer is registered under the key.
 type DistributedHashTable struct {
 	// Entries maps keys to peer lists
 	entries map[string][]string
 
+	// ring holds the virtual nodes of every registered peer, sorted by hash
+	ring []ringNode
+
+	// refs counts the keys each peer on the ring is registered under
+	refs map[string]int
+
+	// joined holds the peers placed on the ring by join, which stay on it
+	// until removePeer even when registered under no key
+	joined map[string]bool
+
+	// owners records the peer each key was assigned to by the last
+	// rebalance
+	owners map[string]string
+
+	// virtualNodes is the number of ring positions per peer; zero means
+	// DefaultVirtualNodes
+	virtualNodes int
+
 	mu sync.RWMutex
 }
 
+// ringNode is a single virtual node on the consistent-hash ring.
+type ringNode struct {
+	hash   uint64
+	peerID string
+}
+
 // NewMultiScopeArchitecture creates a new hypermind multi-scope architecture.
-func NewMultiScopeArchitecture(ctx context.Context) (*MultiScopeArchitecture, error) {
+// When a Store is given, the scopes and peers it holds are loaded into the
+// new architecture. Removals are not persisted.
+// Supported options: WithReplicationFactor, WithClock, WithTransport,
+// WithGossipFanout, WithVirtualNodes, WithStore, WithHealthThresholds,
+// WithStateHistorySize, WithMaxPeersPerScope
+func NewMultiScopeArchitecture(ctx context.Context, opt ...Option) (*MultiScopeArchitecture, error) {
 	const op = "hypermind.NewMultiScopeArchitecture"
 
+	opts := getOpts(opt...)
+	ownsTransport := opts.withTransport == nil
+	if ownsTransport {
+		opts.withTransport = NewLoopbackTransport()
+	}
+
 	msa := &MultiScopeArchitecture{
-		scopes: make(map[string]*DistributedScope),
+		scopes:            make(map[string]*DistributedScope),
+		replicationFactor: opts.withReplicationFactor,
+		events:            make(map[string][]ScopeEvent),
+		now:               opts.withClock,
+		transport:         opts.withTransport,
+		ownsTransport:     ownsTransport,
+		gossipFanout:      opts.withGossipFanout,
+		subscribers:       make(map[string][]*subscription),
+		antiEntropyLoops:  make(map[*antiEntropyLoop]struct{}),
+		store:             opts.withStore,
+		suspectAfter:      opts.withSuspectAfter,
+		deadAfter:         opts.withDeadAfter,
+		history:           make(map[string]*stateRing),
+		historySize:       opts.withStateHistorySize,
+		maxPeersPerScope:  max(opts.withMaxPeersPerScope, 0),
+		peerLimits:        make(map[string]int),
 		peerNetwork: &PeerNetwork{
 			activePeers: make(map[string]*Peer),
 			dht: &DistributedHashTable{
-				entries: make(map[string][]string),
+				entries:      make(map[string][]string),
+				refs:         make(map[string]int),
+				joined:       make(map[string]bool),
+				owners:       make(map[string]string),
+				virtualNodes: opts.withVirtualNodes,
 			},
 		},
 	}
 
+	if msa.store != nil {
+		scopes, err := msa.store.LoadScopes(ctx)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, op, errors.WithMsg("failed to load scopes"))
+		}
+		for _, scope := range scopes {
+			if scope.State == nil {
+				scope.State = make(map[string]interface{})
+			}
+			msa.scopes[scope.ID] = scope
+		}
+		peers, err := msa.store.LoadPeers(ctx)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, op, errors.WithMsg("failed to load peers"))
+		}
+		for _, peer := range peers {
+			msa.peerNetwork.activePeers[peer.ID] = peer
+			msa.peerNetwork.dht.join(peer.ID)
+			for _, scopeID := range peer.ScopeIDs {
+				msa.peerNetwork.dht.add(scopeID, peer.ID)
+			}
+		}
+	}
+
 	return msa, nil
 }
 
@@ -121,12 +293,38 @@ func (m *MultiScopeArchitecture) RegisterScope(ctx context.Context, scope *Distr
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	scope.CreatedAt = time.Now()
-	scope.UpdatedAt = time.Now()
+	// Walk the existing parent chain; reaching the new scope means the
+	// registration would close a loop.
+	for parentID, hops := scope.ParentID, 0; parentID != ""; hops++ {
+		if parentID == scope.ID {
+			return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("parent %s of scope %s would create a cycle", scope.ParentID, scope.ID))
+		}
+		parent, ok := m.scopes[parentID]
+		if !ok {
+			if hops == 0 {
+				m.recordEvent(ScopeEvent{Kind: MissingParentEvent, ScopeID: scope.ID, ParentID: parentID})
+			}
+			break
+		}
+		if hops > len(m.scopes) {
+			// the existing hierarchy already contains a cycle
+			break
+		}
+		parentID = parent.ParentID
+	}
+
+	scope.CreatedAt = m.now()
+	scope.UpdatedAt = scope.CreatedAt
 	if scope.State == nil {
 		scope.State = make(map[string]interface{})
 	}
 
+	if m.store != nil {
+		if err := m.store.SaveScope(ctx, scope); err != nil {
+			return errors.Wrap(ctx, err, op, errors.WithMsg("failed to persist scope"))
+		}
+	}
+
 	m.scopes[scope.ID] = scope
 	return nil
 }
@@ -140,43 +338,620 @@ func (m *MultiScopeArchitecture) GetScope(ctx context.Context, scopeID string) (
 
 	scope, ok := m.scopes[scopeID]
 	if !ok {
-		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("scope %s not found", scopeID))
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
 	}
 
 	return scope, nil
 }
 
+// GetScopeState returns a deep copy of the scope's State taken under the
+// read lock. Nested maps and slices are copied too, so the result can be
+// read and modified while the scope keeps changing. Use it instead of
+// reading GetScope(...).State, which is the live map.
+func (m *MultiScopeArchitecture) GetScopeState(ctx context.Context, scopeID string) (map[string]interface{}, error) {
+	const op = "hypermind.(MultiScopeArchitecture).GetScopeState"
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	scope, ok := m.scopes[scopeID]
+	if !ok {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
+	}
+
+	return deepCopyState(scope.State), nil
+}
+
+// deepCopyState copies state, recursing into nested maps and slices.
+func deepCopyState(state map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(state))
+	for k, v := range state {
+		c[k] = deepCopyValue(v)
+	}
+	return c
+}
+
+// deepCopyValue copies v if it is a map or slice of the kinds state values
+// are built from and returns any other value as is.
+func deepCopyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return deepCopyState(v)
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, e := range v {
+			c[i] = deepCopyValue(e)
+		}
+		return c
+	case []string:
+		return slices.Clone(v)
+	case []float64:
+		return slices.Clone(v)
+	case []int:
+		return slices.Clone(v)
+	default:
+		return v
+	}
+}
+
+// ListScopes returns every registered scope sorted by ID.
+func (m *MultiScopeArchitecture) ListScopes(ctx context.Context) []*DistributedScope {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	scopes := make([]*DistributedScope, 0, len(m.scopes))
+	for _, scope := range m.scopes {
+		scopes = append(scopes, scope)
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i].ID < scopes[j].ID })
+	return scopes
+}
+
+// GetScopesByType returns every registered scope whose Type is scopeType,
+// sorted by ID.
+func (m *MultiScopeArchitecture) GetScopesByType(ctx context.Context, scopeType string) []*DistributedScope {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var scopes []*DistributedScope
+	for _, scope := range m.scopes {
+		if scope.Type == scopeType {
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i].ID < scopes[j].ID })
+	return scopes
+}
+
+// RemoveScope removes a scope from the architecture. When cascade is true all
+// of its descendants are removed as well; otherwise a scope that still has
+// children cannot be removed. Removed scopes are dropped from every peer's
+// ScopeIDs and from the DHT. When a Store is set the removed scopes are
+// deleted from it and the peers that referenced them are saved again.
+func (m *MultiScopeArchitecture) RemoveScope(ctx context.Context, scopeID string, cascade bool) error {
+	const op = "hypermind.(MultiScopeArchitecture).RemoveScope"
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.scopes[scopeID]; !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
+	}
+
+	removed := map[string]bool{scopeID: true}
+	queue := []string{scopeID}
+	for len(queue) > 0 {
+		parentID := queue[0]
+		queue = queue[1:]
+		for id, scope := range m.scopes {
+			if scope.ParentID != parentID || removed[id] {
+				continue
+			}
+			if !cascade {
+				return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("scope %s has child scopes", scopeID))
+			}
+			removed[id] = true
+			queue = append(queue, id)
+		}
+	}
+
+	m.peerNetwork.mu.Lock()
+	defer m.peerNetwork.mu.Unlock()
+
+	remaining := make(map[*Peer][]string)
+	for _, peer := range m.peerNetwork.activePeers {
+		scopeIDs := make([]string, 0, len(peer.ScopeIDs))
+		for _, id := range peer.ScopeIDs {
+			if !removed[id] {
+				scopeIDs = append(scopeIDs, id)
+			}
+		}
+		if len(scopeIDs) != len(peer.ScopeIDs) {
+			remaining[peer] = scopeIDs
+		}
+	}
+
+	if m.store != nil {
+		for id := range removed {
+			if err := m.store.DeleteScope(ctx, id); err != nil {
+				return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("failed to delete scope %s from store", id)))
+			}
+		}
+		for peer, scopeIDs := range remaining {
+			stored := copyPeer(peer)
+			stored.ScopeIDs = scopeIDs
+			if err := m.store.SavePeer(ctx, stored); err != nil {
+				return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("failed to persist peer %s", peer.ID)))
+			}
+		}
+	}
+
+	for peer, scopeIDs := range remaining {
+		peer.ScopeIDs = scopeIDs
+	}
+
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+
+	for id := range removed {
+		delete(m.scopes, id)
+		delete(m.events, id)
+		delete(m.history, id)
+		delete(m.peerLimits, id)
+		m.peerNetwork.dht.removeKey(id)
+		m.closeSubscribers(id)
+	}
+
+	return nil
+}
+
+// RenameScope changes the ID of the scope oldID to newID. Child scopes are
+// re-parented to the new ID and the scope's peers, DHT entry, activity
+// feed, state history and subscribers follow it. When a Store is set the
+// renamed scope, its children and its peers are saved under the new ID and
+// the entry under the old ID is deleted.
+func (m *MultiScopeArchitecture) RenameScope(ctx context.Context, oldID, newID string) error {
+	const op = "hypermind.(MultiScopeArchitecture).RenameScope"
+
+	if newID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "new scope ID is empty")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	scope, ok := m.scopes[oldID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", oldID))
+	}
+	if _, ok := m.scopes[newID]; ok {
+		return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("scope %s already exists", newID))
+	}
+
+	var children []*DistributedScope
+	for _, s := range m.scopes {
+		if s.ParentID == oldID {
+			children = append(children, s)
+		}
+	}
+
+	m.peerNetwork.mu.Lock()
+	defer m.peerNetwork.mu.Unlock()
+
+	if m.store != nil {
+		renamed := copyScope(scope)
+		renamed.ID = newID
+		if err := m.store.SaveScope(ctx, renamed); err != nil {
+			return errors.Wrap(ctx, err, op, errors.WithMsg("failed to persist scope"))
+		}
+		for _, child := range children {
+			reparented := copyScope(child)
+			reparented.ParentID = newID
+			if err := m.store.SaveScope(ctx, reparented); err != nil {
+				return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("failed to persist child scope %s", child.ID)))
+			}
+		}
+		for _, peer := range m.peerNetwork.activePeers {
+			i := slices.Index(peer.ScopeIDs, oldID)
+			if i < 0 {
+				continue
+			}
+			stored := copyPeer(peer)
+			stored.ScopeIDs[i] = newID
+			if err := m.store.SavePeer(ctx, stored); err != nil {
+				return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("failed to persist peer %s", peer.ID)))
+			}
+		}
+		if err := m.store.DeleteScope(ctx, oldID); err != nil {
+			return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("failed to delete scope %s from store", oldID)))
+		}
+	}
+
+	delete(m.scopes, oldID)
+	scope.ID = newID
+	m.scopes[newID] = scope
+	for _, child := range children {
+		child.ParentID = newID
+	}
+	if ring, ok := m.history[oldID]; ok {
+		delete(m.history, oldID)
+		m.history[newID] = ring
+	}
+
+	for _, peer := range m.peerNetwork.activePeers {
+		if i := slices.Index(peer.ScopeIDs, oldID); i >= 0 {
+			peer.ScopeIDs[i] = newID
+		}
+	}
+	m.peerNetwork.dht.renameKey(oldID, newID)
+	if limit, ok := m.peerLimits[oldID]; ok {
+		delete(m.peerLimits, oldID)
+		m.peerLimits[newID] = limit
+	}
+
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+
+	if events, ok := m.events[oldID]; ok {
+		delete(m.events, oldID)
+		for i := range events {
+			events[i].ScopeID = newID
+		}
+		m.events[newID] = events
+	}
+
+	m.subsMu.Lock()
+	defer m.subsMu.Unlock()
+
+	if subs, ok := m.subscribers[oldID]; ok {
+		delete(m.subscribers, oldID)
+		for _, sub := range subs {
+			sub.scopeID = newID
+		}
+		m.subscribers[newID] = subs
+	}
+
+	return nil
+}
+
+// GetChildScopes returns the scopes whose ParentID is scopeID, sorted by ID.
+func (m *MultiScopeArchitecture) GetChildScopes(ctx context.Context, scopeID string) ([]*DistributedScope, error) {
+	const op = "hypermind.(MultiScopeArchitecture).GetChildScopes"
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.scopes[scopeID]; !ok {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
+	}
+
+	children := make([]*DistributedScope, 0)
+	for _, scope := range m.scopes {
+		if scope.ParentID == scopeID {
+			children = append(children, scope)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].ID < children[j].ID })
+
+	return children, nil
+}
+
+// GetAncestors returns the chain of ancestors of a scope, starting with its
+// parent and ending at the root. The walk stops at a scope with no ParentID
+// or whose parent is not registered. A cycle in the chain is an error.
+func (m *MultiScopeArchitecture) GetAncestors(ctx context.Context, scopeID string) ([]*DistributedScope, error) {
+	const op = "hypermind.(MultiScopeArchitecture).GetAncestors"
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	scope, ok := m.scopes[scopeID]
+	if !ok {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
+	}
+
+	ancestors := make([]*DistributedScope, 0)
+	visited := map[string]bool{scopeID: true}
+	for scope.ParentID != "" {
+		if visited[scope.ParentID] {
+			return nil, errors.New(ctx, errors.CycleFound, op, fmt.Sprintf("scope %s has a cycle in its ancestry at %s", scopeID, scope.ParentID))
+		}
+		parent, ok := m.scopes[scope.ParentID]
+		if !ok {
+			break
+		}
+		visited[parent.ID] = true
+		ancestors = append(ancestors, parent)
+		scope = parent
+	}
+
+	return ancestors, nil
+}
+
+// ScopeNode is a scope together with its nested child scopes.
+type ScopeNode struct {
+	*DistributedScope
+
+	// Children are the child scope nodes, sorted by ID
+	Children []*ScopeNode
+
+	// Orphan is set on a root whose ParentID refers to a scope that is not
+	// registered
+	Orphan bool
+}
+
+// GetScopeTree returns the scope hierarchy as a forest of root nodes sorted by
+// ID. Scopes with no ParentID are roots; scopes whose parent is not
+// registered are also returned as roots, marked as orphans.
+func (m *MultiScopeArchitecture) GetScopeTree(ctx context.Context) ([]*ScopeNode, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	children := make(map[string][]*DistributedScope)
+	roots := make([]*ScopeNode, 0)
+	for _, scope := range m.scopes {
+		_, hasParent := m.scopes[scope.ParentID]
+		switch {
+		case scope.ParentID == "":
+			roots = append(roots, &ScopeNode{DistributedScope: scope})
+		case !hasParent:
+			roots = append(roots, &ScopeNode{DistributedScope: scope, Orphan: true})
+		default:
+			children[scope.ParentID] = append(children[scope.ParentID], scope)
+		}
+	}
+
+	visited := make(map[string]bool, len(m.scopes))
+	var build func(node *ScopeNode)
+	build = func(node *ScopeNode) {
+		visited[node.ID] = true
+		kids := children[node.ID]
+		sort.Slice(kids, func(i, j int) bool { return kids[i].ID < kids[j].ID })
+		node.Children = make([]*ScopeNode, 0, len(kids))
+		for _, kid := range kids {
+			if visited[kid.ID] {
+				continue
+			}
+			child := &ScopeNode{DistributedScope: kid}
+			build(child)
+			node.Children = append(node.Children, child)
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool { return roots[i].ID < roots[j].ID })
+	for _, root := range roots {
+		build(root)
+	}
+
+	return roots, nil
+}
+
 // PropagateState propagates state changes across the P2P network.
-func (m *MultiScopeArchitecture) PropagateState(ctx context.Context, scopeID string, state map[string]interface{}) error {
+// Supported options: WithOriginPeer
+func (m *MultiScopeArchitecture) PropagateState(ctx context.Context, scopeID string, state map[string]interface{}, opt ...Option) error {
 	const op = "hypermind.(MultiScopeArchitecture).PropagateState"
 
+	opts := getOpts(opt...)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	scope, ok := m.scopes[scopeID]
 	if !ok {
-		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("scope %s not found", scopeID))
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
 	}
 
 	// Update local state
+	applied := m.applyState(scope, state, nil, opts.withOriginPeer)
+
+	send := func(peerID string) error {
+		return m.transport.Send(ctx, peerID, scopeID, applied)
+	}
+	if err := m.propagateToPeers(ctx, scopeID, send); err != nil {
+		return errors.Wrap(ctx, err, op)
+	}
+	return nil
+}
+
+// PropagateStateBulk propagates the same state to every scope in scopeIDs
+// as PropagateState does. A failure for one scope does not stop the others;
+// the returned error joins one error per failed scope, naming the scope.
+// Supported options: WithOriginPeer
+func (m *MultiScopeArchitecture) PropagateStateBulk(ctx context.Context, scopeIDs []string, state map[string]interface{}, opt ...Option) error {
+	const op = "hypermind.(MultiScopeArchitecture).PropagateStateBulk"
+
+	var errs []error
+	for _, scopeID := range scopeIDs {
+		if err := m.PropagateState(ctx, scopeID, state, opt...); err != nil {
+			errs = append(errs, errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("propagating to scope %s", scopeID))))
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// PropagateDelta applies a partial state update to a scope, setting the keys
+// in changed and deleting the keys in removed, and gossips only that delta
+// to peers. Transports that implement DeltaTransport receive the delta
+// itself; other transports receive the scope's full resulting state.
+func (m *MultiScopeArchitecture) PropagateDelta(ctx context.Context, scopeID string, changed map[string]interface{}, removed []string) error {
+	const op = "hypermind.(MultiScopeArchitecture).PropagateDelta"
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	scope, ok := m.scopes[scopeID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
+	}
+
+	applied := m.applyState(scope, changed, removed, "")
+	removed = slices.Clone(removed)
+
+	send := func(peerID string) error {
+		if dt, ok := m.transport.(DeltaTransport); ok {
+			return dt.SendDelta(ctx, peerID, scopeID, applied, removed)
+		}
+		full := make(map[string]interface{}, len(scope.State))
+		for k, v := range scope.State {
+			full[k] = v
+		}
+		return m.transport.Send(ctx, peerID, scopeID, full)
+	}
+	if err := m.propagateToPeers(ctx, scopeID, send); err != nil {
+		return errors.Wrap(ctx, err, op)
+	}
+	return nil
+}
+
+// Receive applies a delivery gossiped by another architecture to the local
+// copy of its scope, setting the delivered keys and deleting the removed
+// ones. The update is not gossiped any further. Register it with a
+// LoopbackTransport shared with the sending architecture to connect the
+// two.
+func (m *MultiScopeArchitecture) Receive(ctx context.Context, d Delivery) error {
+	const op = "hypermind.(MultiScopeArchitecture).Receive"
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	scope, ok := m.scopes[d.ScopeID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", d.ScopeID))
+	}
+	m.applyState(scope, d.State, d.Removed, "")
+	return nil
+}
+
+// CommitState sends state to every peer of the scope and applies it locally
+// only if at least quorum participants acknowledge it, counting the local
+// node as one. If the quorum is not reached the local state is left
+// untouched, the peers that acknowledged are sent a rollback to the local
+// state and an error describing the failed sends is returned. Transports
+// that implement DeltaTransport also have the keys new to the proposed state
+// removed by the rollback; other transports are only sent the previous
+// values of the keys that existed.
+func (m *MultiScopeArchitecture) CommitState(ctx context.Context, scopeID string, state map[string]interface{}, quorum int) error {
+	const op = "hypermind.(MultiScopeArchitecture).CommitState"
+
+	if quorum <= 0 {
+		return errors.New(ctx, errors.InvalidParameter, op, "quorum must be positive")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	scope, ok := m.scopes[scopeID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
+	}
+
+	proposed := make(map[string]interface{}, len(state))
+	for k, v := range state {
+		proposed[k] = v
+	}
+
+	var acked []string
+	var sendErr error
+	for _, peerID := range m.peerNetwork.dht.lookup(scopeID) {
+		if err := m.transport.Send(ctx, peerID, scopeID, proposed); err != nil {
+			sendErr = stderrors.Join(sendErr, errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("sending to peer %s", peerID))))
+			continue
+		}
+		acked = append(acked, peerID)
+	}
+	if acks := len(acked) + 1; acks < quorum {
+		for _, peerID := range acked {
+			if err := m.rollback(ctx, peerID, scope, proposed); err != nil {
+				sendErr = stderrors.Join(sendErr, errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("rolling back peer %s", peerID))))
+			}
+		}
+		return errors.New(ctx, errors.Unavailable, op, fmt.Sprintf("quorum not reached: %d of %d required acknowledgements", acks, quorum), errors.WithWrap(sendErr))
+	}
+
+	m.applyState(scope, proposed, nil, "")
+	return nil
+}
+
+// rollback undoes a proposed state already sent to a peer by sending it the
+// scope's values of the proposed keys. Keys the scope does not hold are
+// removed when the transport implements DeltaTransport. The caller must hold
+// m.mu.
+func (m *MultiScopeArchitecture) rollback(ctx context.Context, peerID string, scope *DistributedScope, proposed map[string]interface{}) error {
+	previous := make(map[string]interface{}, len(proposed))
+	var removed []string
+	for k := range proposed {
+		if v, ok := scope.State[k]; ok {
+			previous[k] = v
+			continue
+		}
+		removed = append(removed, k)
+	}
+	sort.Strings(removed)
+	if dt, ok := m.transport.(DeltaTransport); ok {
+		return dt.SendDelta(ctx, peerID, scope.ID, previous, removed)
+	}
+	return m.transport.Send(ctx, peerID, scope.ID, previous)
+}
+
+// applyState merges state into the scope and deletes the removed keys, then
+// records the change in the scope's feed and history and notifies
+// subscribers. It returns a copy of the merged keys. The caller must hold
+// m.mu.
+func (m *MultiScopeArchitecture) applyState(scope *DistributedScope, state map[string]interface{}, removed []string, originPeer string) map[string]interface{} {
+	applied := make(map[string]interface{}, len(state))
+	changedKeys := make([]string, 0, len(state)+len(removed))
 	for k, v := range state {
 		scope.State[k] = v
+		applied[k] = v
+		changedKeys = append(changedKeys, k)
+	}
+	for _, k := range removed {
+		delete(scope.State, k)
+		if _, ok := applied[k]; !ok {
+			changedKeys = append(changedKeys, k)
+		}
 	}
-	scope.UpdatedAt = time.Now()
+	sort.Strings(changedKeys)
+	scope.UpdatedAt = m.now()
+	scope.Version++
 
-	// Propagate to peers (simplified)
-	return m.propagateToPeers(ctx, scopeID, state)
+	ring, ok := m.history[scope.ID]
+	if !ok {
+		ring = newStateRing(m.historySize)
+		m.history[scope.ID] = ring
+	}
+	ring.push(StateEvent{Timestamp: scope.UpdatedAt, OriginPeer: originPeer, ChangedKeys: changedKeys})
+	m.recordEvent(ScopeEvent{Kind: StateChangedEvent, ScopeID: scope.ID, State: applied, RemovedKeys: slices.Clone(removed)})
+	m.notifySubscribers(scope.ID, scope.State)
+	return applied
 }
 
-// propagateToPeers sends state updates to connected peers.
-func (m *MultiScopeArchitecture) propagateToPeers(ctx context.Context, scopeID string, state map[string]interface{}) error {
-	// Simplified P2P propagation
-	// In a full implementation, this would use the hypermind DHT
-	// and gossip protocol to distribute state updates
-	return nil
+// propagateToPeers gossips an update to the scope's peers found through the
+// DHT by calling send for each chosen peer. At most gossipFanout peers,
+// chosen at random, are sent the update. Every send is attempted and all
+// failures are returned together.
+func (m *MultiScopeArchitecture) propagateToPeers(ctx context.Context, scopeID string, send func(peerID string) error) error {
+	const op = "hypermind.(MultiScopeArchitecture).propagateToPeers"
+
+	peerIDs := m.peerNetwork.dht.lookup(scopeID)
+	rand.Shuffle(len(peerIDs), func(i, j int) { peerIDs[i], peerIDs[j] = peerIDs[j], peerIDs[i] })
+	if m.gossipFanout > 0 && len(peerIDs) > m.gossipFanout {
+		peerIDs = peerIDs[:m.gossipFanout]
+	}
+
+	var retErr error
+	for _, peerID := range peerIDs {
+		if err := send(peerID); err != nil {
+			retErr = stderrors.Join(retErr, errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("sending to peer %s", peerID))))
+		}
+	}
+	return retErr
 }
 
-// ConnectPeer connects a new peer to the network.
+// ConnectPeer connects a peer to the network. Connecting a peer that is
+// already connected updates its Address, ScopeIDs and LastSeen, and
+// reconciles its DHT entries with the new scope set. The peer is rejected
+// if joining any of its scopes would exceed the scope's peer limit; see
+// ScopePeerLimit.
 func (m *MultiScopeArchitecture) ConnectPeer(ctx context.Context, peer *Peer) error {
 	const op = "hypermind.(MultiScopeArchitecture).ConnectPeer"
 
@@ -190,17 +965,214 @@ func (m *MultiScopeArchitecture) ConnectPeer(ctx context.Context, peer *Peer) er
 	m.peerNetwork.mu.Lock()
 	defer m.peerNetwork.mu.Unlock()
 
-	peer.LastSeen = time.Now()
+	scopeIDs := make([]string, 0, len(peer.ScopeIDs))
+	for _, id := range peer.ScopeIDs {
+		if !slices.Contains(scopeIDs, id) {
+			scopeIDs = append(scopeIDs, id)
+		}
+	}
+
+	var joining []string
+	if existing, ok := m.peerNetwork.activePeers[peer.ID]; ok {
+		for _, scopeID := range scopeIDs {
+			if !slices.Contains(existing.ScopeIDs, scopeID) {
+				joining = append(joining, scopeID)
+			}
+		}
+	} else {
+		joining = scopeIDs
+	}
+	for _, scopeID := range joining {
+		if limit := m.peerLimit(scopeID); limit > 0 && m.peerNetwork.dht.count(scopeID) >= limit {
+			return errors.New(ctx, errors.QueueIsFull, op, fmt.Sprintf("scope %s already has the maximum of %d peers", scopeID, limit))
+		}
+	}
+
+	if _, ok := m.peerNetwork.activePeers[peer.ID]; !ok {
+		if err := m.transport.Dial(ctx, peer.ID); err != nil {
+			return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("failed to dial peer %s", peer.ID)))
+		}
+	}
+
+	now := m.now()
+	if m.store != nil {
+		stored := &Peer{ID: peer.ID, Address: peer.Address, LastSeen: now, ScopeIDs: scopeIDs}
+		if err := m.store.SavePeer(ctx, stored); err != nil {
+			return errors.Wrap(ctx, err, op, errors.WithMsg("failed to persist peer"))
+		}
+	}
+
+	var previous []string
+	if existing, ok := m.peerNetwork.activePeers[peer.ID]; ok {
+		previous = existing.ScopeIDs
+		existing.Address = peer.Address
+		peer = existing
+	}
+	peer.ScopeIDs = scopeIDs
+	peer.LastSeen = now
 	m.peerNetwork.activePeers[peer.ID] = peer
+	m.peerNetwork.dht.join(peer.ID)
+
+	for _, scopeID := range previous {
+		if !slices.Contains(scopeIDs, scopeID) {
+			m.peerNetwork.dht.remove(scopeID, peer.ID)
+			m.recordEvent(ScopeEvent{Kind: PeerDisconnectedEvent, ScopeID: scopeID, PeerID: peer.ID})
+			m.checkReplication(scopeID)
+		}
+	}
 
 	// Add to DHT for discovery
-	for _, scopeID := range peer.ScopeIDs {
-		m.peerNetwork.dht.add(scopeID, peer.ID)
+	for _, scopeID := range scopeIDs {
+		if !slices.Contains(previous, scopeID) {
+			m.peerNetwork.dht.add(scopeID, peer.ID)
+			m.recordEvent(ScopeEvent{Kind: PeerConnectedEvent, ScopeID: scopeID, PeerID: peer.ID})
+		}
+	}
+
+	return nil
+}
+
+// SetScopePeerLimit overrides the architecture's peer limit for scopeID. A
+// limit of zero leaves the scope unlimited. Peers already connected to the
+// scope stay connected even if they exceed the new limit.
+func (m *MultiScopeArchitecture) SetScopePeerLimit(ctx context.Context, scopeID string, limit int) error {
+	const op = "hypermind.(MultiScopeArchitecture).SetScopePeerLimit"
+
+	if scopeID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "scope ID is empty")
+	}
+	if limit < 0 {
+		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("peer limit %d is negative", limit))
+	}
+
+	m.peerNetwork.mu.Lock()
+	defer m.peerNetwork.mu.Unlock()
+
+	m.peerLimits[scopeID] = limit
+	return nil
+}
+
+// ScopePeerLimit returns the maximum number of peers scopeID can have: its
+// override if one is set, otherwise the limit set by WithMaxPeersPerScope.
+// Zero means unlimited.
+func (m *MultiScopeArchitecture) ScopePeerLimit(ctx context.Context, scopeID string) int {
+	m.peerNetwork.mu.RLock()
+	defer m.peerNetwork.mu.RUnlock()
+
+	return m.peerLimit(scopeID)
+}
+
+// peerLimit implements ScopePeerLimit. The caller must hold peerNetwork.mu.
+func (m *MultiScopeArchitecture) peerLimit(scopeID string) int {
+	if limit, ok := m.peerLimits[scopeID]; ok {
+		return limit
+	}
+	return m.maxPeersPerScope
+}
+
+// DisconnectPeer removes a peer from the network, from the DHT entries of
+// every scope it participated in and from the Store, if one is set. A
+// replication warning is recorded for any scope left with fewer peers than
+// the configured replication factor.
+func (m *MultiScopeArchitecture) DisconnectPeer(ctx context.Context, peerID string) error {
+	const op = "hypermind.(MultiScopeArchitecture).DisconnectPeer"
+
+	if peerID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "peer ID is empty")
+	}
+
+	m.peerNetwork.mu.Lock()
+	defer m.peerNetwork.mu.Unlock()
+
+	peer, ok := m.peerNetwork.activePeers[peerID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("peer %s not found", peerID))
+	}
+	if err := m.removePeer(ctx, peer); err != nil {
+		return errors.Wrap(ctx, err, op)
+	}
+
+	return nil
+}
+
+// Heartbeat refreshes the LastSeen time of a connected peer.
+func (m *MultiScopeArchitecture) Heartbeat(ctx context.Context, peerID string) error {
+	const op = "hypermind.(MultiScopeArchitecture).Heartbeat"
+
+	m.peerNetwork.mu.Lock()
+	defer m.peerNetwork.mu.Unlock()
+
+	peer, ok := m.peerNetwork.activePeers[peerID]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("peer %s not found", peerID))
 	}
+	peer.LastSeen = m.now()
 
 	return nil
 }
 
+// EvictStalePeers disconnects every peer whose LastSeen is more than ttl in
+// the past and returns the IDs of the removed peers in sorted order. A peer
+// that cannot be deleted from the Store stays connected; the failures are
+// returned together along with the peers that were removed.
+func (m *MultiScopeArchitecture) EvictStalePeers(ctx context.Context, ttl time.Duration) ([]string, error) {
+	const op = "hypermind.(MultiScopeArchitecture).EvictStalePeers"
+
+	m.peerNetwork.mu.Lock()
+	defer m.peerNetwork.mu.Unlock()
+
+	cutoff := m.now().Add(-ttl)
+	removed := make([]string, 0)
+	var errs []error
+	for id, peer := range m.peerNetwork.activePeers {
+		if peer.LastSeen.Before(cutoff) {
+			if err := m.removePeer(ctx, peer); err != nil {
+				errs = append(errs, errors.Wrap(ctx, err, op))
+				continue
+			}
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+
+	return removed, stderrors.Join(errs...)
+}
+
+// removePeer deletes peer from the Store, the active set and the DHT entries
+// of its scopes, recording the disconnect and any replication warnings. The
+// caller must hold m.peerNetwork.mu.
+func (m *MultiScopeArchitecture) removePeer(ctx context.Context, peer *Peer) error {
+	const op = "hypermind.(MultiScopeArchitecture).removePeer"
+
+	if m.store != nil {
+		if err := m.store.DeletePeer(ctx, peer.ID); err != nil {
+			return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("failed to delete peer %s from store", peer.ID)))
+		}
+	}
+	delete(m.peerNetwork.activePeers, peer.ID)
+	m.peerNetwork.dht.removePeer(peer.ID)
+
+	for _, scopeID := range peer.ScopeIDs {
+		m.recordEvent(ScopeEvent{Kind: PeerDisconnectedEvent, ScopeID: scopeID, PeerID: peer.ID})
+		m.checkReplication(scopeID)
+	}
+	return nil
+}
+
+// checkReplication records a replication warning if the scope has fewer peers
+// than the configured replication factor.
+func (m *MultiScopeArchitecture) checkReplication(scopeID string) {
+	remaining := len(m.peerNetwork.dht.lookup(scopeID))
+	if m.replicationFactor > 0 && remaining < m.replicationFactor {
+		m.recordEvent(ScopeEvent{
+			Kind:              ReplicationWarningEvent,
+			ScopeID:           scopeID,
+			PeerCount:         remaining,
+			ReplicationFactor: m.replicationFactor,
+		})
+	}
+}
+
 // DiscoverPeers discovers peers for a given scope using the DHT.
 func (m *MultiScopeArchitecture) DiscoverPeers(ctx context.Context, scopeID string) ([]*Peer, error) {
 	const op = "hypermind.(MultiScopeArchitecture).DiscoverPeers"
@@ -220,19 +1192,71 @@ func (m *MultiScopeArchitecture) DiscoverPeers(ctx context.Context, scopeID stri
 	return peers, nil
 }
 
-// GetActivePeers returns all currently active peers.
+// GetActivePeers returns copies of all currently active peers with their
+// Health set from LastSeen. Each copy has its own ScopeIDs, so callers may
+// modify the returned peers without affecting the architecture or racing
+// with its updates.
 func (m *MultiScopeArchitecture) GetActivePeers(ctx context.Context) []*Peer {
 	m.peerNetwork.mu.RLock()
 	defer m.peerNetwork.mu.RUnlock()
 
+	now := m.now()
 	peers := make([]*Peer, 0, len(m.peerNetwork.activePeers))
 	for _, peer := range m.peerNetwork.activePeers {
-		peers = append(peers, peer)
+		c := copyPeer(peer)
+		c.Health = m.health(peer, now)
+		peers = append(peers, c)
 	}
 
 	return peers
 }
 
+// GetPeersByHealth returns copies of the active peers whose health is status.
+func (m *MultiScopeArchitecture) GetPeersByHealth(ctx context.Context, status PeerHealth) []*Peer {
+	peers := make([]*Peer, 0)
+	for _, peer := range m.GetActivePeers(ctx) {
+		if peer.Health == status {
+			peers = append(peers, peer)
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].ID < peers[j].ID })
+	return peers
+}
+
+// health classifies peer by the age of its LastSeen at now.
+func (m *MultiScopeArchitecture) health(peer *Peer, now time.Time) PeerHealth {
+	age := now.Sub(peer.LastSeen)
+	switch {
+	case age > m.deadAfter:
+		return Dead
+	case age > m.suspectAfter:
+		return Suspect
+	default:
+		return Healthy
+	}
+}
+
+// PeerCount returns the number of currently active peers.
+func (m *MultiScopeArchitecture) PeerCount(ctx context.Context) int {
+	m.peerNetwork.mu.RLock()
+	defer m.peerNetwork.mu.RUnlock()
+
+	return len(m.peerNetwork.activePeers)
+}
+
+// RebalanceDHT recomputes which peer owns each scope in the DHT: the peer of
+// the first virtual node after the scope's hash on the consistent-hash ring
+// of every connected peer. The owner need not be one of the scope's peers.
+// It returns the scopes whose owner changed since the previous call, mapped
+// to their new owner, so operators can see the impact of peers joining and
+// leaving. The first call records the assignments and reports no moves.
+func (m *MultiScopeArchitecture) RebalanceDHT(ctx context.Context) (moves map[string]string) {
+	m.peerNetwork.mu.RLock()
+	defer m.peerNetwork.mu.RUnlock()
+
+	return m.peerNetwork.dht.rebalance()
+}
+
 // IntegrateWithBoundary integrates the hypermind architecture with Boundary's scope system.
 func (m *MultiScopeArchitecture) IntegrateWithBoundary(ctx context.Context) error {
 	const op = "hypermind.(MultiScopeArchitecture).IntegrateWithBoundary"
@@ -242,7 +1266,8 @@ func (m *MultiScopeArchitecture) IntegrateWithBoundary(ctx context.Context) erro
 	return nil
 }
 
-// add adds a peer ID to the DHT entry for a key.
+// add adds a peer ID to the DHT entry for a key, placing the peer on the
+// ring if this is its first key.
 func (d *DistributedHashTable) add(key, peerID string) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -251,17 +1276,227 @@ func (d *DistributedHashTable) add(key, peerID string) {
 		d.entries[key] = make([]string, 0)
 	}
 	d.entries[key] = append(d.entries[key], peerID)
+
+	if d.refs == nil {
+		d.refs = make(map[string]int)
+	}
+	d.refs[peerID]++
+	if d.refs[peerID] == 1 && !d.joined[peerID] {
+		d.placeOnRing(peerID)
+	}
+}
+
+// join places peerID on the ring, where it stays until removePeer, so it
+// takes its share of key ownership even when registered under no key.
+func (d *DistributedHashTable) join(peerID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.joined == nil {
+		d.joined = make(map[string]bool)
+	}
+	if d.joined[peerID] {
+		return
+	}
+	d.joined[peerID] = true
+	if d.refs[peerID] == 0 {
+		d.placeOnRing(peerID)
+	}
+}
+
+// remove removes a peer ID from the DHT entry for a key, taking the peer off
+// the ring once it is registered under no key.
+func (d *DistributedHashTable) remove(key, peerID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.removeLocked(key, peerID)
+}
+
+// count returns the number of peers registered under a key.
+func (d *DistributedHashTable) count(key string) int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return len(d.entries[key])
 }
 
-// lookup retrieves peer IDs for a key from the DHT.
+// renameKey moves the peer IDs stored under oldKey to newKey.
+func (d *DistributedHashTable) renameKey(oldKey, newKey string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if peers, ok := d.entries[oldKey]; ok {
+		delete(d.entries, oldKey)
+		d.entries[newKey] = peers
+	}
+	if owner, ok := d.owners[oldKey]; ok {
+		delete(d.owners, oldKey)
+		d.owners[newKey] = owner
+	}
+}
+
+// removeKey removes every peer ID stored under a key.
+func (d *DistributedHashTable) removeKey(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, peerID := range slices.Clone(d.entries[key]) {
+		d.removeLocked(key, peerID)
+	}
+	delete(d.owners, key)
+}
+
+// removePeer removes a peer from every key and from the ring.
+func (d *DistributedHashTable) removePeer(peerID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.joined, peerID)
+	for key, peers := range d.entries {
+		for _, id := range slices.Clone(peers) {
+			if id == peerID {
+				d.removeLocked(key, peerID)
+			}
+		}
+	}
+	d.ring = slices.DeleteFunc(d.ring, func(n ringNode) bool { return n.peerID == peerID })
+}
+
+// removeLocked removes one occurrence of peerID from key. The caller must
+// hold d.mu.
+func (d *DistributedHashTable) removeLocked(key, peerID string) {
+	peers := d.entries[key]
+	i := slices.Index(peers, peerID)
+	if i < 0 {
+		return
+	}
+	d.entries[key] = append(peers[:i], peers[i+1:]...)
+	if len(d.entries[key]) == 0 {
+		delete(d.entries, key)
+	}
+
+	d.refs[peerID]--
+	if d.refs[peerID] <= 0 {
+		delete(d.refs, peerID)
+		if !d.joined[peerID] {
+			d.ring = slices.DeleteFunc(d.ring, func(n ringNode) bool { return n.peerID == peerID })
+		}
+	}
+}
+
+// placeOnRing inserts the virtual nodes of peerID into the ring. The caller
+// must hold d.mu.
+func (d *DistributedHashTable) placeOnRing(peerID string) {
+	vnodes := d.virtualNodes
+	if vnodes <= 0 {
+		vnodes = DefaultVirtualNodes
+	}
+	for i := 0; i < vnodes; i++ {
+		d.ring = append(d.ring, ringNode{hash: ringHash(fmt.Sprintf("%s#%d", peerID, i)), peerID: peerID})
+	}
+	sort.Slice(d.ring, func(i, j int) bool {
+		if d.ring[i].hash != d.ring[j].hash {
+			return d.ring[i].hash < d.ring[j].hash
+		}
+		return d.ring[i].peerID < d.ring[j].peerID
+	})
+}
+
+// lookup retrieves all peer IDs for a key from the DHT, closest first.
 func (d *DistributedHashTable) lookup(key string) []string {
+	return d.lookupN(key, -1)
+}
+
+// lookupN returns up to n distinct peers registered under key, in ring order
+// starting from the key's hash. A negative n returns every peer of the key.
+func (d *DistributedHashTable) lookupN(key string, n int) []string {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
-	if peers, ok := d.entries[key]; ok {
-		result := make([]string, len(peers))
-		copy(result, peers)
+	return d.closest(key, n)
+}
+
+// closest implements lookupN. The caller must hold d.mu.
+func (d *DistributedHashTable) closest(key string, n int) []string {
+	members := make(map[string]bool, len(d.entries[key]))
+	for _, id := range d.entries[key] {
+		members[id] = true
+	}
+	if n < 0 || n > len(members) {
+		n = len(members)
+	}
+
+	result := make([]string, 0, n)
+	if n == 0 {
 		return result
 	}
-	return []string{}
+	h := ringHash(key)
+	start := sort.Search(len(d.ring), func(i int) bool { return d.ring[i].hash >= h })
+	for i := 0; i < len(d.ring) && len(result) < n; i++ {
+		id := d.ring[(start+i)%len(d.ring)].peerID
+		if members[id] {
+			result = append(result, id)
+			delete(members, id)
+		}
+	}
+	return result
+}
+
+// owner returns the peer of the first virtual node at or after key's hash,
+// or "" if the ring is empty. The caller must hold d.mu.
+func (d *DistributedHashTable) owner(key string) string {
+	if len(d.ring) == 0 {
+		return ""
+	}
+	h := ringHash(key)
+	i := sort.Search(len(d.ring), func(i int) bool { return d.ring[i].hash >= h })
+	return d.ring[i%len(d.ring)].peerID
+}
+
+// rebalance assigns every key to its owner on the ring and returns the keys
+// whose owner differs from the one recorded by the previous rebalance,
+// mapped to their new owner. Keys assigned for the first time are recorded
+// but not reported, and keys that no longer have peers are forgotten.
+func (d *DistributedHashTable) rebalance() map[string]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.owners == nil {
+		d.owners = make(map[string]string)
+	}
+	for key := range d.owners {
+		if _, ok := d.entries[key]; !ok {
+			delete(d.owners, key)
+		}
+	}
+
+	moves := make(map[string]string)
+	for key := range d.entries {
+		owner := d.owner(key)
+		if owner == "" {
+			continue
+		}
+		if previous, ok := d.owners[key]; ok && previous != owner {
+			moves[key] = owner
+		}
+		d.owners[key] = owner
+	}
+	return moves
+}
+
+// ringHash returns the ring position of s. FNV-1a alone maps strings that
+// differ only in their last characters, such as "scope-1" and "scope-2", to
+// nearby positions, so its result is passed through the MurmurHash3
+// finalizer to spread them around the ring.
+func ringHash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
 }
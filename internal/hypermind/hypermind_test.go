@@ -5,9 +5,15 @@ package hypermind
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/boundary/internal/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -469,3 +475,1529 @@ func TestMultiScopeArchitecture_ComplexScenario(t *testing.T) {
 	activePeers := msa.GetActivePeers(ctx)
 	assert.Equal(t, 2, len(activePeers))
 }
+
+func TestMultiScopeArchitecture_ScopeFeed(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("feed merges state and peer events in order", func(t *testing.T) {
+		msa, err := NewMultiScopeArchitecture(ctx, WithReplicationFactor(1))
+		require.NoError(t, err)
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", Type: "org"}))
+
+		require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: []string{"org-1"}}))
+		require.NoError(t, msa.PropagateState(ctx, "org-1", map[string]interface{}{"status": "active"}))
+		require.NoError(t, msa.DisconnectPeer(ctx, "peer-1"))
+
+		feed, err := msa.ScopeFeed(ctx, "org-1", time.Time{})
+		require.NoError(t, err)
+		require.Len(t, feed, 4)
+		assert.Equal(t, PeerConnectedEvent, feed[0].Kind)
+		assert.Equal(t, "peer-1", feed[0].PeerID)
+		assert.Equal(t, StateChangedEvent, feed[1].Kind)
+		assert.Equal(t, "active", feed[1].State["status"])
+		assert.Equal(t, PeerDisconnectedEvent, feed[2].Kind)
+		assert.Equal(t, ReplicationWarningEvent, feed[3].Kind)
+		assert.Equal(t, 0, feed[3].PeerCount)
+		assert.Equal(t, 1, feed[3].ReplicationFactor)
+		for i := 1; i < len(feed); i++ {
+			assert.False(t, feed[i].Timestamp.Before(feed[i-1].Timestamp))
+		}
+	})
+
+	t.Run("since filters older events", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		msa, err := NewMultiScopeArchitecture(ctx, WithClock(func() time.Time { return now }))
+		require.NoError(t, err)
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", Type: "org"}))
+		require.NoError(t, msa.PropagateState(ctx, "org-1", map[string]interface{}{"k": 1}))
+
+		now = now.Add(time.Second)
+		since := now
+		require.NoError(t, msa.PropagateState(ctx, "org-1", map[string]interface{}{"k": 2}))
+
+		feed, err := msa.ScopeFeed(ctx, "org-1", since)
+		require.NoError(t, err)
+		require.Len(t, feed, 1)
+		assert.Equal(t, 2, feed[0].State["k"])
+	})
+
+	t.Run("no replication warning when factor is satisfied", func(t *testing.T) {
+		msa, err := NewMultiScopeArchitecture(ctx)
+		require.NoError(t, err)
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", Type: "org"}))
+		require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: []string{"org-1"}}))
+		require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-2", ScopeIDs: []string{"org-1"}}))
+		require.NoError(t, msa.DisconnectPeer(ctx, "peer-1"))
+
+		feed, err := msa.ScopeFeed(ctx, "org-1", time.Time{})
+		require.NoError(t, err)
+		for _, e := range feed {
+			assert.NotEqual(t, ReplicationWarningEvent, e.Kind)
+		}
+	})
+
+	t.Run("error on unknown scope", func(t *testing.T) {
+		msa, err := NewMultiScopeArchitecture(ctx)
+		require.NoError(t, err)
+		_, err = msa.ScopeFeed(ctx, "nonexistent", time.Time{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+func TestMultiScopeArchitecture_EvictStalePeers(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	msa, err := NewMultiScopeArchitecture(ctx, WithClock(clock))
+	require.NoError(t, err)
+
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: []string{"org-1"}}))
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-2", ScopeIDs: []string{"org-1", "proj-1"}}))
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-3", ScopeIDs: []string{"proj-1"}}))
+
+	now = now.Add(30 * time.Second)
+	require.NoError(t, msa.Heartbeat(ctx, "peer-1"))
+
+	now = now.Add(45 * time.Second)
+	removed, err := msa.EvictStalePeers(ctx, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"peer-2", "peer-3"}, removed)
+
+	assert.Len(t, msa.GetActivePeers(ctx), 1)
+	peers, err := msa.DiscoverPeers(ctx, "org-1")
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	assert.Equal(t, "peer-1", peers[0].ID)
+	assert.Equal(t, []string{"peer-1"}, msa.peerNetwork.dht.lookup("org-1"))
+	assert.Empty(t, msa.peerNetwork.dht.lookup("proj-1"))
+
+	removed, err = msa.EvictStalePeers(ctx, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+
+	t.Run("heartbeat unknown peer", func(t *testing.T) {
+		err := msa.Heartbeat(ctx, "peer-2")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "peer peer-2 not found")
+	})
+}
+
+// failingTransport fails every send to the peers in fail and records the
+// rest.
+type failingTransport struct {
+	*LoopbackTransport
+	fail map[string]bool
+}
+
+func (t *failingTransport) Send(ctx context.Context, peerID, scopeID string, state map[string]interface{}) error {
+	if t.fail[peerID] {
+		return fmt.Errorf("peer %s unreachable", peerID)
+	}
+	return t.LoopbackTransport.Send(ctx, peerID, scopeID, state)
+}
+
+func TestMultiScopeArchitecture_Gossip(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, opt ...Option) *MultiScopeArchitecture {
+		msa, err := NewMultiScopeArchitecture(ctx, opt...)
+		require.NoError(t, err)
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", Type: "org"}))
+		for _, id := range []string{"peer-1", "peer-2", "peer-3"} {
+			require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: id, ScopeIDs: []string{"org-1"}}))
+		}
+		return msa
+	}
+
+	t.Run("every peer receives the state", func(t *testing.T) {
+		transport := NewLoopbackTransport()
+		msa := setup(t, WithTransport(transport))
+		require.NoError(t, msa.PropagateState(ctx, "org-1", map[string]interface{}{"status": "active"}))
+
+		deliveries := transport.Deliveries()
+		require.Len(t, deliveries, 3)
+		got := make([]string, 0, len(deliveries))
+		for _, d := range deliveries {
+			assert.Equal(t, "org-1", d.ScopeID)
+			assert.Equal(t, "active", d.State["status"])
+			got = append(got, d.PeerID)
+		}
+		assert.ElementsMatch(t, []string{"peer-1", "peer-2", "peer-3"}, got)
+	})
+
+	t.Run("fanout bounds deliveries", func(t *testing.T) {
+		transport := NewLoopbackTransport()
+		msa := setup(t, WithTransport(transport), WithGossipFanout(2))
+		require.NoError(t, msa.PropagateState(ctx, "org-1", map[string]interface{}{"k": 1}))
+		assert.Len(t, transport.Deliveries(), 2)
+	})
+
+	t.Run("send errors are aggregated", func(t *testing.T) {
+		transport := &failingTransport{
+			LoopbackTransport: NewLoopbackTransport(),
+			fail:              map[string]bool{"peer-1": true, "peer-3": true},
+		}
+		msa := setup(t, WithTransport(transport))
+		err := msa.PropagateState(ctx, "org-1", map[string]interface{}{"k": 1})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "peer peer-1 unreachable")
+		assert.Contains(t, err.Error(), "peer peer-3 unreachable")
+
+		deliveries := transport.Deliveries()
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, "peer-2", deliveries[0].PeerID)
+
+		scope, err := msa.GetScope(ctx, "org-1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, scope.State["k"])
+	})
+}
+
+func TestMultiScopeArchitecture_Hierarchy(t *testing.T) {
+	ctx := context.Background()
+	msa, err := NewMultiScopeArchitecture(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "global", Type: "global"}))
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", ParentID: "global", Type: "org"}))
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-2", ParentID: "global", Type: "org"}))
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "proj-1", ParentID: "org-1", Type: "project"}))
+
+	ids := func(scopes []*DistributedScope) []string {
+		out := make([]string, 0, len(scopes))
+		for _, s := range scopes {
+			out = append(out, s.ID)
+		}
+		return out
+	}
+
+	t.Run("children", func(t *testing.T) {
+		children, err := msa.GetChildScopes(ctx, "global")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"org-1", "org-2"}, ids(children))
+
+		children, err = msa.GetChildScopes(ctx, "proj-1")
+		require.NoError(t, err)
+		assert.Empty(t, children)
+
+		_, err = msa.GetChildScopes(ctx, "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "scope missing not found")
+	})
+
+	t.Run("ancestors", func(t *testing.T) {
+		ancestors, err := msa.GetAncestors(ctx, "proj-1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"org-1", "global"}, ids(ancestors))
+
+		ancestors, err = msa.GetAncestors(ctx, "global")
+		require.NoError(t, err)
+		assert.Empty(t, ancestors)
+	})
+
+	t.Run("ancestor cycle", func(t *testing.T) {
+		// bypass RegisterScope so the cycle can be constructed directly
+		msa.scopes["a"] = &DistributedScope{ID: "a", ParentID: "b"}
+		msa.scopes["b"] = &DistributedScope{ID: "b", ParentID: "a"}
+
+		_, err := msa.GetAncestors(ctx, "a")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle")
+	})
+}
+
+func TestMultiScopeArchitecture_RegisterScope_Cycles(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		scopes  []*DistributedScope
+		wantErr string
+	}{
+		{
+			name: "valid chain",
+			scopes: []*DistributedScope{
+				{ID: "global"},
+				{ID: "org-1", ParentID: "global"},
+				{ID: "proj-1", ParentID: "org-1"},
+			},
+		},
+		{
+			name:    "self parent",
+			scopes:  []*DistributedScope{{ID: "a", ParentID: "a"}},
+			wantErr: "parent a of scope a would create a cycle",
+		},
+		{
+			name: "indirect cycle",
+			scopes: []*DistributedScope{
+				{ID: "a", ParentID: "c"},
+				{ID: "b", ParentID: "a"},
+				{ID: "c", ParentID: "b"},
+			},
+			wantErr: "parent b of scope c would create a cycle",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msa, err := NewMultiScopeArchitecture(ctx)
+			require.NoError(t, err)
+			for _, s := range tt.scopes[:len(tt.scopes)-1] {
+				require.NoError(t, msa.RegisterScope(ctx, s))
+			}
+			last := tt.scopes[len(tt.scopes)-1]
+			err = msa.RegisterScope(ctx, last)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				_, err := msa.GetScope(ctx, last.ID)
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			ancestors, err := msa.GetAncestors(ctx, last.ID)
+			require.NoError(t, err)
+			assert.Len(t, ancestors, len(tt.scopes)-1)
+		})
+	}
+
+	t.Run("missing parent is recorded", func(t *testing.T) {
+		msa, err := NewMultiScopeArchitecture(ctx)
+		require.NoError(t, err)
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", ParentID: "global"}))
+
+		feed, err := msa.ScopeFeed(ctx, "org-1", time.Time{})
+		require.NoError(t, err)
+		require.Len(t, feed, 1)
+		assert.Equal(t, MissingParentEvent, feed[0].Kind)
+		assert.Equal(t, "global", feed[0].ParentID)
+	})
+}
+
+func TestMultiScopeArchitecture_RemoveScope(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *MultiScopeArchitecture {
+		msa, err := NewMultiScopeArchitecture(ctx)
+		require.NoError(t, err)
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "global"}))
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", ParentID: "global"}))
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-2", ParentID: "global"}))
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "proj-1", ParentID: "org-1"}))
+		require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: []string{"org-1", "proj-1", "org-2"}}))
+		return msa
+	}
+
+	t.Run("cascade removes subtree", func(t *testing.T) {
+		msa := setup(t)
+		require.NoError(t, msa.RemoveScope(ctx, "org-1", true))
+
+		for _, id := range []string{"org-1", "proj-1"} {
+			_, err := msa.GetScope(ctx, id)
+			assert.Error(t, err)
+			peers, err := msa.DiscoverPeers(ctx, id)
+			require.NoError(t, err)
+			assert.Empty(t, peers)
+		}
+		_, err := msa.GetScope(ctx, "org-2")
+		assert.NoError(t, err)
+
+		peers, err := msa.DiscoverPeers(ctx, "org-2")
+		require.NoError(t, err)
+		require.Len(t, peers, 1)
+		assert.Equal(t, []string{"org-2"}, peers[0].ScopeIDs)
+	})
+
+	t.Run("non-cascade with children", func(t *testing.T) {
+		msa := setup(t)
+		err := msa.RemoveScope(ctx, "org-1", false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "scope org-1 has child scopes")
+		_, err = msa.GetScope(ctx, "proj-1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("non-cascade leaf", func(t *testing.T) {
+		msa := setup(t)
+		require.NoError(t, msa.RemoveScope(ctx, "proj-1", false))
+		children, err := msa.GetChildScopes(ctx, "org-1")
+		require.NoError(t, err)
+		assert.Empty(t, children)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		msa := setup(t)
+		err := msa.RemoveScope(ctx, "missing", true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "scope missing not found")
+	})
+}
+
+func TestMultiScopeArchitecture_Subscribe(t *testing.T) {
+	ctx := context.Background()
+	msa, err := NewMultiScopeArchitecture(ctx)
+	require.NoError(t, err)
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1"}))
+
+	t.Run("multiple subscribers receive updates", func(t *testing.T) {
+		ch1, cancel1, err := msa.Subscribe(ctx, "org-1")
+		require.NoError(t, err)
+		defer cancel1()
+		ch2, cancel2, err := msa.Subscribe(ctx, "org-1")
+		require.NoError(t, err)
+		defer cancel2()
+
+		require.NoError(t, msa.PropagateState(ctx, "org-1", map[string]interface{}{"status": "active"}))
+		for _, ch := range []<-chan map[string]interface{}{ch1, ch2} {
+			snapshot := <-ch
+			assert.Equal(t, "active", snapshot["status"])
+		}
+	})
+
+	t.Run("unsubscribe stops delivery", func(t *testing.T) {
+		ch, cancel, err := msa.Subscribe(ctx, "org-1")
+		require.NoError(t, err)
+		cancel()
+		cancel()
+
+		require.NoError(t, msa.PropagateState(ctx, "org-1", map[string]interface{}{"k": 1}))
+		_, open := <-ch
+		assert.False(t, open)
+	})
+
+	t.Run("slow subscriber does not block", func(t *testing.T) {
+		ch, cancel, err := msa.Subscribe(ctx, "org-1")
+		require.NoError(t, err)
+		defer cancel()
+
+		for i := 0; i < subscriptionBuffer*2; i++ {
+			require.NoError(t, msa.PropagateState(ctx, "org-1", map[string]interface{}{"i": i}))
+		}
+		assert.Len(t, ch, subscriptionBuffer)
+		first := <-ch
+		assert.Equal(t, 0, first["i"])
+	})
+
+	t.Run("scope removal closes channel", func(t *testing.T) {
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-2"}))
+		ch, cancel, err := msa.Subscribe(ctx, "org-2")
+		require.NoError(t, err)
+		require.NoError(t, msa.RemoveScope(ctx, "org-2", false))
+		_, open := <-ch
+		assert.False(t, open)
+		cancel()
+	})
+
+	t.Run("unknown scope", func(t *testing.T) {
+		_, _, err := msa.Subscribe(ctx, "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "scope missing not found")
+	})
+}
+
+func TestDistributedHashTable_ConsistentHashing(t *testing.T) {
+	newDHT := func(peers ...string) *DistributedHashTable {
+		dht := &DistributedHashTable{
+			entries:      make(map[string][]string),
+			virtualNodes: 16,
+		}
+		for _, p := range peers {
+			dht.add("scope", p)
+		}
+		return dht
+	}
+	peers := []string{"peer-1", "peer-2", "peer-3", "peer-4", "peer-5"}
+
+	t.Run("lookupN returns replica count", func(t *testing.T) {
+		dht := newDHT(peers...)
+		assert.Len(t, dht.lookupN("scope", 3), 3)
+		assert.Len(t, dht.lookupN("scope", 10), 5)
+		assert.Empty(t, dht.lookupN("scope", 0))
+		assert.Empty(t, dht.lookupN("other", 3))
+		assert.ElementsMatch(t, peers, dht.lookup("scope"))
+	})
+
+	t.Run("assignment is deterministic", func(t *testing.T) {
+		assert.Equal(t, newDHT(peers...).lookupN("scope", 3), newDHT(peers...).lookupN("scope", 3))
+	})
+
+	t.Run("stable when a peer joins", func(t *testing.T) {
+		dht := newDHT(peers...)
+		before := dht.lookupN("scope", 3)
+		dht.add("scope", "peer-6")
+		after := dht.lookupN("scope", 3)
+		for _, id := range after {
+			if id != "peer-6" {
+				assert.Contains(t, before, id)
+			}
+		}
+	})
+
+	t.Run("stable when an unassigned peer leaves", func(t *testing.T) {
+		dht := newDHT(peers...)
+		before := dht.lookupN("scope", 2)
+		for _, p := range peers {
+			if !slices.Contains(before, p) {
+				dht.removePeer(p)
+				break
+			}
+		}
+		assert.Equal(t, before, dht.lookupN("scope", 2))
+	})
+
+	t.Run("removed peer leaves the ring", func(t *testing.T) {
+		dht := newDHT(peers...)
+		dht.add("other", "peer-1")
+		dht.removePeer("peer-1")
+		assert.NotContains(t, dht.lookup("scope"), "peer-1")
+		assert.Empty(t, dht.lookup("other"))
+		for _, n := range dht.ring {
+			assert.NotEqual(t, "peer-1", n.peerID)
+		}
+		assert.Len(t, dht.ring, 4*16)
+	})
+}
+
+func TestMultiScopeArchitecture_ConnectPeer_Reconnect(t *testing.T) {
+	ctx := context.Background()
+	msa, err := NewMultiScopeArchitecture(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", Address: "10.0.0.1:9200", ScopeIDs: []string{"org-1", "proj-1"}}))
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", Address: "10.0.0.2:9200", ScopeIDs: []string{"org-1", "org-2", "org-2"}}))
+	assert.Equal(t, 1, msa.PeerCount(ctx))
+
+	peers, err := msa.DiscoverPeers(ctx, "org-1")
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	assert.Equal(t, "10.0.0.2:9200", peers[0].Address)
+	assert.Equal(t, []string{"org-1", "org-2"}, peers[0].ScopeIDs)
+
+	peers, err = msa.DiscoverPeers(ctx, "org-2")
+	require.NoError(t, err)
+	assert.Len(t, peers, 1)
+
+	peers, err = msa.DiscoverPeers(ctx, "proj-1")
+	require.NoError(t, err)
+	assert.Empty(t, peers)
+
+	require.NoError(t, msa.DisconnectPeer(ctx, "peer-1"))
+	assert.Equal(t, 0, msa.PeerCount(ctx))
+	assert.Empty(t, msa.peerNetwork.dht.ring)
+}
+
+func TestMultiScopeArchitecture_CommitState(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, fail ...string) (*MultiScopeArchitecture, *failingTransport) {
+		transport := &failingTransport{LoopbackTransport: NewLoopbackTransport(), fail: make(map[string]bool)}
+		for _, id := range fail {
+			transport.fail[id] = true
+		}
+		msa, err := NewMultiScopeArchitecture(ctx, WithTransport(transport), WithGossipFanout(1))
+		require.NoError(t, err)
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", State: map[string]interface{}{"mode": "old"}}))
+		for _, id := range []string{"peer-1", "peer-2", "peer-3", "peer-4"} {
+			require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: id, ScopeIDs: []string{"org-1"}}))
+		}
+		return msa, transport
+	}
+
+	tests := []struct {
+		name       string
+		fail       []string
+		quorum     int
+		wantCommit bool
+		wantErr    string
+	}{
+		{name: "all peers ack", quorum: 5, wantCommit: true},
+		{name: "majority with failures", fail: []string{"peer-1", "peer-2"}, quorum: 3, wantCommit: true},
+		{name: "quorum not reached", fail: []string{"peer-1", "peer-2", "peer-3"}, quorum: 3, wantErr: "quorum not reached: 2 of 3"},
+		{name: "invalid quorum", quorum: 0, wantErr: "quorum must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msa, transport := setup(t, tt.fail...)
+			err := msa.CommitState(ctx, "org-1", map[string]interface{}{"mode": "new"}, tt.quorum)
+
+			scope, getErr := msa.GetScope(ctx, "org-1")
+			require.NoError(t, getErr)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Equal(t, "old", scope.State["mode"])
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "new", scope.State["mode"])
+			// commits go to every peer regardless of the gossip fanout
+			assert.Len(t, transport.Deliveries(), 4-len(tt.fail))
+		})
+	}
+
+	t.Run("peers are rolled back when quorum is not reached", func(t *testing.T) {
+		msa, transport := setup(t, "peer-1", "peer-2", "peer-3")
+		peer, err := NewMultiScopeArchitecture(ctx)
+		require.NoError(t, err)
+		require.NoError(t, peer.RegisterScope(ctx, &DistributedScope{ID: "org-1", State: map[string]interface{}{"mode": "old"}}))
+		require.NoError(t, transport.Register(ctx, "peer-4", peer.Receive))
+
+		err = msa.CommitState(ctx, "org-1", map[string]interface{}{"mode": "new", "extra": 1}, 3)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "quorum not reached: 2 of 3")
+
+		// the proposal and its rollback are both delivered, in order
+		assert.Eventually(t, func() bool {
+			history, err := peer.GetStateHistory(ctx, "org-1", 0)
+			return err == nil && len(history) == 2
+		}, time.Second, time.Millisecond)
+		state, err := peer.GetScopeState(ctx, "org-1")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"mode": "old"}, state)
+	})
+
+	t.Run("unknown scope", func(t *testing.T) {
+		msa, _ := setup(t)
+		err := msa.CommitState(ctx, "missing", map[string]interface{}{"k": 1}, 1)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "scope missing not found")
+	})
+}
+
+func TestMultiScopeArchitecture_GetScopeTree(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("three-level tree", func(t *testing.T) {
+		msa, err := NewMultiScopeArchitecture(ctx)
+		require.NoError(t, err)
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "global"}))
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-2", ParentID: "global"}))
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", ParentID: "global"}))
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "proj-1", ParentID: "org-1"}))
+
+		tree, err := msa.GetScopeTree(ctx)
+		require.NoError(t, err)
+		require.Len(t, tree, 1)
+		root := tree[0]
+		assert.Equal(t, "global", root.ID)
+		assert.False(t, root.Orphan)
+		require.Len(t, root.Children, 2)
+		assert.Equal(t, "org-1", root.Children[0].ID)
+		assert.Equal(t, "org-2", root.Children[1].ID)
+		require.Len(t, root.Children[0].Children, 1)
+		assert.Equal(t, "proj-1", root.Children[0].Children[0].ID)
+		assert.Empty(t, root.Children[0].Children[0].Children)
+		assert.Empty(t, root.Children[1].Children)
+	})
+
+	t.Run("orphan surfaced as root", func(t *testing.T) {
+		msa, err := NewMultiScopeArchitecture(ctx)
+		require.NoError(t, err)
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "global"}))
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "proj-9", ParentID: "org-missing"}))
+
+		tree, err := msa.GetScopeTree(ctx)
+		require.NoError(t, err)
+		require.Len(t, tree, 2)
+		assert.Equal(t, "global", tree[0].ID)
+		assert.False(t, tree[0].Orphan)
+		assert.Equal(t, "proj-9", tree[1].ID)
+		assert.True(t, tree[1].Orphan)
+	})
+}
+
+// sendOnlyTransport implements Transport but not DeltaTransport.
+type sendOnlyTransport struct {
+	recorder *LoopbackTransport
+}
+
+func (t *sendOnlyTransport) Dial(ctx context.Context, peerID string) error {
+	return t.recorder.Dial(ctx, peerID)
+}
+
+func (t *sendOnlyTransport) Send(ctx context.Context, peerID, scopeID string, state map[string]interface{}) error {
+	return t.recorder.Send(ctx, peerID, scopeID, state)
+}
+
+func (t *sendOnlyTransport) Close(ctx context.Context) error {
+	return t.recorder.Close(ctx)
+}
+
+func TestMultiScopeArchitecture_PropagateDelta(t *testing.T) {
+	ctx := context.Background()
+	transport := NewLoopbackTransport()
+	msa, err := NewMultiScopeArchitecture(ctx, WithTransport(transport))
+	require.NoError(t, err)
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{
+		ID:    "org-1",
+		State: map[string]interface{}{"keep": "same", "change": 1, "drop": true},
+	}))
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: []string{"org-1"}}))
+
+	require.NoError(t, msa.PropagateDelta(ctx, "org-1", map[string]interface{}{"change": 2}, []string{"drop"}))
+
+	scope, err := msa.GetScope(ctx, "org-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"keep": "same", "change": 2}, scope.State)
+	_, ok := scope.State["drop"]
+	assert.False(t, ok)
+
+	deliveries := transport.Deliveries()
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, map[string]interface{}{"change": 2}, deliveries[0].State)
+	assert.Equal(t, []string{"drop"}, deliveries[0].Removed)
+
+	t.Run("full state for plain transports", func(t *testing.T) {
+		plain := &sendOnlyTransport{recorder: NewLoopbackTransport()}
+		msa, err := NewMultiScopeArchitecture(ctx, WithTransport(plain))
+		require.NoError(t, err)
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", State: map[string]interface{}{"a": 1, "b": 2}}))
+		require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: []string{"org-1"}}))
+
+		require.NoError(t, msa.PropagateDelta(ctx, "org-1", nil, []string{"b"}))
+		deliveries := plain.recorder.Deliveries()
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, map[string]interface{}{"a": 1}, deliveries[0].State)
+	})
+
+	t.Run("unknown scope", func(t *testing.T) {
+		err := msa.PropagateDelta(ctx, "missing", nil, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "scope missing not found")
+	})
+}
+
+func TestMultiScopeArchitecture_Store(t *testing.T) {
+	ctx := context.Background()
+
+	populate := func(t *testing.T, store Store) {
+		msa, err := NewMultiScopeArchitecture(ctx, WithStore(store))
+		require.NoError(t, err)
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "global", Type: "global"}))
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", ParentID: "global", Type: "org", State: map[string]interface{}{"status": "active"}}))
+		require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", Address: "10.0.0.1:9200", ScopeIDs: []string{"global", "org-1"}}))
+		require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-2", Address: "10.0.0.2:9200", ScopeIDs: []string{"org-1"}}))
+	}
+
+	verify := func(t *testing.T, store Store) {
+		msa, err := NewMultiScopeArchitecture(ctx, WithStore(store))
+		require.NoError(t, err)
+
+		scope, err := msa.GetScope(ctx, "org-1")
+		require.NoError(t, err)
+		assert.Equal(t, "global", scope.ParentID)
+		assert.Equal(t, "org", scope.Type)
+		assert.Equal(t, "active", scope.State["status"])
+
+		assert.Equal(t, 2, msa.PeerCount(ctx))
+		peers, err := msa.DiscoverPeers(ctx, "org-1")
+		require.NoError(t, err)
+		assert.Len(t, peers, 2)
+		peers, err = msa.DiscoverPeers(ctx, "global")
+		require.NoError(t, err)
+		require.Len(t, peers, 1)
+		assert.Equal(t, "10.0.0.1:9200", peers[0].Address)
+	}
+
+	t.Run("memory store", func(t *testing.T) {
+		store := NewMemoryStore()
+		populate(t, store)
+		verify(t, store)
+	})
+
+	t.Run("file store", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "hypermind.json")
+		store, err := NewFileStore(ctx, path)
+		require.NoError(t, err)
+		populate(t, store)
+
+		reopened, err := NewFileStore(ctx, path)
+		require.NoError(t, err)
+		verify(t, reopened)
+	})
+
+	// removals must not come back when the architecture is rebuilt
+	removeAndVerify := func(t *testing.T, store Store, reopen func(t *testing.T) Store) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock := WithClock(func() time.Time { return now })
+		msa, err := NewMultiScopeArchitecture(ctx, WithStore(store), clock)
+		require.NoError(t, err)
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "global", Type: "global"}))
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", ParentID: "global", Type: "org"}))
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "proj-1", ParentID: "org-1", Type: "project"}))
+		require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "stale", ScopeIDs: []string{"global"}}))
+		now = now.Add(time.Hour)
+		require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: []string{"global", "org-1"}}))
+		require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-2", ScopeIDs: []string{"proj-1"}}))
+
+		require.NoError(t, msa.RemoveScope(ctx, "org-1", true))
+		require.NoError(t, msa.DisconnectPeer(ctx, "peer-2"))
+		evicted, err := msa.EvictStalePeers(ctx, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"stale"}, evicted)
+
+		reloaded, err := NewMultiScopeArchitecture(ctx, WithStore(reopen(t)), clock)
+		require.NoError(t, err)
+		scopeIDs := make([]string, 0)
+		for _, scope := range reloaded.ListScopes(ctx) {
+			scopeIDs = append(scopeIDs, scope.ID)
+		}
+		assert.Equal(t, []string{"global"}, scopeIDs)
+		peers := reloaded.GetActivePeers(ctx)
+		require.Len(t, peers, 1)
+		assert.Equal(t, "peer-1", peers[0].ID)
+		assert.Equal(t, []string{"global"}, peers[0].ScopeIDs)
+		assert.Empty(t, reloaded.peerNetwork.dht.lookup("org-1"))
+	}
+
+	t.Run("memory store persists removals", func(t *testing.T) {
+		store := NewMemoryStore()
+		removeAndVerify(t, store, func(t *testing.T) Store { return store })
+	})
+
+	t.Run("file store persists removals", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "hypermind.json")
+		store, err := NewFileStore(ctx, path)
+		require.NoError(t, err)
+		removeAndVerify(t, store, func(t *testing.T) Store {
+			reopened, err := NewFileStore(ctx, path)
+			require.NoError(t, err)
+			return reopened
+		})
+	})
+
+	t.Run("file store rejects corrupt file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "hypermind.json")
+		require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+		_, err := NewFileStore(ctx, path)
+		require.Error(t, err)
+	})
+}
+
+func TestMultiScopeArchitecture_PeerHealth(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	msa, err := NewMultiScopeArchitecture(ctx,
+		WithClock(func() time.Time { return now }),
+		WithHealthThresholds(10*time.Second, time.Minute))
+	require.NoError(t, err)
+
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "dead"}))
+	now = now.Add(50 * time.Second)
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "suspect"}))
+	now = now.Add(15 * time.Second)
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "healthy"}))
+	now = now.Add(5 * time.Second)
+
+	ids := func(peers []*Peer) []string {
+		out := make([]string, 0, len(peers))
+		for _, p := range peers {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"healthy"}, ids(msa.GetPeersByHealth(ctx, Healthy)))
+	assert.Equal(t, []string{"suspect"}, ids(msa.GetPeersByHealth(ctx, Suspect)))
+	assert.Equal(t, []string{"dead"}, ids(msa.GetPeersByHealth(ctx, Dead)))
+
+	for _, p := range msa.GetActivePeers(ctx) {
+		assert.Equal(t, PeerHealth(p.ID), p.Health)
+	}
+
+	require.NoError(t, msa.Heartbeat(ctx, "dead"))
+	assert.Equal(t, []string{"dead", "healthy"}, ids(msa.GetPeersByHealth(ctx, Healthy)))
+}
+
+func TestMultiScopeArchitecture_GetStateHistory(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	msa, err := NewMultiScopeArchitecture(ctx,
+		WithClock(func() time.Time { return now }),
+		WithStateHistorySize(3))
+	require.NoError(t, err)
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1"}))
+
+	history, err := msa.GetStateHistory(ctx, "org-1", 0)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Second)
+		require.NoError(t, msa.PropagateState(ctx, "org-1", map[string]interface{}{fmt.Sprintf("k%d", i): i, "common": i}, WithOriginPeer(fmt.Sprintf("peer-%d", i))))
+	}
+
+	history, err = msa.GetStateHistory(ctx, "org-1", 0)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	for i, e := range history {
+		n := i + 2
+		assert.Equal(t, fmt.Sprintf("peer-%d", n), e.OriginPeer)
+		assert.Equal(t, []string{"common", fmt.Sprintf("k%d", n)}, e.ChangedKeys)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, n+1, 0, time.UTC), e.Timestamp)
+	}
+
+	history, err = msa.GetStateHistory(ctx, "org-1", 2)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "peer-3", history[0].OriginPeer)
+	assert.Equal(t, "peer-4", history[1].OriginPeer)
+
+	t.Run("delta records removed keys", func(t *testing.T) {
+		require.NoError(t, msa.PropagateDelta(ctx, "org-1", map[string]interface{}{"b": 1}, []string{"a"}))
+		history, err := msa.GetStateHistory(ctx, "org-1", 1)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, []string{"a", "b"}, history[0].ChangedKeys)
+		assert.Empty(t, history[0].OriginPeer)
+	})
+
+	t.Run("unknown scope", func(t *testing.T) {
+		_, err := msa.GetStateHistory(ctx, "missing", 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "scope missing not found")
+	})
+}
+
+func TestMultiScopeArchitecture_ListScopes(t *testing.T) {
+	ctx := context.Background()
+	m, err := NewMultiScopeArchitecture(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, m.ListScopes(ctx))
+
+	for _, id := range []string{"org-2", "global", "org-1"} {
+		require.NoError(t, m.RegisterScope(ctx, &DistributedScope{ID: id, Type: "org"}))
+	}
+	ids := make([]string, 0)
+	for _, scope := range m.ListScopes(ctx) {
+		ids = append(ids, scope.ID)
+	}
+	assert.Equal(t, []string{"global", "org-1", "org-2"}, ids)
+}
+
+func TestMultiScopeArchitecture_Close(t *testing.T) {
+	ctx := context.Background()
+	msa, err := NewMultiScopeArchitecture(ctx)
+	require.NoError(t, err)
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", Type: "org"}))
+
+	ch, cancel, err := msa.Subscribe(ctx, "org-1")
+	require.NoError(t, err)
+
+	require.NoError(t, msa.Close(ctx))
+	_, ok := <-ch
+	assert.False(t, ok, "subscription channel should be closed")
+	cancel()
+
+	require.NoError(t, msa.Close(ctx))
+
+	_, _, err = msa.Subscribe(ctx, "org-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "architecture is closed")
+}
+
+func TestMultiScopeArchitecture_ErrorCodes(t *testing.T) {
+	ctx := context.Background()
+	msa, err := NewMultiScopeArchitecture(ctx)
+	require.NoError(t, err)
+
+	_, err = msa.GetScope(ctx, "missing")
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+
+	err = msa.PropagateState(ctx, "missing", map[string]interface{}{"k": 1})
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+
+	err = msa.RegisterScope(ctx, nil)
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+}
+
+func TestMultiScopeArchitecture_GetScopeState(t *testing.T) {
+	ctx := context.Background()
+	msa, err := NewMultiScopeArchitecture(ctx)
+	require.NoError(t, err)
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", Type: "org"}))
+
+	_, err = msa.GetScopeState(ctx, "missing")
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+
+	require.NoError(t, msa.PropagateState(ctx, "org-1", map[string]interface{}{
+		"name":   "acme",
+		"limits": map[string]interface{}{"sessions": 5, "tags": []interface{}{"a", map[string]interface{}{"b": 1}}},
+		"ports":  []int{22, 443},
+	}))
+
+	state, err := msa.GetScopeState(ctx, "org-1")
+	require.NoError(t, err)
+	state["name"] = "changed"
+	limits := state["limits"].(map[string]interface{})
+	limits["sessions"] = 99
+	limits["tags"].([]interface{})[1].(map[string]interface{})["b"] = 2
+	state["ports"].([]int)[0] = 8080
+
+	again, err := msa.GetScopeState(ctx, "org-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":   "acme",
+		"limits": map[string]interface{}{"sessions": 5, "tags": []interface{}{"a", map[string]interface{}{"b": 1}}},
+		"ports":  []int{22, 443},
+	}, again)
+
+	t.Run("concurrent reads and writes", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					assert.NoError(t, msa.PropagateState(ctx, "org-1", map[string]interface{}{
+						"counter": j,
+						"nested":  map[string]interface{}{"writer": i},
+					}))
+				}
+			}(i)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					state, err := msa.GetScopeState(ctx, "org-1")
+					if assert.NoError(t, err) {
+						state["counter"] = -1
+						if nested, ok := state["nested"].(map[string]interface{}); ok {
+							nested["writer"] = -1
+						}
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		state, err := msa.GetScopeState(ctx, "org-1")
+		require.NoError(t, err)
+		assert.Equal(t, 99, state["counter"])
+		assert.NotEqual(t, -1, state["nested"].(map[string]interface{})["writer"])
+	})
+}
+
+func TestMultiScopeArchitecture_GetScopesByType(t *testing.T) {
+	ctx := context.Background()
+	msa, err := NewMultiScopeArchitecture(ctx)
+	require.NoError(t, err)
+
+	for _, scope := range []*DistributedScope{
+		{ID: "p_2", Type: "project", ParentID: "o_1"},
+		{ID: "o_2", Type: "org", ParentID: "global"},
+		{ID: "global", Type: "global"},
+		{ID: "p_1", Type: "project", ParentID: "o_2"},
+		{ID: "o_1", Type: "org", ParentID: "global"},
+	} {
+		require.NoError(t, msa.RegisterScope(ctx, scope))
+	}
+
+	scopeIDs := func(scopes []*DistributedScope) []string {
+		ids := make([]string, 0, len(scopes))
+		for _, s := range scopes {
+			ids = append(ids, s.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"global"}, scopeIDs(msa.GetScopesByType(ctx, "global")))
+	assert.Equal(t, []string{"o_1", "o_2"}, scopeIDs(msa.GetScopesByType(ctx, "org")))
+	assert.Equal(t, []string{"p_1", "p_2"}, scopeIDs(msa.GetScopesByType(ctx, "project")))
+	assert.Empty(t, msa.GetScopesByType(ctx, "unknown"))
+}
+
+func TestLoopbackTransport_BetweenArchitectures(t *testing.T) {
+	ctx := context.Background()
+	transport := NewLoopbackTransport()
+
+	nodeA, err := NewMultiScopeArchitecture(ctx, WithTransport(transport))
+	require.NoError(t, err)
+	nodeB, err := NewMultiScopeArchitecture(ctx, WithTransport(transport))
+	require.NoError(t, err)
+	for _, node := range []*MultiScopeArchitecture{nodeA, nodeB} {
+		require.NoError(t, node.RegisterScope(ctx, &DistributedScope{ID: "org-1", Type: "org"}))
+	}
+	require.NoError(t, transport.Register(ctx, "node-a", nodeA.Receive))
+	require.NoError(t, transport.Register(ctx, "node-b", nodeB.Receive))
+	require.NoError(t, nodeA.ConnectPeer(ctx, &Peer{ID: "node-b", ScopeIDs: []string{"org-1"}}))
+	require.NoError(t, nodeB.ConnectPeer(ctx, &Peer{ID: "node-a", ScopeIDs: []string{"org-1"}}))
+
+	stateOf := func(node *MultiScopeArchitecture) map[string]interface{} {
+		state, err := node.GetScopeState(ctx, "org-1")
+		require.NoError(t, err)
+		return state
+	}
+
+	require.NoError(t, nodeA.PropagateState(ctx, "org-1", map[string]interface{}{"from": "a"}))
+	assert.Eventually(t, func() bool { return stateOf(nodeB)["from"] == "a" }, time.Second, time.Millisecond)
+
+	require.NoError(t, nodeB.PropagateDelta(ctx, "org-1", map[string]interface{}{"reply": "b"}, []string{"from"}))
+	assert.Eventually(t, func() bool {
+		state := stateOf(nodeA)
+		_, stale := state["from"]
+		return state["reply"] == "b" && !stale
+	}, time.Second, time.Millisecond)
+
+	t.Run("concurrent gossip in both directions", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for _, node := range []*MultiScopeArchitecture{nodeA, nodeB} {
+			wg.Add(1)
+			go func(node *MultiScopeArchitecture) {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					assert.NoError(t, node.PropagateState(ctx, "org-1", map[string]interface{}{"n": i}))
+				}
+			}(node)
+		}
+		wg.Wait()
+	})
+
+	t.Run("missing scope is not applied", func(t *testing.T) {
+		err := nodeB.Receive(ctx, Delivery{PeerID: "node-b", ScopeID: "missing", State: map[string]interface{}{"x": 1}})
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+	})
+
+	t.Run("closed transport", func(t *testing.T) {
+		require.NoError(t, transport.Close(ctx))
+		require.NoError(t, transport.Close(ctx))
+
+		err := nodeA.ConnectPeer(ctx, &Peer{ID: "node-c", ScopeIDs: []string{"org-1"}})
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Closed), err))
+		assert.Contains(t, err.Error(), "failed to dial peer node-c")
+
+		err = transport.Send(ctx, "node-b", "org-1", map[string]interface{}{"late": true})
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Closed), err))
+		err = transport.Register(ctx, "node-c", nodeA.Receive)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Closed), err))
+	})
+}
+
+func TestMultiScopeArchitecture_CloseTransport(t *testing.T) {
+	ctx := context.Background()
+
+	owned, err := NewMultiScopeArchitecture(ctx)
+	require.NoError(t, err)
+	require.NoError(t, owned.Close(ctx))
+	err = owned.ConnectPeer(ctx, &Peer{ID: "peer-1"})
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.Closed), err))
+
+	shared := NewLoopbackTransport()
+	msa, err := NewMultiScopeArchitecture(ctx, WithTransport(shared))
+	require.NoError(t, err)
+	require.NoError(t, msa.Close(ctx))
+	require.NoError(t, shared.Dial(ctx, "peer-1"))
+}
+
+func TestMultiScopeArchitecture_RenameScope(t *testing.T) {
+	ctx := context.Background()
+
+	msa, err := NewMultiScopeArchitecture(ctx)
+	require.NoError(t, err)
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", Type: "org"}))
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "proj-1", Type: "project", ParentID: "org-1"}))
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-3", Type: "org"}))
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: []string{"org-1"}}))
+	updates, cancel, err := msa.Subscribe(ctx, "org-1")
+	require.NoError(t, err)
+	defer cancel()
+
+	require.NoError(t, msa.RenameScope(ctx, "org-1", "org-2"))
+
+	_, err = msa.GetScope(ctx, "org-1")
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+	renamed, err := msa.GetScope(ctx, "org-2")
+	require.NoError(t, err)
+	assert.Equal(t, "org-2", renamed.ID)
+	child, err := msa.GetScope(ctx, "proj-1")
+	require.NoError(t, err)
+	assert.Equal(t, "org-2", child.ParentID)
+
+	peers, err := msa.DiscoverPeers(ctx, "org-2")
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	assert.Equal(t, []string{"org-2"}, peers[0].ScopeIDs)
+
+	require.NoError(t, msa.PropagateState(ctx, "org-2", map[string]interface{}{"k": "v"}))
+	select {
+	case state := <-updates:
+		assert.Equal(t, "v", state["k"])
+	case <-time.After(time.Second):
+		t.Fatal("subscriber was not moved to the new scope ID")
+	}
+
+	err = msa.RenameScope(ctx, "org-2", "org-3")
+	assert.True(t, errors.Match(errors.T(errors.NotUnique), err))
+	err = msa.RenameScope(ctx, "missing", "org-4")
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+
+	t.Run("store", func(t *testing.T) {
+		store := NewMemoryStore()
+		msa, err := NewMultiScopeArchitecture(ctx, WithStore(store))
+		require.NoError(t, err)
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1", Type: "org"}))
+		require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "proj-1", Type: "project", ParentID: "org-1"}))
+		require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: []string{"org-1"}}))
+
+		require.NoError(t, msa.RenameScope(ctx, "org-1", "org-2"))
+
+		reloaded, err := NewMultiScopeArchitecture(ctx, WithStore(store))
+		require.NoError(t, err)
+		scopeIDs := make([]string, 0)
+		for _, scope := range reloaded.ListScopes(ctx) {
+			scopeIDs = append(scopeIDs, scope.ID)
+		}
+		assert.Equal(t, []string{"org-2", "proj-1"}, scopeIDs)
+		child, err := reloaded.GetScope(ctx, "proj-1")
+		require.NoError(t, err)
+		assert.Equal(t, "org-2", child.ParentID)
+		peers, err := reloaded.DiscoverPeers(ctx, "org-2")
+		require.NoError(t, err)
+		require.Len(t, peers, 1)
+		assert.Equal(t, []string{"org-2"}, peers[0].ScopeIDs)
+	})
+}
+
+func TestMultiScopeArchitecture_RebalanceDHT(t *testing.T) {
+	ctx := context.Background()
+
+	const numScopes = 300
+	scopeIDs := make([]string, 0, numScopes)
+	for i := 0; i < numScopes; i++ {
+		scopeIDs = append(scopeIDs, fmt.Sprintf("scope-%d", i))
+	}
+
+	msa, err := NewMultiScopeArchitecture(ctx)
+	require.NoError(t, err)
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: scopeIDs}))
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-2", ScopeIDs: scopeIDs}))
+	assert.Empty(t, msa.RebalanceDHT(ctx))
+	assert.Empty(t, msa.RebalanceDHT(ctx))
+
+	// A third peer takes over roughly a third of the scopes, all of them
+	// from the existing peers and none moving between the existing peers.
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-3", ScopeIDs: scopeIDs}))
+	joined := msa.RebalanceDHT(ctx)
+	for scopeID, owner := range joined {
+		assert.Equal(t, "peer-3", owner, "scope %s", scopeID)
+	}
+	assert.Greater(t, len(joined), numScopes/6)
+	assert.Less(t, len(joined), numScopes/2)
+
+	// When it leaves, exactly the scopes it took over move back.
+	require.NoError(t, msa.DisconnectPeer(ctx, "peer-3"))
+	left := msa.RebalanceDHT(ctx)
+	assert.Len(t, left, len(joined))
+	for scopeID, owner := range left {
+		assert.Contains(t, joined, scopeID)
+		assert.NotEqual(t, "peer-3", owner)
+	}
+
+	// Ownership hashes onto the ring of every connected peer, so a peer
+	// registered under no scope still takes its share.
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-4"}))
+	idle := msa.RebalanceDHT(ctx)
+	for scopeID, owner := range idle {
+		assert.Equal(t, "peer-4", owner, "scope %s", scopeID)
+	}
+	assert.Greater(t, len(idle), numScopes/6)
+	assert.Less(t, len(idle), numScopes/2)
+
+	require.NoError(t, msa.DisconnectPeer(ctx, "peer-4"))
+	assert.Len(t, msa.RebalanceDHT(ctx), len(idle))
+}
+
+func TestMultiScopeArchitecture_PeerLimits(t *testing.T) {
+	ctx := context.Background()
+
+	msa, err := NewMultiScopeArchitecture(ctx, WithMaxPeersPerScope(2))
+	require.NoError(t, err)
+	require.NoError(t, msa.SetScopePeerLimit(ctx, "org-big", 3))
+	assert.Equal(t, 2, msa.ScopePeerLimit(ctx, "org-1"))
+	assert.Equal(t, 3, msa.ScopePeerLimit(ctx, "org-big"))
+
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: []string{"org-1", "org-big"}}))
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-2", ScopeIDs: []string{"org-1", "org-big"}}))
+
+	err = msa.ConnectPeer(ctx, &Peer{ID: "peer-3", ScopeIDs: []string{"org-2", "org-1"}})
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.QueueIsFull), err))
+	assert.Contains(t, err.Error(), "scope org-1")
+	// The rejected peer joined none of its scopes.
+	assert.Equal(t, 2, msa.PeerCount(ctx))
+	peers, err := msa.DiscoverPeers(ctx, "org-2")
+	require.NoError(t, err)
+	assert.Empty(t, peers)
+
+	// Other scopes are unaffected, and the override allows a third peer.
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-3", ScopeIDs: []string{"org-2", "org-big"}}))
+	err = msa.ConnectPeer(ctx, &Peer{ID: "peer-4", ScopeIDs: []string{"org-big"}})
+	assert.True(t, errors.Match(errors.T(errors.QueueIsFull), err))
+
+	// Reconnecting a peer to scopes it already has is not limited.
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: []string{"org-1", "org-big"}}))
+
+	// Removing the limit lets the scope grow.
+	require.NoError(t, msa.SetScopePeerLimit(ctx, "org-1", 0))
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-4", ScopeIDs: []string{"org-1"}}))
+
+	err = msa.SetScopePeerLimit(ctx, "org-1", -1)
+	assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+}
+
+func TestMultiScopeArchitecture_PropagateStateBulk(t *testing.T) {
+	ctx := context.Background()
+
+	msa, err := NewMultiScopeArchitecture(ctx)
+	require.NoError(t, err)
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-1"}))
+	require.NoError(t, msa.RegisterScope(ctx, &DistributedScope{ID: "org-2"}))
+
+	err = msa.PropagateStateBulk(ctx, []string{"org-1", "missing", "org-2"}, map[string]interface{}{"flag": true})
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+	assert.Contains(t, err.Error(), "propagating to scope missing")
+	joined, ok := err.(interface{ Unwrap() []error })
+	require.True(t, ok)
+	assert.Len(t, joined.Unwrap(), 1)
+
+	for _, id := range []string{"org-1", "org-2"} {
+		scope, err := msa.GetScope(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, true, scope.State["flag"])
+	}
+
+	assert.NoError(t, msa.PropagateStateBulk(ctx, []string{"org-1", "org-2"}, map[string]interface{}{"flag": false}))
+}
+
+func TestMultiScopeArchitecture_GetActivePeersConcurrent(t *testing.T) {
+	ctx := context.Background()
+
+	msa, err := NewMultiScopeArchitecture(ctx)
+	require.NoError(t, err)
+	require.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: []string{"org-1"}}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				for _, peer := range msa.GetActivePeers(ctx) {
+					peer.ScopeIDs = append(peer.ScopeIDs[:0], "mutated")
+					peer.Address = "mutated"
+				}
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.NoError(t, msa.ConnectPeer(ctx, &Peer{ID: "peer-1", ScopeIDs: []string{"org-1", fmt.Sprintf("org-%d", i+2)}}))
+				assert.NoError(t, msa.Heartbeat(ctx, "peer-1"))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	peers := msa.GetActivePeers(ctx)
+	require.Len(t, peers, 1)
+	assert.Empty(t, peers[0].Address)
+	assert.Contains(t, peers[0].ScopeIDs, "org-1")
+	assert.NotContains(t, peers[0].ScopeIDs, "mutated")
+}
+
+func TestMultiScopeArchitecture_SyncScope(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// node-a's clock runs an hour behind node-b's, but node-a has applied
+	// more changes, so its state is the newer one
+	setup := func(t *testing.T) (*LoopbackTransport, *MultiScopeArchitecture, *MultiScopeArchitecture) {
+		transport := NewLoopbackTransport()
+		nodeA, err := NewMultiScopeArchitecture(ctx, WithTransport(transport), WithClock(func() time.Time { return base }))
+		require.NoError(t, err)
+		nodeB, err := NewMultiScopeArchitecture(ctx, WithTransport(transport), WithClock(func() time.Time { return base.Add(time.Hour) }))
+		require.NoError(t, err)
+		for _, node := range []*MultiScopeArchitecture{nodeA, nodeB} {
+			require.NoError(t, node.RegisterScope(ctx, &DistributedScope{ID: "org-1", Type: "org"}))
+		}
+		require.NoError(t, transport.RegisterState(ctx, "node-a", nodeA.Snapshot))
+		require.NoError(t, transport.RegisterState(ctx, "node-b", nodeB.Snapshot))
+
+		// no peers are connected, so no update is gossiped
+		require.NoError(t, nodeA.PropagateState(ctx, "org-1", map[string]interface{}{"only-a": 1}))
+		require.NoError(t, nodeA.PropagateState(ctx, "org-1", map[string]interface{}{"shared": "new"}))
+		require.NoError(t, nodeB.PropagateState(ctx, "org-1", map[string]interface{}{"shared": "old", "only-b": 2}))
+		return transport, nodeA, nodeB
+	}
+	// loopOf returns the node's only running anti-entropy loop
+	loopOf := func(t *testing.T, node *MultiScopeArchitecture) *antiEntropyLoop {
+		node.subsMu.Lock()
+		defer node.subsMu.Unlock()
+		require.Len(t, node.antiEntropyLoops, 1)
+		for loop := range node.antiEntropyLoops {
+			return loop
+		}
+		return nil
+	}
+	exited := func(loop *antiEntropyLoop) bool {
+		select {
+		case <-loop.exited:
+			return true
+		default:
+			return false
+		}
+	}
+	stateOf := func(t *testing.T, node *MultiScopeArchitecture) map[string]interface{} {
+		state, err := node.GetScopeState(ctx, "org-1")
+		require.NoError(t, err)
+		return state
+	}
+
+	t.Run("stale architecture catches up", func(t *testing.T) {
+		_, nodeA, nodeB := setup(t)
+
+		require.NoError(t, nodeB.SyncScope(ctx, "node-a", "org-1"))
+		assert.Equal(t, map[string]interface{}{"shared": "new", "only-a": 1, "only-b": 2}, stateOf(t, nodeB))
+
+		history, err := nodeB.GetStateHistory(ctx, "org-1", 1)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, "node-a", history[0].OriginPeer)
+		assert.Equal(t, []string{"only-a", "shared"}, history[0].ChangedKeys)
+
+		// the newer architecture only gains the missing key
+		require.NoError(t, nodeA.SyncScope(ctx, "node-b", "org-1"))
+		assert.Equal(t, stateOf(t, nodeB), stateOf(t, nodeA))
+	})
+
+	t.Run("equal versions fall back to UpdatedAt", func(t *testing.T) {
+		_, nodeA, nodeB := setup(t)
+		require.NoError(t, nodeB.PropagateState(ctx, "org-1", map[string]interface{}{"shared": "later"}))
+
+		// both are at version 2 and node-b's clock is ahead
+		require.NoError(t, nodeA.SyncScope(ctx, "node-b", "org-1"))
+		assert.Equal(t, "later", stateOf(t, nodeA)["shared"])
+	})
+
+	t.Run("merged state orders after both", func(t *testing.T) {
+		_, nodeA, nodeB := setup(t)
+		require.NoError(t, nodeB.SyncScope(ctx, "node-a", "org-1"))
+		scopeA, err := nodeA.GetScope(ctx, "org-1")
+		require.NoError(t, err)
+		scopeB, err := nodeB.GetScope(ctx, "org-1")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), scopeA.Version)
+		assert.Equal(t, uint64(3), scopeB.Version)
+	})
+
+	t.Run("anti-entropy round", func(t *testing.T) {
+		_, _, nodeB := setup(t)
+
+		// without peers there is nothing to sync from
+		require.NoError(t, nodeB.antiEntropyRound(ctx))
+		assert.Equal(t, "old", stateOf(t, nodeB)["shared"])
+
+		require.NoError(t, nodeB.ConnectPeer(ctx, &Peer{ID: "node-a", ScopeIDs: []string{"org-1"}}))
+		require.NoError(t, nodeB.antiEntropyRound(ctx))
+		assert.Equal(t, "new", stateOf(t, nodeB)["shared"])
+
+		require.NoError(t, nodeB.ConnectPeer(ctx, &Peer{ID: "node-c", ScopeIDs: []string{"org-1"}}))
+		require.NoError(t, nodeB.DisconnectPeer(ctx, "node-a"))
+		err := nodeB.antiEntropyRound(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "peer node-c has no state handler")
+	})
+
+	t.Run("newer local state wins conflicts", func(t *testing.T) {
+		_, nodeA, _ := setup(t)
+
+		require.NoError(t, nodeA.SyncScope(ctx, "node-b", "org-1"))
+		assert.Equal(t, map[string]interface{}{"shared": "new", "only-a": 1, "only-b": 2}, stateOf(t, nodeA))
+
+		// a sync that changes nothing records nothing
+		before, err := nodeA.GetStateHistory(ctx, "org-1", 0)
+		require.NoError(t, err)
+		require.NoError(t, nodeA.SyncScope(ctx, "node-b", "org-1"))
+		after, err := nodeA.GetStateHistory(ctx, "org-1", 0)
+		require.NoError(t, err)
+		assert.Len(t, after, len(before))
+	})
+
+	t.Run("anti-entropy loop", func(t *testing.T) {
+		_, _, nodeB := setup(t)
+		require.NoError(t, nodeB.ConnectPeer(ctx, &Peer{ID: "node-a", ScopeIDs: []string{"org-1"}}))
+
+		stop, err := nodeB.StartAntiEntropy(ctx, time.Millisecond)
+		require.NoError(t, err)
+		loop := loopOf(t, nodeB)
+		assert.Eventually(t, func() bool { return stateOf(t, nodeB)["shared"] == "new" }, time.Second, time.Millisecond)
+		stop()
+		stop()
+
+		// stop returns once the loop has exited, so no further round runs
+		assert.True(t, exited(loop))
+		assert.Empty(t, nodeB.antiEntropyLoops)
+
+		_, err = nodeB.StartAntiEntropy(ctx, 0)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+
+	t.Run("close stops the loop", func(t *testing.T) {
+		_, _, nodeB := setup(t)
+		require.NoError(t, nodeB.ConnectPeer(ctx, &Peer{ID: "node-a", ScopeIDs: []string{"org-1"}}))
+
+		stop, err := nodeB.StartAntiEntropy(ctx, time.Millisecond)
+		require.NoError(t, err)
+		loop := loopOf(t, nodeB)
+
+		require.NoError(t, nodeB.Close(ctx))
+		assert.True(t, exited(loop), "anti-entropy loop still running after Close")
+		assert.Empty(t, nodeB.antiEntropyLoops)
+		stop()
+
+		_, err = nodeB.StartAntiEntropy(ctx, time.Millisecond)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Closed), err))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		transport, _, nodeB := setup(t)
+
+		err := nodeB.SyncScope(ctx, "", "org-1")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+
+		err = nodeB.SyncScope(ctx, "node-a", "missing")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+
+		err = nodeB.SyncScope(ctx, "node-c", "org-1")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+		assert.Contains(t, err.Error(), "peer node-c has no state handler")
+
+		sendOnly, err := NewMultiScopeArchitecture(ctx, WithTransport(&sendOnlyTransport{recorder: NewLoopbackTransport()}))
+		require.NoError(t, err)
+		require.NoError(t, sendOnly.RegisterScope(ctx, &DistributedScope{ID: "org-1", Type: "org"}))
+		err = sendOnly.SyncScope(ctx, "node-a", "org-1")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		assert.Contains(t, err.Error(), "cannot fetch state")
+
+		require.NoError(t, transport.Close(ctx))
+		err = nodeB.SyncScope(ctx, "node-a", "org-1")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.Closed), err))
+	})
+}
@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package hypermind
+
+import "time"
+
+// DefaultReplicationFactor is the minimum number of peers a scope is expected
+// to have before a replication warning is recorded in its activity feed.
+const DefaultReplicationFactor = 1
+
+// DefaultGossipFanout is the maximum number of peers a state update is sent
+// to directly when it is propagated.
+const DefaultGossipFanout = 3
+
+// DefaultVirtualNodes is the number of positions each peer occupies on the
+// DHT's consistent-hash ring.
+const DefaultVirtualNodes = 64
+
+// DefaultStateHistorySize is the number of state changes retained per scope
+// for GetStateHistory.
+const DefaultStateHistorySize = 100
+
+// DefaultSuspectAfter and DefaultDeadAfter are the LastSeen ages at which a
+// peer is reported as Suspect and Dead.
+const (
+	DefaultSuspectAfter = 30 * time.Second
+	DefaultDeadAfter    = 2 * time.Minute
+)
+
+// getOpts - iterate the inbound Options and return a struct
+func getOpts(opt ...Option) options {
+	opts := getDefaultOptions()
+	for _, o := range opt {
+		o(&opts)
+	}
+	return opts
+}
+
+// Option - how Options are passed as arguments
+type Option func(*options)
+
+// options = how options are represented
+type options struct {
+	withReplicationFactor int
+	withClock             func() time.Time
+	withTransport         Transport
+	withGossipFanout      int
+	withVirtualNodes      int
+	withStore             Store
+	withSuspectAfter      time.Duration
+	withDeadAfter         time.Duration
+	withStateHistorySize  int
+	withOriginPeer        string
+	withMaxPeersPerScope  int
+}
+
+func getDefaultOptions() options {
+	return options{
+		withReplicationFactor: DefaultReplicationFactor,
+		withClock:             time.Now,
+		withGossipFanout:      DefaultGossipFanout,
+		withVirtualNodes:      DefaultVirtualNodes,
+		withSuspectAfter:      DefaultSuspectAfter,
+		withDeadAfter:         DefaultDeadAfter,
+		withStateHistorySize:  DefaultStateHistorySize,
+	}
+}
+
+// WithReplicationFactor sets the minimum number of peers each scope should
+// have. When a peer disconnects and leaves a scope with fewer peers, a
+// replication warning is recorded in the scope's activity feed. A value <= 0
+// disables replication warnings.
+func WithReplicationFactor(n int) Option {
+	return func(o *options) {
+		o.withReplicationFactor = n
+	}
+}
+
+// WithClock sets the function used to read the current time when stamping
+// peers, scopes and events. It defaults to time.Now and is mainly useful for
+// tests.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		if now != nil {
+			o.withClock = now
+		}
+	}
+}
+
+// WithTransport sets the transport used to gossip state to peers. When not
+// set, a LoopbackTransport is used.
+func WithTransport(t Transport) Option {
+	return func(o *options) {
+		o.withTransport = t
+	}
+}
+
+// WithGossipFanout bounds the number of peers each state update is sent to.
+// A value <= 0 sends to every peer of the scope.
+func WithGossipFanout(n int) Option {
+	return func(o *options) {
+		o.withGossipFanout = n
+	}
+}
+
+// WithVirtualNodes sets the number of positions each peer occupies on the
+// DHT's consistent-hash ring. More virtual nodes spread keys more evenly. A
+// value <= 0 uses DefaultVirtualNodes.
+func WithVirtualNodes(n int) Option {
+	return func(o *options) {
+		o.withVirtualNodes = n
+	}
+}
+
+// WithStore sets the Store that registered scopes and connected peers are
+// persisted to. The architecture is rehydrated from the store when it is
+// created.
+func WithStore(s Store) Option {
+	return func(o *options) {
+		o.withStore = s
+	}
+}
+
+// WithHealthThresholds sets the LastSeen ages after which a peer is reported
+// as Suspect and as Dead.
+func WithHealthThresholds(suspectAfter, deadAfter time.Duration) Option {
+	return func(o *options) {
+		o.withSuspectAfter = suspectAfter
+		o.withDeadAfter = deadAfter
+	}
+}
+
+// WithMaxPeersPerScope caps the number of peers each scope can have.
+// ConnectPeer rejects a peer that would push one of its scopes over the
+// limit. SetScopePeerLimit overrides the limit for a single scope. A value
+// <= 0 leaves scopes unlimited.
+func WithMaxPeersPerScope(n int) Option {
+	return func(o *options) {
+		o.withMaxPeersPerScope = n
+	}
+}
+
+// WithStateHistorySize sets the number of state changes retained per scope.
+// Older changes are evicted first. A value <= 0 disables the history.
+func WithStateHistorySize(n int) Option {
+	return func(o *options) {
+		o.withStateHistorySize = n
+	}
+}
+
+// WithOriginPeer identifies the peer a propagated state change came from.
+func WithOriginPeer(peerID string) Option {
+	return func(o *options) {
+		o.withOriginPeer = peerID
+	}
+}
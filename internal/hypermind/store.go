@@ -0,0 +1,305 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package hypermind
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/boundary/internal/errors"
+)
+
+// Store persists scopes and peers so that an architecture can be rehydrated
+// after a restart. Implementations must be safe for concurrent use.
+type Store interface {
+	// SaveScope creates or replaces the stored scope with the same ID.
+	SaveScope(ctx context.Context, scope *DistributedScope) error
+
+	// LoadScopes returns every stored scope.
+	LoadScopes(ctx context.Context) ([]*DistributedScope, error)
+
+	// DeleteScope removes the stored scope with the given ID. Deleting a
+	// scope that is not stored is not an error.
+	DeleteScope(ctx context.Context, scopeID string) error
+
+	// SavePeer creates or replaces the stored peer with the same ID.
+	SavePeer(ctx context.Context, peer *Peer) error
+
+	// LoadPeers returns every stored peer.
+	LoadPeers(ctx context.Context) ([]*Peer, error)
+
+	// DeletePeer removes the stored peer with the given ID. Deleting a peer
+	// that is not stored is not an error.
+	DeletePeer(ctx context.Context, peerID string) error
+}
+
+// storeContents is the data held by the built-in stores.
+type storeContents struct {
+	Scopes map[string]*DistributedScope `json:"scopes"`
+	Peers  map[string]*Peer             `json:"peers"`
+}
+
+func newStoreContents() storeContents {
+	return storeContents{
+		Scopes: make(map[string]*DistributedScope),
+		Peers:  make(map[string]*Peer),
+	}
+}
+
+// scopes returns copies of the stored scopes sorted by ID.
+func (c storeContents) scopes() []*DistributedScope {
+	scopes := make([]*DistributedScope, 0, len(c.Scopes))
+	for _, s := range c.Scopes {
+		scopes = append(scopes, copyScope(s))
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i].ID < scopes[j].ID })
+	return scopes
+}
+
+// peers returns copies of the stored peers sorted by ID.
+func (c storeContents) peers() []*Peer {
+	peers := make([]*Peer, 0, len(c.Peers))
+	for _, p := range c.Peers {
+		peers = append(peers, copyPeer(p))
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].ID < peers[j].ID })
+	return peers
+}
+
+// MemoryStore is a Store that keeps copies of scopes and peers in memory.
+type MemoryStore struct {
+	contents storeContents
+
+	mu sync.RWMutex
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{contents: newStoreContents()}
+}
+
+// SaveScope stores a copy of scope.
+func (s *MemoryStore) SaveScope(ctx context.Context, scope *DistributedScope) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.contents.Scopes[scope.ID] = copyScope(scope)
+	return nil
+}
+
+// LoadScopes returns copies of the stored scopes sorted by ID.
+func (s *MemoryStore) LoadScopes(ctx context.Context) ([]*DistributedScope, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.contents.scopes(), nil
+}
+
+// DeleteScope removes the stored scope with the given ID.
+func (s *MemoryStore) DeleteScope(ctx context.Context, scopeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.contents.Scopes, scopeID)
+	return nil
+}
+
+// SavePeer stores a copy of peer.
+func (s *MemoryStore) SavePeer(ctx context.Context, peer *Peer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.contents.Peers[peer.ID] = copyPeer(peer)
+	return nil
+}
+
+// LoadPeers returns copies of the stored peers sorted by ID.
+func (s *MemoryStore) LoadPeers(ctx context.Context) ([]*Peer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.contents.peers(), nil
+}
+
+// DeletePeer removes the stored peer with the given ID.
+func (s *MemoryStore) DeletePeer(ctx context.Context, peerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.contents.Peers, peerID)
+	return nil
+}
+
+// FileStore is a Store that keeps scopes and peers in a JSON file. The whole
+// file is rewritten on every save. Scope state round-trips through JSON, so
+// numeric values are loaded as float64.
+type FileStore struct {
+	path     string
+	contents storeContents
+
+	mu sync.RWMutex
+}
+
+// NewFileStore opens a JSON file store at path, loading its contents if the
+// file exists.
+func NewFileStore(ctx context.Context, path string) (*FileStore, error) {
+	const op = "hypermind.NewFileStore"
+
+	if path == "" {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "path is empty")
+	}
+
+	s := &FileStore{path: path, contents: newStoreContents()}
+	raw, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return s, nil
+	case err != nil:
+		return nil, errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("reading %s", path)))
+	}
+	if err := json.Unmarshal(raw, &s.contents); err != nil {
+		return nil, errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("decoding %s", path)))
+	}
+	if s.contents.Scopes == nil {
+		s.contents.Scopes = make(map[string]*DistributedScope)
+	}
+	if s.contents.Peers == nil {
+		s.contents.Peers = make(map[string]*Peer)
+	}
+	return s, nil
+}
+
+// SaveScope stores scope and rewrites the file.
+func (s *FileStore) SaveScope(ctx context.Context, scope *DistributedScope) error {
+	const op = "hypermind.(FileStore).SaveScope"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.contents.Scopes[scope.ID]
+	s.contents.Scopes[scope.ID] = copyScope(scope)
+	if err := s.flush(); err != nil {
+		if existed {
+			s.contents.Scopes[scope.ID] = previous
+		} else {
+			delete(s.contents.Scopes, scope.ID)
+		}
+		return errors.Wrap(ctx, err, op)
+	}
+	return nil
+}
+
+// LoadScopes returns the stored scopes sorted by ID.
+func (s *FileStore) LoadScopes(ctx context.Context) ([]*DistributedScope, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.contents.scopes(), nil
+}
+
+// DeleteScope removes the stored scope with the given ID and rewrites the
+// file.
+func (s *FileStore) DeleteScope(ctx context.Context, scopeID string) error {
+	const op = "hypermind.(FileStore).DeleteScope"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.contents.Scopes[scopeID]
+	if !existed {
+		return nil
+	}
+	delete(s.contents.Scopes, scopeID)
+	if err := s.flush(); err != nil {
+		s.contents.Scopes[scopeID] = previous
+		return errors.Wrap(ctx, err, op)
+	}
+	return nil
+}
+
+// SavePeer stores peer and rewrites the file.
+func (s *FileStore) SavePeer(ctx context.Context, peer *Peer) error {
+	const op = "hypermind.(FileStore).SavePeer"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.contents.Peers[peer.ID]
+	s.contents.Peers[peer.ID] = copyPeer(peer)
+	if err := s.flush(); err != nil {
+		if existed {
+			s.contents.Peers[peer.ID] = previous
+		} else {
+			delete(s.contents.Peers, peer.ID)
+		}
+		return errors.Wrap(ctx, err, op)
+	}
+	return nil
+}
+
+// LoadPeers returns the stored peers sorted by ID.
+func (s *FileStore) LoadPeers(ctx context.Context) ([]*Peer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.contents.peers(), nil
+}
+
+// DeletePeer removes the stored peer with the given ID and rewrites the
+// file.
+func (s *FileStore) DeletePeer(ctx context.Context, peerID string) error {
+	const op = "hypermind.(FileStore).DeletePeer"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.contents.Peers[peerID]
+	if !existed {
+		return nil
+	}
+	delete(s.contents.Peers, peerID)
+	if err := s.flush(); err != nil {
+		s.contents.Peers[peerID] = previous
+		return errors.Wrap(ctx, err, op)
+	}
+	return nil
+}
+
+// flush writes the contents to a temporary file and renames it over the
+// store's path so a crash never leaves a partially written file. The caller
+// must hold s.mu.
+func (s *FileStore) flush() error {
+	raw, err := json.MarshalIndent(s.contents, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// copyScope returns a copy of scope with its own State map.
+func copyScope(scope *DistributedScope) *DistributedScope {
+	c := *scope
+	c.Peers = slices.Clone(scope.Peers)
+	c.State = make(map[string]interface{}, len(scope.State))
+	for k, v := range scope.State {
+		c.State[k] = v
+	}
+	return &c
+}
+
+// copyPeer returns a copy of peer with its own ScopeIDs slice.
+func copyPeer(peer *Peer) *Peer {
+	c := *peer
+	c.ScopeIDs = slices.Clone(peer.ScopeIDs)
+	return &c
+}
@@ -0,0 +1,141 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package hypermind
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/boundary/internal/errors"
+)
+
+// subscriptionBuffer is the number of state snapshots buffered per
+// subscriber. Snapshots that arrive while the buffer is full are dropped.
+const subscriptionBuffer = 16
+
+// subscription is a single subscriber to a scope's state changes.
+type subscription struct {
+	ch   chan map[string]interface{}
+	once sync.Once
+
+	// scopeID is the subscribed scope, updated by RenameScope and
+	// protected by the architecture's subsMu
+	scopeID string
+}
+
+// Subscribe returns a channel that receives a snapshot of the scope's state
+// after every PropagateState for that scope, along with a function that
+// unsubscribes and closes the channel. Delivery never blocks PropagateState:
+// each subscriber has a buffer of subscriptionBuffer snapshots and further
+// snapshots are dropped until the subscriber catches up. The channel is also
+// closed if the scope is removed.
+func (m *MultiScopeArchitecture) Subscribe(ctx context.Context, scopeID string) (<-chan map[string]interface{}, func(), error) {
+	const op = "hypermind.(MultiScopeArchitecture).Subscribe"
+
+	m.mu.RLock()
+	_, ok := m.scopes[scopeID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("scope %s not found", scopeID))
+	}
+
+	sub := &subscription{ch: make(chan map[string]interface{}, subscriptionBuffer), scopeID: scopeID}
+
+	m.subsMu.Lock()
+	if m.subsClosed {
+		m.subsMu.Unlock()
+		return nil, nil, errors.New(ctx, errors.Closed, op, "architecture is closed")
+	}
+	m.subscribers[scopeID] = append(m.subscribers[scopeID], sub)
+	m.subsMu.Unlock()
+
+	cancel := func() {
+		m.subsMu.Lock()
+		defer m.subsMu.Unlock()
+
+		subs := m.subscribers[sub.scopeID]
+		for i, s := range subs {
+			if s == sub {
+				m.subscribers[sub.scopeID] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(m.subscribers[sub.scopeID]) == 0 {
+			delete(m.subscribers, sub.scopeID)
+		}
+		sub.close()
+	}
+
+	return sub.ch, cancel, nil
+}
+
+// notifySubscribers sends a copy of state to every subscriber of the scope
+// without blocking.
+func (m *MultiScopeArchitecture) notifySubscribers(scopeID string, state map[string]interface{}) {
+	m.subsMu.Lock()
+	defer m.subsMu.Unlock()
+
+	for _, sub := range m.subscribers[scopeID] {
+		snapshot := make(map[string]interface{}, len(state))
+		for k, v := range state {
+			snapshot[k] = v
+		}
+		select {
+		case sub.ch <- snapshot:
+		default:
+		}
+	}
+}
+
+// closeSubscribers closes and forgets every subscriber of the scope.
+func (m *MultiScopeArchitecture) closeSubscribers(scopeID string) {
+	m.subsMu.Lock()
+	defer m.subsMu.Unlock()
+
+	for _, sub := range m.subscribers[scopeID] {
+		sub.close()
+	}
+	delete(m.subscribers, scopeID)
+}
+
+// Close closes every subscription channel, stops every anti-entropy loop and
+// refuses new subscriptions and loops. The default loopback transport is
+// closed as well, once the loops have stopped; a transport set with
+// WithTransport may be shared and is left for its owner to close. Calling
+// Close more than once has no further effect.
+func (m *MultiScopeArchitecture) Close(ctx context.Context) error {
+	const op = "hypermind.(MultiScopeArchitecture).Close"
+
+	m.subsMu.Lock()
+	for _, subs := range m.subscribers {
+		for _, sub := range subs {
+			sub.close()
+		}
+	}
+	clear(m.subscribers)
+	loops := make([]*antiEntropyLoop, 0, len(m.antiEntropyLoops))
+	for loop := range m.antiEntropyLoops {
+		loops = append(loops, loop)
+	}
+	m.subsClosed = true
+	m.subsMu.Unlock()
+
+	// stop without holding subsMu, a round in progress notifies subscribers
+	for _, loop := range loops {
+		loop.stop()
+	}
+
+	if m.ownsTransport {
+		if err := m.transport.Close(ctx); err != nil {
+			return errors.Wrap(ctx, err, op, errors.WithMsg("closing transport"))
+		}
+	}
+	return nil
+}
+
+// close closes the subscription channel exactly once.
+func (s *subscription) close() {
+	s.once.Do(func() { close(s.ch) })
+}
@@ -0,0 +1,324 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package hypermind
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/errors"
+)
+
+// Transport delivers scope state to remote peers during gossip propagation.
+type Transport interface {
+	// Dial prepares the transport to deliver to the peer identified by
+	// peerID. ConnectPeer dials every newly connected peer and fails if the
+	// dial fails.
+	Dial(ctx context.Context, peerID string) error
+
+	// Send delivers state for scopeID to the peer identified by peerID.
+	Send(ctx context.Context, peerID, scopeID string, state map[string]interface{}) error
+
+	// Close releases the transport's resources. Dial and Send fail once the
+	// transport is closed.
+	Close(ctx context.Context) error
+}
+
+// DeltaTransport is implemented by transports that can deliver partial state
+// updates. PropagateDelta uses SendDelta when the configured transport
+// supports it.
+type DeltaTransport interface {
+	Transport
+
+	// SendDelta delivers the changed keys and the names of the removed keys
+	// for scopeID to the peer identified by peerID.
+	SendDelta(ctx context.Context, peerID, scopeID string, changed map[string]interface{}, removed []string) error
+}
+
+// StateTransport is implemented by transports that can fetch the full state
+// a peer holds for a scope. SyncScope requires the configured transport to
+// support it.
+type StateTransport interface {
+	Transport
+
+	// FetchState requests the state the peer identified by peerID holds for
+	// scopeID.
+	FetchState(ctx context.Context, peerID, scopeID string) (ScopeSnapshot, error)
+}
+
+// ScopeSnapshot is the full state an architecture holds for a scope.
+type ScopeSnapshot struct {
+	// ScopeID is the scope the state belongs to
+	ScopeID string
+
+	// State is a copy of the scope's state
+	State map[string]interface{}
+
+	// UpdatedAt is when the scope's state last changed, by the clock of the
+	// architecture holding it
+	UpdatedAt time.Time
+
+	// Version is the scope's Version when the snapshot was taken
+	Version uint64
+}
+
+// Delivery records a single state delivery made through a LoopbackTransport.
+type Delivery struct {
+	// PeerID is the receiving peer
+	PeerID string
+
+	// ScopeID is the scope the state belongs to
+	ScopeID string
+
+	// State is the delivered state, or the changed keys of a delta
+	State map[string]interface{}
+
+	// Removed lists the keys removed by a delta
+	Removed []string
+}
+
+// DeliveryHandler receives the deliveries sent to a peer registered with a
+// LoopbackTransport. MultiScopeArchitecture.Receive is a DeliveryHandler.
+type DeliveryHandler func(ctx context.Context, d Delivery) error
+
+// StateHandler returns the state held for a scope by a peer registered with
+// a LoopbackTransport. MultiScopeArchitecture.Snapshot is a StateHandler.
+type StateHandler func(ctx context.Context, scopeID string) (ScopeSnapshot, error)
+
+// LoopbackTransport is an in-memory Transport that records every delivery
+// instead of sending it over the network. Deliveries to a peer registered
+// with Register are also handed to its handler, so architectures sharing a
+// loopback transport gossip to each other. State fetched from a peer
+// registered with RegisterState is read through its state handler. It is the
+// default transport.
+type LoopbackTransport struct {
+	deliveries []Delivery
+	handlers   map[string]*mailbox
+	states     map[string]StateHandler
+	closed     bool
+
+	mu sync.Mutex
+}
+
+// mailbox queues the deliveries to a registered peer and hands them to its
+// handler in order on a goroutine of its own, so a sender holding its own
+// locks never waits on the receiver's.
+type mailbox struct {
+	handler DeliveryHandler
+	queue   []Delivery
+	wake    chan struct{}
+	done    chan struct{}
+}
+
+// NewLoopbackTransport creates an empty loopback transport.
+func NewLoopbackTransport() *LoopbackTransport {
+	return &LoopbackTransport{
+		deliveries: make([]Delivery, 0),
+		handlers:   make(map[string]*mailbox),
+		states:     make(map[string]StateHandler),
+	}
+}
+
+// Register routes the deliveries sent to peerID to h, replacing any handler
+// already registered for the peer. Deliveries are handed to h
+// asynchronously, one at a time and in the order they were sent; errors
+// returned by h are discarded.
+func (t *LoopbackTransport) Register(ctx context.Context, peerID string, h DeliveryHandler) error {
+	const op = "hypermind.(LoopbackTransport).Register"
+
+	if peerID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "peer ID is empty")
+	}
+	if h == nil {
+		return errors.New(ctx, errors.InvalidParameter, op, "handler is nil")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return errors.New(ctx, errors.Closed, op, "transport is closed")
+	}
+	if mb, ok := t.handlers[peerID]; ok {
+		close(mb.done)
+	}
+	mb := &mailbox{
+		handler: h,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	t.handlers[peerID] = mb
+	go t.deliver(context.WithoutCancel(ctx), mb)
+	return nil
+}
+
+// RegisterState makes FetchState for peerID read through h, replacing any
+// state handler already registered for the peer.
+func (t *LoopbackTransport) RegisterState(ctx context.Context, peerID string, h StateHandler) error {
+	const op = "hypermind.(LoopbackTransport).RegisterState"
+
+	if peerID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "peer ID is empty")
+	}
+	if h == nil {
+		return errors.New(ctx, errors.InvalidParameter, op, "handler is nil")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return errors.New(ctx, errors.Closed, op, "transport is closed")
+	}
+	t.states[peerID] = h
+	return nil
+}
+
+// Unregister stops routing deliveries to peerID's handler and removes its
+// state handler. Deliveries still queued for it are dropped.
+func (t *LoopbackTransport) Unregister(peerID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if mb, ok := t.handlers[peerID]; ok {
+		close(mb.done)
+		delete(t.handlers, peerID)
+	}
+	delete(t.states, peerID)
+}
+
+// deliver hands mb's queued deliveries to its handler until mb is done.
+func (t *LoopbackTransport) deliver(ctx context.Context, mb *mailbox) {
+	for {
+		select {
+		case <-mb.done:
+			return
+		case <-mb.wake:
+		}
+		t.mu.Lock()
+		queue := mb.queue
+		mb.queue = nil
+		t.mu.Unlock()
+		for _, d := range queue {
+			select {
+			case <-mb.done:
+				return
+			default:
+			}
+			_ = mb.handler(ctx, d)
+		}
+	}
+}
+
+// Dial succeeds for any peer until the transport is closed. Sends to a
+// peer without a registered handler are only recorded.
+func (t *LoopbackTransport) Dial(ctx context.Context, peerID string) error {
+	const op = "hypermind.(LoopbackTransport).Dial"
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return errors.New(ctx, errors.Closed, op, fmt.Sprintf("transport is closed: cannot dial peer %s", peerID))
+	}
+	return nil
+}
+
+// Send records the delivery and queues it for the peer's handler, if one
+// is registered. It only fails once the transport is closed.
+func (t *LoopbackTransport) Send(ctx context.Context, peerID, scopeID string, state map[string]interface{}) error {
+	const op = "hypermind.(LoopbackTransport).Send"
+
+	if !t.record(Delivery{PeerID: peerID, ScopeID: scopeID, State: state}) {
+		return errors.New(ctx, errors.Closed, op, "transport is closed")
+	}
+	return nil
+}
+
+// SendDelta records the delta delivery and queues it for the peer's
+// handler, if one is registered. It only fails once the transport is
+// closed.
+func (t *LoopbackTransport) SendDelta(ctx context.Context, peerID, scopeID string, changed map[string]interface{}, removed []string) error {
+	const op = "hypermind.(LoopbackTransport).SendDelta"
+
+	if !t.record(Delivery{PeerID: peerID, ScopeID: scopeID, State: changed, Removed: removed}) {
+		return errors.New(ctx, errors.Closed, op, "transport is closed")
+	}
+	return nil
+}
+
+// FetchState reads the peer's state for scopeID through its state handler.
+// The handler is called without holding the transport's lock. It fails if
+// the peer has no state handler or the transport is closed.
+func (t *LoopbackTransport) FetchState(ctx context.Context, peerID, scopeID string) (ScopeSnapshot, error) {
+	const op = "hypermind.(LoopbackTransport).FetchState"
+
+	t.mu.Lock()
+	closed := t.closed
+	h, ok := t.states[peerID]
+	t.mu.Unlock()
+
+	switch {
+	case closed:
+		return ScopeSnapshot{}, errors.New(ctx, errors.Closed, op, "transport is closed")
+	case !ok:
+		return ScopeSnapshot{}, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("peer %s has no state handler", peerID))
+	}
+	snap, err := h(ctx, scopeID)
+	if err != nil {
+		return ScopeSnapshot{}, errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("fetching state from peer %s", peerID)))
+	}
+	return snap, nil
+}
+
+// record appends d to the recorded deliveries and to the mailbox of its
+// peer's handler. It reports false if the transport is closed.
+func (t *LoopbackTransport) record(d Delivery) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return false
+	}
+	t.deliveries = append(t.deliveries, d)
+	if mb, ok := t.handlers[d.PeerID]; ok {
+		mb.queue = append(mb.queue, d)
+		select {
+		case mb.wake <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+// Close stops every handler and makes later Dial, Send, FetchState and
+// Register calls fail. The recorded deliveries stay readable. Calling Close more than once
+// has no further effect.
+func (t *LoopbackTransport) Close(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil
+	}
+	for peerID, mb := range t.handlers {
+		close(mb.done)
+		delete(t.handlers, peerID)
+	}
+	clear(t.states)
+	t.closed = true
+	return nil
+}
+
+// Deliveries returns the deliveries recorded so far, oldest first.
+func (t *LoopbackTransport) Deliveries() []Delivery {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	deliveries := make([]Delivery, len(t.deliveries))
+	copy(deliveries, t.deliveries)
+	return deliveries
+}
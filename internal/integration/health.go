@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package integration
+
+import (
+	"context"
+
+	"github.com/hashicorp/boundary/internal/hypermind"
+)
+
+// HealthReport summarizes the state of the three frameworks.
+type HealthReport struct {
+	// Healthy is set when the framework is open, the consistency check
+	// completed and it found no inconsistencies
+	Healthy bool
+
+	// Closed is set once Close has been called
+	Closed bool
+
+	// TensorLogic reports the tensor logic framework
+	TensorLogic TensorLogicHealth
+
+	// Hypermind reports the multi-scope architecture
+	Hypermind HypermindHealth
+
+	// ATenSpace reports the space
+	ATenSpace ATenSpaceHealth
+
+	// Inconsistencies are the discrepancies found by VerifyConsistency
+	Inconsistencies []Inconsistency
+
+	// Err is set if the consistency check failed
+	Err error
+}
+
+// TensorLogicHealth reports the size of the tensor logic framework.
+type TensorLogicHealth struct {
+	// Variables is the number of registered variables
+	Variables int
+
+	// Equations is the number of defined equations
+	Equations int
+}
+
+// HypermindHealth reports the scopes and peers of the multi-scope
+// architecture.
+type HypermindHealth struct {
+	// Scopes is the number of registered scopes
+	Scopes int
+
+	// HealthyPeers, SuspectPeers and DeadPeers count the active peers by
+	// how recently they were seen
+	HealthyPeers int
+	SuspectPeers int
+	DeadPeers    int
+}
+
+// ATenSpaceHealth reports the size of the space.
+type ATenSpaceHealth struct {
+	// Atoms, Links and Boundaries count the contents of the space
+	Atoms      int
+	Links      int
+	Boundaries int
+}
+
+// Health reports the size of each framework, the health of the Hypermind
+// peers and the inconsistencies between the frameworks found by
+// VerifyConsistency. It can be called after Close.
+func (u *UnifiedFramework) Health(ctx context.Context) HealthReport {
+	report := HealthReport{
+		Closed: u.closed.Load(),
+		TensorLogic: TensorLogicHealth{
+			Variables: len(u.TensorLogic.ListVariables(ctx)),
+			Equations: len(u.TensorLogic.ListEquations(ctx)),
+		},
+		Hypermind: HypermindHealth{
+			Scopes: len(u.Hypermind.ListScopes(ctx)),
+		},
+	}
+
+	for _, peer := range u.Hypermind.GetActivePeers(ctx) {
+		switch peer.Health {
+		case hypermind.Healthy:
+			report.Hypermind.HealthyPeers++
+		case hypermind.Suspect:
+			report.Hypermind.SuspectPeers++
+		case hypermind.Dead:
+			report.Hypermind.DeadPeers++
+		}
+	}
+
+	stats := u.ATenSpace.Stats(ctx)
+	report.ATenSpace = ATenSpaceHealth{
+		Atoms:      stats.Atoms,
+		Links:      stats.Links,
+		Boundaries: stats.Boundaries,
+	}
+
+	report.Inconsistencies, report.Err = u.VerifyConsistency(ctx)
+	report.Healthy = !report.Closed && report.Err == nil && len(report.Inconsistencies) == 0
+	return report
+}
@@ -12,11 +12,20 @@ package integration
 
 import (
 	"context"
+	stderrors "errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/hashicorp/boundary/internal/atenspace"
 	"github.com/hashicorp/boundary/internal/errors"
 	"github.com/hashicorp/boundary/internal/hypermind"
 	"github.com/hashicorp/boundary/internal/tensorlogic"
+	"github.com/hashicorp/boundary/internal/types/scope"
 )
 
 // UnifiedFramework integrates all three frameworks into a cohesive system.
@@ -29,12 +38,21 @@ type UnifiedFramework struct {
 
 	// ATenSpace provides the Space defined by Boundary domain model
 	ATenSpace *atenspace.Space
+
+	// closed is set by Close; mutating calls fail once it is set
+	closed atomic.Bool
+
+	// metrics receives counters and latencies
+	metrics Metrics
 }
 
 // NewUnifiedFramework creates a new integrated framework instance.
-func NewUnifiedFramework(ctx context.Context) (*UnifiedFramework, error) {
+// Supported options: WithMetrics
+func NewUnifiedFramework(ctx context.Context, opt ...Option) (*UnifiedFramework, error) {
 	const op = "integration.NewUnifiedFramework"
 
+	opts := getOpts(opt...)
+
 	// Initialize Tensor Logic framework
 	tl, err := tensorlogic.NewFramework(ctx)
 	if err != nil {
@@ -57,11 +75,55 @@ func NewUnifiedFramework(ctx context.Context) (*UnifiedFramework, error) {
 		TensorLogic: tl,
 		Hypermind:   hm,
 		ATenSpace:   as,
+		metrics:     opts.withMetrics,
 	}
 
 	return uf, nil
 }
 
+// Close releases the framework's resources: Hypermind stops accepting
+// subscriptions and closes every subscription channel. Afterwards every
+// call that modifies the framework fails with a Closed error. Calling Close
+// more than once has no further effect.
+func (u *UnifiedFramework) Close(ctx context.Context) error {
+	const op = "integration.(UnifiedFramework).Close"
+
+	if !u.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	if err := u.Hypermind.Close(ctx); err != nil {
+		return errors.Wrap(ctx, err, op)
+	}
+	return nil
+}
+
+// ConnectPeer connects a peer to the Hypermind P2P network.
+func (u *UnifiedFramework) ConnectPeer(ctx context.Context, peer *hypermind.Peer) error {
+	const op = "integration.(UnifiedFramework).ConnectPeer"
+
+	if err := u.checkOpen(ctx, op); err != nil {
+		return err
+	}
+	if err := u.Hypermind.ConnectPeer(ctx, peer); err != nil {
+		return errors.Wrap(ctx, err, op)
+	}
+	u.metrics.IncrCounter(MetricPeerConnects, 1)
+	return nil
+}
+
+// observeLatency reports the time elapsed since start under name.
+func (u *UnifiedFramework) observeLatency(name string, start time.Time) {
+	u.metrics.ObserveLatency(name, time.Since(start))
+}
+
+// checkOpen returns a Closed error once Close has been called.
+func (u *UnifiedFramework) checkOpen(ctx context.Context, op errors.Op) error {
+	if u.closed.Load() {
+		return errors.New(ctx, errors.Closed, op, "framework is closed")
+	}
+	return nil
+}
+
 // IntegrateWithBoundary integrates all three frameworks with Boundary's domain model.
 // This is the key integration point where all frameworks work together:
 // 1. Tensor Logic: All Boundary variables use tensor equations
@@ -70,6 +132,10 @@ func NewUnifiedFramework(ctx context.Context) (*UnifiedFramework, error) {
 func (u *UnifiedFramework) IntegrateWithBoundary(ctx context.Context) error {
 	const op = "integration.(UnifiedFramework).IntegrateWithBoundary"
 
+	if err := u.checkOpen(ctx, op); err != nil {
+		return err
+	}
+
 	// Integrate Tensor Logic with Boundary variables
 	if err := u.TensorLogic.IntegrateWithBoundary(ctx); err != nil {
 		return errors.Wrap(ctx, err, op, errors.WithMsg("tensor logic integration failed"))
@@ -93,9 +159,86 @@ func (u *UnifiedFramework) IntegrateWithBoundary(ctx context.Context) error {
 // - The scope is represented as a tensor variable (Tensor Logic)
 // - The scope participates in P2P network (Hypermind)
 // - The scope is an atom in the Space (ATenSpace)
+//
+// The scope is created in all three frameworks or in none: if a step fails,
+// the steps already applied are rolled back and the error names the step
+// that failed. scopeType must be "global", "org" or "project".
 func (u *UnifiedFramework) CreateBoundaryScope(ctx context.Context, scopeID, scopeType string) error {
 	const op = "integration.(UnifiedFramework).CreateBoundaryScope"
 
+	if err := u.checkOpen(ctx, op); err != nil {
+		return err
+	}
+
+	if err := u.createBoundaryScope(ctx, scopeID, scopeType, ""); err != nil {
+		return errors.Wrap(ctx, err, op)
+	}
+	u.metrics.IncrCounter(MetricScopesCreated, 1)
+	return nil
+}
+
+// CreateBoundaryScopeUnder creates a scope like CreateBoundaryScope as a
+// child of parentID: the distributed scope's ParentID is set and a
+// ScopeLink is added from the parent atom, which must already exist, to the
+// new atom. A global scope cannot have a parent, and the parent of a project
+// must be an org or project scope.
+func (u *UnifiedFramework) CreateBoundaryScopeUnder(ctx context.Context, scopeID, scopeType, parentID string) error {
+	const op = "integration.(UnifiedFramework).CreateBoundaryScopeUnder"
+
+	if err := u.checkOpen(ctx, op); err != nil {
+		return err
+	}
+
+	if parentID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "parent ID is empty")
+	}
+	if err := u.createBoundaryScope(ctx, scopeID, scopeType, parentID); err != nil {
+		return errors.Wrap(ctx, err, op)
+	}
+	u.metrics.IncrCounter(MetricScopesCreated, 1)
+	return nil
+}
+
+// createBoundaryScope creates a scope in all three frameworks, rolling back
+// on failure. When parentID is set the distributed scope gets that ParentID
+// and a ScopeLink is added from the parent atom, which must exist, to the
+// new atom.
+func (u *UnifiedFramework) createBoundaryScope(ctx context.Context, scopeID, scopeType, parentID string) (retErr error) {
+	const op = "integration.(UnifiedFramework).createBoundaryScope"
+
+	typ, err := parseScopeType(ctx, scopeType)
+	if err != nil {
+		return errors.Wrap(ctx, err, op)
+	}
+	if parentID != "" {
+		if typ == scope.Global {
+			return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("global scope %s cannot have a parent", scopeID))
+		}
+		if _, err := u.ATenSpace.GetAtomCopy(ctx, parentID); err != nil {
+			return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("parent atom %s not found", parentID))
+		}
+		if typ == scope.Project {
+			parent, err := u.Hypermind.GetScope(ctx, parentID)
+			if err != nil || (parent.Type != scope.Org.String() && parent.Type != scope.Project.String()) {
+				return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("parent %s of project scope %s is not an org or project scope", parentID, scopeID))
+			}
+		}
+	}
+
+	// rollback holds the undo functions of the applied steps, run in
+	// reverse order if a later step fails
+	var rollback []func() error
+	defer func() {
+		if retErr == nil {
+			return
+		}
+		for i := len(rollback) - 1; i >= 0; i-- {
+			if err := rollback[i](); err != nil {
+				retErr = stderrors.Join(retErr, errors.Wrap(ctx, err, op, errors.WithMsg("rolling back")))
+			}
+		}
+	}()
+
 	// Create tensor variable for the scope (Tensor Logic)
 	scopeVar := &tensorlogic.Variable{
 		Name:    scopeID,
@@ -103,81 +246,660 @@ func (u *UnifiedFramework) CreateBoundaryScope(ctx context.Context, scopeID, sco
 		Type:    tensorlogic.HybridType,
 	}
 	if err := u.TensorLogic.RegisterVariable(ctx, scopeVar); err != nil {
-		return errors.Wrap(ctx, err, op)
+		return errors.Wrap(ctx, err, op, errors.WithMsg("registering tensor variable"))
 	}
+	rollback = append(rollback, func() error { return u.TensorLogic.DeleteVariable(ctx, scopeID) })
 
 	// Create distributed scope (Hypermind)
 	distScope := &hypermind.DistributedScope{
-		ID:   scopeID,
-		Type: scopeType,
+		ID:       scopeID,
+		Type:     scopeType,
+		ParentID: parentID,
 	}
 	if err := u.Hypermind.RegisterScope(ctx, distScope); err != nil {
-		return errors.Wrap(ctx, err, op)
+		return errors.Wrap(ctx, err, op, errors.WithMsg("registering distributed scope"))
 	}
+	rollback = append(rollback, func() error { return u.Hypermind.RemoveScope(ctx, scopeID, false) })
 
-	// Create atom in Space (ATenSpace)
+	// Create atom in Space (ATenSpace). AddAtom refuses to replace an
+	// existing atom, which belongs to someone else and so is not removed on
+	// rollback.
 	atom := &atenspace.Atom{
 		ID:   scopeID,
 		Type: atenspace.AggregateAtom,
 		Name: scopeID,
 	}
 	if err := u.ATenSpace.AddAtom(ctx, atom); err != nil {
-		return errors.Wrap(ctx, err, op)
+		return errors.Wrap(ctx, err, op, errors.WithMsg("adding atom"))
 	}
+	rollback = append(rollback, func() error { return u.ATenSpace.RemoveAtom(ctx, scopeID) })
 
 	// Attach tensor to atom
 	tensor := &atenspace.Tensor{
-		ID:     scopeID + "_tensor",
+		ID:     scopeTensorID(scopeID),
 		Shape:  []int{10, 10},
 		Data:   make([]float64, 100),
 		DType:  "float64",
 		Device: "cpu",
 	}
 	if err := u.ATenSpace.AttachTensor(ctx, scopeID, tensor); err != nil {
-		return errors.Wrap(ctx, err, op)
+		return errors.Wrap(ctx, err, op, errors.WithMsg("attaching tensor"))
+	}
+
+	// Link the atom to its parent; removing the atom on rollback removes
+	// the link too
+	if parentID != "" {
+		link := &atenspace.Link{
+			ID:       scopeLinkID(parentID, scopeID),
+			Type:     atenspace.ScopeLink,
+			Source:   parentID,
+			Target:   scopeID,
+			Strength: 1.0,
+		}
+		if err := u.ATenSpace.AddLink(ctx, link); err != nil {
+			return errors.Wrap(ctx, err, op, errors.WithMsg("linking to parent"))
+		}
+	}
+
+	return nil
+}
+
+// parseScopeType returns the Boundary scope type named by scopeType:
+// "global", "org" or "project".
+func parseScopeType(ctx context.Context, scopeType string) (scope.Type, error) {
+	const op = "integration.parseScopeType"
+
+	typ, ok := scope.Map[scopeType]
+	if !ok {
+		return scope.Unknown, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("unknown scope type %q", scopeType))
+	}
+	return typ, nil
+}
+
+// scopeLinkID returns the ID of the ScopeLink from a parent scope's atom to
+// a child scope's atom.
+func scopeLinkID(parentID, scopeID string) string {
+	return parentID + "_scope_" + scopeID
+}
+
+// scopeTensorID returns the ID of the tensor attached to a scope's atom.
+func scopeTensorID(scopeID string) string {
+	return scopeID + "_tensor"
+}
+
+// ScopeSpec describes a scope to create with BulkCreateBoundaryScopes.
+type ScopeSpec struct {
+	// ID is the scope identifier
+	ID string
+
+	// Type is the scope type: global, org or project
+	Type string
+
+	// ParentID is the parent scope, either another spec or an existing
+	// scope; empty for a root scope
+	ParentID string
+}
+
+// BulkCreateBoundaryScopes creates every scope in specs in all three
+// frameworks. A scope with a ParentID gets that parent in Hypermind and a
+// ScopeLink from the parent atom in ATenSpace. Parents are created before
+// their children whatever order specs are given in. If the specs contain
+// duplicate IDs or a parent cycle nothing is created; if creating a scope
+// fails, the scopes already created are removed again.
+func (u *UnifiedFramework) BulkCreateBoundaryScopes(ctx context.Context, specs []ScopeSpec) (retErr error) {
+	const op = "integration.(UnifiedFramework).BulkCreateBoundaryScopes"
+
+	if err := u.checkOpen(ctx, op); err != nil {
+		return err
+	}
+
+	byID := make(map[string]ScopeSpec, len(specs))
+	for _, spec := range specs {
+		if spec.ID == "" {
+			return errors.New(ctx, errors.InvalidParameter, op, "scope ID is empty")
+		}
+		if _, dup := byID[spec.ID]; dup {
+			return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("duplicate scope %s", spec.ID))
+		}
+		if _, err := parseScopeType(ctx, spec.Type); err != nil {
+			return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("scope %s", spec.ID)))
+		}
+		byID[spec.ID] = spec
+	}
+
+	// Order the specs so that every parent within specs precedes its
+	// children; a spec still on the stack when revisited closes a cycle.
+	ordered := make([]ScopeSpec, 0, len(specs))
+	state := make(map[string]int, len(specs)) // 1 visiting, 2 done
+	var visit func(spec ScopeSpec) error
+	visit = func(spec ScopeSpec) error {
+		switch state[spec.ID] {
+		case 1:
+			return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("scope %s is its own ancestor", spec.ID))
+		case 2:
+			return nil
+		}
+		state[spec.ID] = 1
+		if parent, ok := byID[spec.ParentID]; ok {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		state[spec.ID] = 2
+		ordered = append(ordered, spec)
+		return nil
+	}
+	for _, spec := range specs {
+		if err := visit(spec); err != nil {
+			return err
+		}
+	}
+
+	created := make([]string, 0, len(ordered))
+	defer func() {
+		if retErr == nil {
+			return
+		}
+		for i := len(created) - 1; i >= 0; i-- {
+			if err := u.removeBoundaryScope(ctx, created[i]); err != nil {
+				retErr = stderrors.Join(retErr, errors.Wrap(ctx, err, op, errors.WithMsg("rolling back")))
+			}
+		}
+	}()
+	for _, spec := range ordered {
+		if err := u.createBoundaryScope(ctx, spec.ID, spec.Type, spec.ParentID); err != nil {
+			return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("creating scope %s", spec.ID)))
+		}
+		created = append(created, spec.ID)
+	}
+
+	u.metrics.IncrCounter(MetricScopesCreated, int64(len(created)))
+	return nil
+}
+
+// RemoveBoundaryScope removes a scope created by CreateBoundaryScope from
+// all three frameworks: the tensor variable, the distributed scope, and the
+// atom together with its tensor. Every removal is attempted even if an
+// earlier one fails, and all failures are reported together.
+func (u *UnifiedFramework) RemoveBoundaryScope(ctx context.Context, scopeID string) error {
+	const op = "integration.(UnifiedFramework).RemoveBoundaryScope"
+
+	if err := u.checkOpen(ctx, op); err != nil {
+		return err
+	}
+	return u.removeBoundaryScope(ctx, scopeID)
+}
+
+// removeBoundaryScope removes a scope from all three frameworks, attempting
+// every removal and joining the failures.
+func (u *UnifiedFramework) removeBoundaryScope(ctx context.Context, scopeID string) error {
+	const op = "integration.(UnifiedFramework).removeBoundaryScope"
+
+	var retErr error
+	if err := u.TensorLogic.DeleteVariable(ctx, scopeID); err != nil {
+		retErr = stderrors.Join(retErr, errors.Wrap(ctx, err, op, errors.WithMsg("removing tensor variable")))
+	}
+	if err := u.Hypermind.RemoveScope(ctx, scopeID, false); err != nil {
+		retErr = stderrors.Join(retErr, errors.Wrap(ctx, err, op, errors.WithMsg("removing distributed scope")))
+	}
+	if err := u.ATenSpace.RemoveAtom(ctx, scopeID); err != nil {
+		retErr = stderrors.Join(retErr, errors.Wrap(ctx, err, op, errors.WithMsg("removing atom")))
+	}
+	return retErr
+}
+
+// RenameScope changes the ID of a scope created by CreateBoundaryScope from
+// oldID to newID in all three frameworks: the tensor variable, the
+// distributed scope together with its children's ParentID, and the atom
+// together with the links and boundaries referencing it. The ScopeLinks to
+// its parent and children, the MembershipLinks to its peers and the scope's
+// tensor are renamed to match. newID must not exist in any framework; if a
+// rename fails, the renames already made are undone.
+func (u *UnifiedFramework) RenameScope(ctx context.Context, oldID, newID string) (retErr error) {
+	const op = "integration.(UnifiedFramework).RenameScope"
+
+	if err := u.checkOpen(ctx, op); err != nil {
+		return err
+	}
+	if oldID == "" || newID == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "scope ID is empty")
+	}
+	if oldID == newID {
+		return errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("scope %s cannot be renamed to itself", oldID))
+	}
+
+	// Check every framework up front so that a conflict leaves all three
+	// untouched
+	if _, err := u.TensorLogic.GetVariable(ctx, oldID); err != nil {
+		return errors.Wrap(ctx, err, op, errors.WithMsg("looking up tensor variable"))
+	}
+	if _, err := u.Hypermind.GetScope(ctx, oldID); err != nil {
+		return errors.Wrap(ctx, err, op, errors.WithMsg("looking up distributed scope"))
+	}
+	atom, err := u.ATenSpace.GetAtomCopy(ctx, oldID)
+	if err != nil {
+		return errors.Wrap(ctx, err, op, errors.WithMsg("looking up atom"))
+	}
+	if _, err := u.TensorLogic.GetVariable(ctx, newID); err == nil {
+		return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("scope %s already exists in %s", newID, TensorLogicFramework))
+	}
+	if _, err := u.Hypermind.GetScope(ctx, newID); err == nil {
+		return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("scope %s already exists in %s", newID, HypermindFramework))
+	}
+	if _, err := u.ATenSpace.GetAtomCopy(ctx, newID); err == nil {
+		return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("scope %s already exists in %s", newID, ATenSpaceFramework))
+	}
+
+	// The scope's tensor carries the scope ID in its own ID
+	renameTensor := atom.TensorID != "" && atom.TensorID == scopeTensorID(oldID)
+	if renameTensor {
+		if _, err := u.ATenSpace.GetTensorByID(ctx, scopeTensorID(newID)); err == nil {
+			return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("tensor %s already exists", scopeTensorID(newID)))
+		}
+	}
+
+	// The ScopeLinks to the parent and children and the MembershipLinks to
+	// the peers carry the scope ID in their own ID
+	renamedLinks := make(map[string]string)
+	for _, link := range u.ATenSpace.GetLinksForAtom(ctx, oldID) {
+		var newLinkID string
+		switch link.Type {
+		case atenspace.ScopeLink:
+			switch {
+			case link.Target == oldID && link.ID == scopeLinkID(link.Source, oldID):
+				newLinkID = scopeLinkID(link.Source, newID)
+			case link.Source == oldID && link.ID == scopeLinkID(oldID, link.Target):
+				newLinkID = scopeLinkID(newID, link.Target)
+			default:
+				continue
+			}
+		case atenspace.MembershipLink:
+			peerID, ok := strings.CutPrefix(link.Target, peerAtomPrefix)
+			if !ok || link.Source != oldID || link.ID != membershipLinkID(oldID, peerID) {
+				continue
+			}
+			newLinkID = membershipLinkID(newID, peerID)
+		default:
+			continue
+		}
+		if _, err := u.ATenSpace.GetLink(ctx, newLinkID); err == nil {
+			return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("link %s already exists", newLinkID))
+		}
+		renamedLinks[link.ID] = newLinkID
+	}
+
+	// rollback holds the undo functions of the applied steps, run in
+	// reverse order if a later step fails
+	var rollback []func() error
+	defer func() {
+		if retErr == nil {
+			return
+		}
+		for i := len(rollback) - 1; i >= 0; i-- {
+			if err := rollback[i](); err != nil {
+				retErr = stderrors.Join(retErr, errors.Wrap(ctx, err, op, errors.WithMsg("rolling back")))
+			}
+		}
+	}()
+
+	if err := u.TensorLogic.RenameVariable(ctx, oldID, newID); err != nil {
+		return errors.Wrap(ctx, err, op, errors.WithMsg("renaming tensor variable"))
+	}
+	rollback = append(rollback, func() error { return u.TensorLogic.RenameVariable(ctx, newID, oldID) })
+
+	if err := u.Hypermind.RenameScope(ctx, oldID, newID); err != nil {
+		return errors.Wrap(ctx, err, op, errors.WithMsg("renaming distributed scope"))
+	}
+	rollback = append(rollback, func() error { return u.Hypermind.RenameScope(ctx, newID, oldID) })
+
+	if err := u.ATenSpace.RenameAtom(ctx, oldID, newID); err != nil {
+		return errors.Wrap(ctx, err, op, errors.WithMsg("renaming atom"))
+	}
+	rollback = append(rollback, func() error { return u.ATenSpace.RenameAtom(ctx, newID, oldID) })
+
+	if renameTensor {
+		if err := u.ATenSpace.RenameTensor(ctx, scopeTensorID(oldID), scopeTensorID(newID)); err != nil {
+			return errors.Wrap(ctx, err, op, errors.WithMsg("renaming tensor"))
+		}
+		rollback = append(rollback, func() error {
+			return u.ATenSpace.RenameTensor(ctx, scopeTensorID(newID), scopeTensorID(oldID))
+		})
+	}
+
+	for oldLinkID, newLinkID := range renamedLinks {
+		if err := u.ATenSpace.RenameLink(ctx, oldLinkID, newLinkID); err != nil {
+			return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("renaming link %s", oldLinkID)))
+		}
+		rollback = append(rollback, func() error { return u.ATenSpace.RenameLink(ctx, newLinkID, oldLinkID) })
 	}
 
 	return nil
 }
 
 // QueryScope demonstrates querying across all three frameworks.
-func (u *UnifiedFramework) QueryScope(ctx context.Context, scopeID string) (*ScopeInfo, error) {
+// Supported options: WithEffectiveState
+func (u *UnifiedFramework) QueryScope(ctx context.Context, scopeID string, opt ...Option) (*ScopeInfo, error) {
 	const op = "integration.(UnifiedFramework).QueryScope"
 
+	defer u.observeLatency(MetricQueryLatency, time.Now())
+
+	opts := getOpts(opt...)
+
 	info := &ScopeInfo{
 		ID: scopeID,
 	}
 
+	// A framework that does not know the scope leaves its field nil; any
+	// other failure is returned.
+
 	// Get tensor representation (Tensor Logic)
-	if tensorVar, err := u.TensorLogic.Evaluate(ctx, scopeID); err == nil {
+	tensorVar, err := u.TensorLogic.Evaluate(ctx, scopeID)
+	switch {
+	case err == nil:
 		info.TensorVariable = tensorVar
+	case !isNotFound(err):
+		return nil, errors.Wrap(ctx, err, op)
 	}
 
 	// Get distributed scope info (Hypermind)
-	if distScope, err := u.Hypermind.GetScope(ctx, scopeID); err == nil {
+	distScope, err := u.Hypermind.GetScope(ctx, scopeID)
+	switch {
+	case err == nil:
 		info.DistributedScope = distScope
+	case !isNotFound(err):
+		return nil, errors.Wrap(ctx, err, op)
 	}
 
 	// Get atom representation (ATenSpace)
-	if atom, err := u.ATenSpace.GetAtom(ctx, scopeID); err == nil {
+	atom, err := u.ATenSpace.GetAtomCopy(ctx, scopeID)
+	switch {
+	case err == nil:
 		info.Atom = atom
+	case !isNotFound(err):
+		return nil, errors.Wrap(ctx, err, op)
+	}
+
+	// Get boundary memberships (ATenSpace)
+	if boundaries := u.ATenSpace.FindBoundariesForAtom(ctx, scopeID); len(boundaries) > 0 {
+		info.Boundaries = boundaries
+	}
+
+	if opts.withEffectiveState && info.DistributedScope != nil {
+		effective, err := u.effectiveState(ctx, scopeID)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, op)
+		}
+		info.EffectiveState = effective
 	}
 
 	return info, nil
 }
 
+// effectiveState merges the Hypermind state of scopeID's ancestors, root
+// first, with the scope's own state, so that each scope's keys override
+// those of its ancestors. A cycle in the ancestry is an error.
+func (u *UnifiedFramework) effectiveState(ctx context.Context, scopeID string) (map[string]interface{}, error) {
+	const op = "integration.(UnifiedFramework).effectiveState"
+
+	ancestors, err := u.Hypermind.GetAncestors(ctx, scopeID)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, op, errors.WithMsg("walking ancestors"))
+	}
+	chain := make([]string, 0, len(ancestors)+1)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		chain = append(chain, ancestors[i].ID)
+	}
+	chain = append(chain, scopeID)
+
+	effective := make(map[string]interface{})
+	for _, id := range chain {
+		state, err := u.Hypermind.GetScopeState(ctx, id)
+		switch {
+		case err == nil:
+			maps.Copy(effective, state)
+		case !isNotFound(err):
+			return nil, errors.Wrap(ctx, err, op)
+		}
+		// a scope removed since the walk contributes nothing
+	}
+	return effective, nil
+}
+
+// isNotFound reports whether err is a NotFound error from one of the
+// frameworks.
+func isNotFound(err error) bool {
+	return errors.Match(errors.T(errors.NotFound), err)
+}
+
+// ComputeAccessMatrix contracts a users-by-roles tensor with a
+// roles-by-permissions tensor into a users-by-permissions access matrix and
+// attaches it to the scope's atom, replacing the scope's tensor. Both
+// operands must be rank-2 and usersRoles' second index must be
+// rolesPerms' first index.
+func (u *UnifiedFramework) ComputeAccessMatrix(ctx context.Context, scopeID string, usersRoles, rolesPerms *tensorlogic.Variable) (*tensorlogic.Variable, error) {
+	const op = "integration.(UnifiedFramework).ComputeAccessMatrix"
+
+	if err := u.checkOpen(ctx, op); err != nil {
+		return nil, err
+	}
+	if usersRoles == nil || rolesPerms == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "one or both variables are nil")
+	}
+	for _, v := range []*tensorlogic.Variable{usersRoles, rolesPerms} {
+		if len(v.Indices) != 2 || len(v.Shape) != 2 {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("variable %s is not rank-2", v.Name))
+		}
+	}
+	if usersRoles.Indices[1] != rolesPerms.Indices[0] {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("roles index %s of %s does not match index %s of %s", usersRoles.Indices[1], usersRoles.Name, rolesPerms.Indices[0], rolesPerms.Name))
+	}
+	if usersRoles.Indices[0] == rolesPerms.Indices[1] {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("both variables use index %s", usersRoles.Indices[0]))
+	}
+
+	access, err := u.TensorLogic.Join(ctx, usersRoles, rolesPerms)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, op)
+	}
+	if access.Data == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "variables have no data")
+	}
+
+	tensor := &atenspace.Tensor{
+		ID:     scopeTensorID(scopeID),
+		Shape:  slices.Clone(access.Shape),
+		Data:   slices.Clone(access.Data),
+		DType:  "float64",
+		Device: "cpu",
+	}
+	if err := u.ATenSpace.AttachTensor(ctx, scopeID, tensor); err != nil {
+		return nil, errors.Wrap(ctx, err, op)
+	}
+
+	return access, nil
+}
+
 // ScopeInfo aggregates information from all three frameworks.
 type ScopeInfo struct {
 	ID               string
 	TensorVariable   *tensorlogic.Variable
 	DistributedScope *hypermind.DistributedScope
 	Atom             *atenspace.Atom
+
+	// Boundaries are the domain boundaries whose AtomIDs contain the scope;
+	// it is only set by QueryScope
+	Boundaries []*atenspace.DomainBoundary
+
+	// MissingFrom names the frameworks that do not know the scope; it is
+	// only set by ListScopes and is empty when the frameworks agree
+	MissingFrom []string
+
+	// EffectiveState is the scope's Hypermind state merged over that of
+	// its ancestors, nearer scopes overriding farther ones; it is only set
+	// by QueryScope with WithEffectiveState
+	EffectiveState map[string]interface{}
+}
+
+// Framework names reported in ScopeInfo.MissingFrom.
+const (
+	TensorLogicFramework = "tensorlogic"
+	HypermindFramework   = "hypermind"
+	ATenSpaceFramework   = "atenspace"
+)
+
+// ListScopes returns one ScopeInfo per scope known to any of the three
+// frameworks, sorted by ID. Scope IDs are gathered from every tensor
+// variable, every distributed scope and every aggregate atom, the atom type
+// CreateBoundaryScope uses. A scope missing from some frameworks has those
+// frameworks listed in MissingFrom.
+func (u *UnifiedFramework) ListScopes(ctx context.Context) ([]*ScopeInfo, error) {
+	const op = "integration.(UnifiedFramework).ListScopes"
+
+	defer u.observeLatency(MetricQueryLatency, time.Now())
+
+	infos := make(map[string]*ScopeInfo)
+	info := func(id string) *ScopeInfo {
+		if infos[id] == nil {
+			infos[id] = &ScopeInfo{ID: id}
+		}
+		return infos[id]
+	}
+
+	for _, v := range u.TensorLogic.ListVariables(ctx) {
+		info(v.Name).TensorVariable = v
+	}
+	for _, scope := range u.Hypermind.ListScopes(ctx) {
+		info(scope.ID).DistributedScope = scope
+	}
+	for _, atom := range u.ATenSpace.GetAtomsByType(ctx, atenspace.AggregateAtom) {
+		atomCopy, err := u.ATenSpace.GetAtomCopy(ctx, atom.ID)
+		if err != nil {
+			// removed since it was listed
+			continue
+		}
+		info(atom.ID).Atom = atomCopy
+	}
+
+	scopes := make([]*ScopeInfo, 0, len(infos))
+	for _, si := range infos {
+		if si.TensorVariable == nil {
+			si.MissingFrom = append(si.MissingFrom, TensorLogicFramework)
+		}
+		if si.DistributedScope == nil {
+			si.MissingFrom = append(si.MissingFrom, HypermindFramework)
+		}
+		if si.Atom == nil {
+			si.MissingFrom = append(si.MissingFrom, ATenSpaceFramework)
+		}
+		scopes = append(scopes, si)
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i].ID < scopes[j].ID })
+	return scopes, nil
+}
+
+// InconsistencyKind classifies a discrepancy found by VerifyConsistency.
+type InconsistencyKind string
+
+const (
+	// MissingFromFramework means a scope is absent from some frameworks
+	MissingFromFramework InconsistencyKind = "missing_from_framework"
+
+	// MissingTensor means an atom references a tensor that is not stored
+	MissingTensor InconsistencyKind = "missing_tensor"
+
+	// StateMismatch means a distributed scope's State keys differ from its
+	// atom's Attributes keys
+	StateMismatch InconsistencyKind = "state_mismatch"
+)
+
+// Inconsistency describes one discrepancy between the three frameworks.
+type Inconsistency struct {
+	// ID is the scope or atom the discrepancy concerns
+	ID string
+
+	// Kind classifies the discrepancy
+	Kind InconsistencyKind
+
+	// Detail is a human-readable description
+	Detail string
+}
+
+// VerifyConsistency cross-checks the three frameworks and returns every
+// discrepancy found, ordered by ID. It reports scopes missing from some
+// frameworks as ListScopes does, atoms whose tensor is missing from the
+// space, and scopes whose State keys differ from their atom's Attributes
+// keys.
+func (u *UnifiedFramework) VerifyConsistency(ctx context.Context) ([]Inconsistency, error) {
+	const op = "integration.(UnifiedFramework).VerifyConsistency"
+
+	scopes, err := u.ListScopes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, op)
+	}
+
+	found := make([]Inconsistency, 0)
+	for _, si := range scopes {
+		if len(si.MissingFrom) > 0 {
+			found = append(found, Inconsistency{
+				ID:     si.ID,
+				Kind:   MissingFromFramework,
+				Detail: fmt.Sprintf("scope %s is missing from %s", si.ID, strings.Join(si.MissingFrom, ", ")),
+			})
+		}
+		if si.DistributedScope == nil || si.Atom == nil {
+			continue
+		}
+		var onlyState, onlyAttrs []string
+		for k := range si.DistributedScope.State {
+			if _, ok := si.Atom.Attributes[k]; !ok {
+				onlyState = append(onlyState, k)
+			}
+		}
+		for k := range si.Atom.Attributes {
+			if _, ok := si.DistributedScope.State[k]; !ok {
+				onlyAttrs = append(onlyAttrs, k)
+			}
+		}
+		if len(onlyState) > 0 || len(onlyAttrs) > 0 {
+			sort.Strings(onlyState)
+			sort.Strings(onlyAttrs)
+			found = append(found, Inconsistency{
+				ID:     si.ID,
+				Kind:   StateMismatch,
+				Detail: fmt.Sprintf("scope %s state keys %v are not attributes; attribute keys %v are not state", si.ID, onlyState, onlyAttrs),
+			})
+		}
+	}
+
+	atoms, err := u.ATenSpace.ListAtoms(ctx)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, op)
+	}
+	for _, atom := range atoms {
+		if atom.TensorID == "" {
+			continue
+		}
+		if _, err := u.ATenSpace.GetTensor(ctx, atom.ID); err != nil {
+			found = append(found, Inconsistency{
+				ID:     atom.ID,
+				Kind:   MissingTensor,
+				Detail: fmt.Sprintf("atom %s references missing tensor %s", atom.ID, atom.TensorID),
+			})
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	return found, nil
 }
 
 // DefineDomainBoundary creates a boundary that spans all frameworks.
 func (u *UnifiedFramework) DefineDomainBoundary(ctx context.Context, boundaryID, boundaryType string, atomIDs []string) error {
 	const op = "integration.(UnifiedFramework).DefineDomainBoundary"
 
+	if err := u.checkOpen(ctx, op); err != nil {
+		return err
+	}
+
 	// Define boundary in ATenSpace (where Space is defined by Boundary)
 	boundary := &atenspace.DomainBoundary{
 		ID:      boundaryID,
@@ -192,24 +914,203 @@ func (u *UnifiedFramework) DefineDomainBoundary(ctx context.Context, boundaryID,
 	return nil
 }
 
+// SyncPeerMembership mirrors the Hypermind peers of a scope into ATenSpace:
+// each peer gets an entity atom, and a MembershipLink runs from the scope's
+// atom to it. Atoms and links that already exist are kept, so syncing again
+// only adds what is missing. Membership links to peers that have since left
+// the scope are removed; their peer atoms are kept, as they may belong to
+// other scopes.
+func (u *UnifiedFramework) SyncPeerMembership(ctx context.Context, scopeID string) error {
+	const op = "integration.(UnifiedFramework).SyncPeerMembership"
+
+	if err := u.checkOpen(ctx, op); err != nil {
+		return err
+	}
+	if _, err := u.ATenSpace.GetAtomCopy(ctx, scopeID); err != nil {
+		return errors.Wrap(ctx, err, op, errors.WithMsg("looking up scope atom"))
+	}
+	peers, err := u.Hypermind.DiscoverPeers(ctx, scopeID)
+	if err != nil {
+		return errors.Wrap(ctx, err, op, errors.WithMsg("discovering peers"))
+	}
+
+	members := make(map[string]bool, len(peers))
+	for _, peer := range peers {
+		members[peerAtomID(peer.ID)] = true
+
+		atom := &atenspace.Atom{
+			ID:   peerAtomID(peer.ID),
+			Type: atenspace.EntityAtom,
+			Name: peer.ID,
+			Attributes: map[string]interface{}{
+				"peer_id": peer.ID,
+				"address": peer.Address,
+			},
+		}
+		if err := u.ATenSpace.AddAtom(ctx, atom); err != nil && !errors.Match(errors.T(errors.NotUnique), err) {
+			return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("adding atom for peer %s", peer.ID)))
+		}
+
+		link := &atenspace.Link{
+			ID:       membershipLinkID(scopeID, peer.ID),
+			Type:     atenspace.MembershipLink,
+			Source:   scopeID,
+			Target:   atom.ID,
+			Strength: 1.0,
+		}
+		if err := u.ATenSpace.AddLink(ctx, link); err != nil && !errors.Match(errors.T(errors.NotUnique), err) {
+			return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("linking peer %s", peer.ID)))
+		}
+	}
+
+	for _, link := range u.ATenSpace.GetLinksForAtom(ctx, scopeID) {
+		if link.Type != atenspace.MembershipLink || link.Source != scopeID || members[link.Target] {
+			continue
+		}
+		peerID, ok := strings.CutPrefix(link.Target, peerAtomPrefix)
+		if !ok || link.ID != membershipLinkID(scopeID, peerID) {
+			continue
+		}
+		if err := u.ATenSpace.RemoveLink(ctx, link.ID); err != nil && !isNotFound(err) {
+			return errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("unlinking peer %s", peerID)))
+		}
+	}
+
+	return nil
+}
+
+// peerAtomPrefix starts the ID of every peer atom, keeping peer atoms apart
+// from scope atoms with the same ID.
+const peerAtomPrefix = "peer_"
+
+// peerAtomID returns the ID of the atom SyncPeerMembership creates for a
+// peer.
+func peerAtomID(peerID string) string {
+	return peerAtomPrefix + peerID
+}
+
+// membershipLinkID returns the ID of the MembershipLink from a scope's atom
+// to a peer's atom.
+func membershipLinkID(scopeID, peerID string) string {
+	return scopeID + "_member_" + peerID
+}
+
 // PropagateState demonstrates state propagation across frameworks.
-func (u *UnifiedFramework) PropagateState(ctx context.Context, scopeID string, state map[string]interface{}) error {
+// Supported options: WithTensorIndices
+func (u *UnifiedFramework) PropagateState(ctx context.Context, scopeID string, state map[string]interface{}, opt ...Option) error {
 	const op = "integration.(UnifiedFramework).PropagateState"
 
+	if err := u.checkOpen(ctx, op); err != nil {
+		return err
+	}
+
 	// Propagate through Hypermind P2P network
 	if err := u.Hypermind.PropagateState(ctx, scopeID, state); err != nil {
 		return errors.Wrap(ctx, err, op)
 	}
 
 	// Update atom attributes in ATenSpace
-	atom, err := u.ATenSpace.GetAtom(ctx, scopeID)
-	if err != nil {
+	if err := u.ATenSpace.UpdateAtom(ctx, scopeID, state); err != nil {
 		return errors.Wrap(ctx, err, op)
 	}
 
-	for k, v := range state {
-		atom.Attributes[k] = v
+	// Update the atom's tensor with the numeric values of mapped keys
+	opts := getOpts(opt...)
+	values := make(map[int]float64)
+	for k, index := range opts.withTensorIndices {
+		if v, ok := toFloat64(state[k]); ok {
+			values[index] = v
+		}
+	}
+	if len(values) > 0 {
+		if err := u.ATenSpace.SetTensorValues(ctx, scopeID, values); err != nil {
+			return errors.Wrap(ctx, err, op)
+		}
 	}
 
+	u.metrics.IncrCounter(MetricStatePropagations, 1)
 	return nil
 }
+
+// PropagateStateBulk propagates the same state to every scope in scopeIDs
+// as PropagateState does. A failure for one scope does not stop the others;
+// the returned error joins one error per failed scope, naming the scope.
+// Supported options: WithTensorIndices
+func (u *UnifiedFramework) PropagateStateBulk(ctx context.Context, scopeIDs []string, state map[string]interface{}, opt ...Option) error {
+	const op = "integration.(UnifiedFramework).PropagateStateBulk"
+
+	if err := u.checkOpen(ctx, op); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, scopeID := range scopeIDs {
+		if err := u.PropagateState(ctx, scopeID, state, opt...); err != nil {
+			errs = append(errs, errors.Wrap(ctx, err, op, errors.WithMsg(fmt.Sprintf("propagating to scope %s", scopeID))))
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// toFloat64 converts a numeric state value to float64. It reports false for
+// non-numeric values.
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// ResourceAccessors answers "who can access this resource" for a scope. The
+// scope's tensor variable is treated as a user-to-resource access matrix; it
+// is inverted and the indices of users with a non-zero entry for
+// resourceIndex are returned.
+func (u *UnifiedFramework) ResourceAccessors(ctx context.Context, scopeID string, resourceIndex int) ([]int, error) {
+	const op = "integration.(UnifiedFramework).ResourceAccessors"
+
+	access, err := u.TensorLogic.Evaluate(ctx, scopeID)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, op)
+	}
+	inverted, err := u.TensorLogic.Invert(ctx, access)
+	if err != nil {
+		return nil, errors.Wrap(ctx, err, op)
+	}
+
+	resources, users := inverted.Shape[0], inverted.Shape[1]
+	if resourceIndex < 0 || resourceIndex >= resources {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("resource index %d out of range [0, %d)", resourceIndex, resources))
+	}
+
+	accessors := make([]int, 0)
+	for user := 0; user < users; user++ {
+		if inverted.Data[resourceIndex*users+user] != 0 {
+			accessors = append(accessors, user)
+		}
+	}
+	return accessors, nil
+}
@@ -5,10 +5,15 @@ package integration
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/boundary/internal/atenspace"
+	"github.com/hashicorp/boundary/internal/errors"
 	"github.com/hashicorp/boundary/internal/hypermind"
+	"github.com/hashicorp/boundary/internal/tensorlogic"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -235,6 +240,23 @@ func TestUnifiedFramework_PropagateState(t *testing.T) {
 	})
 }
 
+func TestUnifiedFramework_PropagateStateUpdatedAt(t *testing.T) {
+	ctx := context.Background()
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+	created, err := uf.ATenSpace.GetAtomCopy(ctx, "org-1")
+	require.NoError(t, err)
+
+	before := time.Now()
+	require.NoError(t, uf.PropagateState(ctx, "org-1", map[string]interface{}{"status": "active"}))
+
+	atom, err := uf.ATenSpace.GetAtomCopy(ctx, "org-1")
+	require.NoError(t, err)
+	assert.Equal(t, created.CreatedAt, atom.CreatedAt)
+	assert.False(t, atom.UpdatedAt.Before(before))
+}
+
 func TestUnifiedFramework_ComplexScenario(t *testing.T) {
 	ctx := context.Background()
 
@@ -314,6 +336,32 @@ func TestUnifiedFramework_TensorLogicIntegration(t *testing.T) {
 		result, err := uf.TensorLogic.Join(ctx, v1, v2)
 		require.NoError(t, err)
 		assert.NotNil(t, result)
+
+		// both scope variables share their indices, so the join contracts
+		// them fully
+		assert.Empty(t, result.Indices)
+		assert.Equal(t, []int{}, result.Shape)
+
+		// a shaped operand lets the join infer the result's dimensions
+		roles := &tensorlogic.Variable{
+			Name:    "roles",
+			Indices: []string{"entity", "property", "role"},
+			Shape:   []int{2, 3, 4},
+		}
+		result, err = uf.TensorLogic.Join(ctx, v1, roles)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"role"}, result.Indices)
+		assert.Equal(t, []int{4}, result.Shape)
+
+		// entity is left free and neither operand gives its dimension
+		features := &tensorlogic.Variable{
+			Name:    "features",
+			Indices: []string{"property", "feature"},
+			Shape:   []int{3, 5},
+		}
+		_, err = uf.TensorLogic.Join(ctx, v1, features)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot infer dimensions of indices [entity]")
 	})
 }
 
@@ -390,3 +438,894 @@ func TestScopeInfo_Structure(t *testing.T) {
 	assert.Nil(t, info.DistributedScope)
 	assert.Nil(t, info.Atom)
 }
+
+func TestUnifiedFramework_ResourceAccessors(t *testing.T) {
+	ctx := context.Background()
+
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.TensorLogic.RegisterVariable(ctx, &tensorlogic.Variable{
+		Name:    "org-1",
+		Indices: []string{"user", "resource"},
+		Shape:   []int{3, 2},
+		Data: []float64{
+			1, 0,
+			1, 1,
+			0, 1,
+		},
+		Type: tensorlogic.SymbolicType,
+	}))
+
+	t.Run("returns users with access to a resource", func(t *testing.T) {
+		users, err := uf.ResourceAccessors(ctx, "org-1", 0)
+		require.NoError(t, err)
+		assert.Equal(t, []int{0, 1}, users)
+
+		users, err = uf.ResourceAccessors(ctx, "org-1", 1)
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, users)
+	})
+
+	t.Run("error on out of range resource", func(t *testing.T) {
+		_, err := uf.ResourceAccessors(ctx, "org-1", 2)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "out of range")
+	})
+
+	t.Run("error on unknown scope", func(t *testing.T) {
+		_, err := uf.ResourceAccessors(ctx, "nonexistent", 0)
+		require.Error(t, err)
+	})
+}
+
+func TestUnifiedFramework_PropagateStateConcurrentQuery(t *testing.T) {
+	ctx := context.Background()
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, uf.PropagateState(ctx, "org-1", map[string]interface{}{fmt.Sprintf("k%d", i): i}))
+		}(i)
+		go func() {
+			defer wg.Done()
+			info, err := uf.QueryScope(ctx, "org-1")
+			if assert.NoError(t, err) && assert.NotNil(t, info.Atom) {
+				// Reading the returned attributes must not race with
+				// concurrent propagation.
+				for k := range info.Atom.Attributes {
+					_ = info.Atom.Attributes[k]
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	atom, err := uf.ATenSpace.GetAtomCopy(ctx, "org-1")
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, i, atom.Attributes[fmt.Sprintf("k%d", i)])
+	}
+}
+
+func TestUnifiedFramework_RemoveBoundaryScope(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("removes scope from all frameworks", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+
+		require.NoError(t, uf.RemoveBoundaryScope(ctx, "org-1"))
+
+		_, err = uf.TensorLogic.Evaluate(ctx, "org-1")
+		assert.Error(t, err)
+		_, err = uf.Hypermind.GetScope(ctx, "org-1")
+		assert.Error(t, err)
+		_, err = uf.ATenSpace.GetAtom(ctx, "org-1")
+		assert.Error(t, err)
+		_, err = uf.ATenSpace.GetTensor(ctx, "org-1")
+		assert.Error(t, err)
+	})
+
+	t.Run("reports partial failure", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+		require.NoError(t, uf.ATenSpace.RemoveAtom(ctx, "org-1"))
+
+		err = uf.RemoveBoundaryScope(ctx, "org-1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "removing atom")
+		assert.NotContains(t, err.Error(), "removing tensor variable")
+
+		// The other frameworks were still cleaned up.
+		_, err = uf.TensorLogic.Evaluate(ctx, "org-1")
+		assert.Error(t, err)
+		_, err = uf.Hypermind.GetScope(ctx, "org-1")
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown scope reports every framework", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+
+		err = uf.RemoveBoundaryScope(ctx, "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "removing tensor variable")
+		assert.Contains(t, err.Error(), "removing distributed scope")
+		assert.Contains(t, err.Error(), "removing atom")
+	})
+}
+
+func TestUnifiedFramework_CreateBoundaryScopeRollback(t *testing.T) {
+	ctx := context.Background()
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+
+	// An atom that already exists makes the ATenSpace step fail.
+	require.NoError(t, uf.ATenSpace.AddAtom(ctx, &atenspace.Atom{ID: "org-1", Type: atenspace.EntityAtom, Name: "existing"}))
+
+	err = uf.CreateBoundaryScope(ctx, "org-1", "org")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "adding atom")
+	assert.True(t, errors.Match(errors.T(errors.NotUnique), err))
+
+	_, err = uf.TensorLogic.Evaluate(ctx, "org-1")
+	assert.Error(t, err, "tensor variable should be rolled back")
+	_, err = uf.Hypermind.GetScope(ctx, "org-1")
+	assert.Error(t, err, "distributed scope should be rolled back")
+
+	atom, err := uf.ATenSpace.GetAtomCopy(ctx, "org-1")
+	require.NoError(t, err)
+	assert.Equal(t, "existing", atom.Name)
+	assert.Empty(t, atom.TensorID)
+
+	// Once the conflicting atom is gone the scope can be created.
+	require.NoError(t, uf.ATenSpace.RemoveAtom(ctx, "org-1"))
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+}
+
+func TestUnifiedFramework_ListScopes(t *testing.T) {
+	ctx := context.Background()
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "global", "global"))
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+
+	scopes, err := uf.ListScopes(ctx)
+	require.NoError(t, err)
+	require.Len(t, scopes, 2)
+	for _, si := range scopes {
+		assert.Empty(t, si.MissingFrom, si.ID)
+		assert.NotNil(t, si.TensorVariable)
+		assert.NotNil(t, si.DistributedScope)
+		assert.NotNil(t, si.Atom)
+	}
+
+	// A scope registered only in Hypermind is reported as inconsistent.
+	require.NoError(t, uf.Hypermind.RegisterScope(ctx, &hypermind.DistributedScope{ID: "rogue", Type: "org"}))
+
+	scopes, err = uf.ListScopes(ctx)
+	require.NoError(t, err)
+	ids := make([]string, 0, len(scopes))
+	for _, si := range scopes {
+		ids = append(ids, si.ID)
+	}
+	assert.Equal(t, []string{"global", "org-1", "rogue"}, ids)
+
+	rogue := scopes[2]
+	assert.NotNil(t, rogue.DistributedScope)
+	assert.Nil(t, rogue.TensorVariable)
+	assert.Nil(t, rogue.Atom)
+	assert.Equal(t, []string{TensorLogicFramework, ATenSpaceFramework}, rogue.MissingFrom)
+}
+
+func TestUnifiedFramework_VerifyConsistency(t *testing.T) {
+	ctx := context.Background()
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-2", "org"))
+	require.NoError(t, uf.PropagateState(ctx, "org-1", map[string]interface{}{"status": "active"}))
+
+	found, err := uf.VerifyConsistency(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	// Desync Hypermind and ATenSpace: state that never reached the atom,
+	// and an attribute that never reached the scope.
+	require.NoError(t, uf.Hypermind.PropagateState(ctx, "org-1", map[string]interface{}{"owner": "alice"}))
+	require.NoError(t, uf.ATenSpace.UpdateAtom(ctx, "org-2", map[string]interface{}{"region": "eu"}))
+	require.NoError(t, uf.TensorLogic.DeleteVariable(ctx, "org-2"))
+
+	found, err = uf.VerifyConsistency(ctx)
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+
+	assert.Equal(t, "org-1", found[0].ID)
+	assert.Equal(t, StateMismatch, found[0].Kind)
+	assert.Contains(t, found[0].Detail, "state keys [owner] are not attributes")
+
+	assert.Equal(t, "org-2", found[1].ID)
+	assert.Equal(t, MissingFromFramework, found[1].Kind)
+	assert.Contains(t, found[1].Detail, "missing from tensorlogic")
+
+	assert.Equal(t, "org-2", found[2].ID)
+	assert.Equal(t, StateMismatch, found[2].Kind)
+	assert.Contains(t, found[2].Detail, "attribute keys [region] are not state")
+}
+
+func TestUnifiedFramework_PropagateStateToTensor(t *testing.T) {
+	ctx := context.Background()
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+
+	indices := map[string]int{"load": 0, "users": 11, "status": 5}
+	state := map[string]interface{}{"load": 0.75, "users": 42, "status": "active"}
+	require.NoError(t, uf.PropagateState(ctx, "org-1", state, WithTensorIndices(indices)))
+
+	atom, err := uf.ATenSpace.GetAtomCopy(ctx, "org-1")
+	require.NoError(t, err)
+	assert.Equal(t, 0.75, atom.Attributes["load"])
+	assert.Equal(t, 42, atom.Attributes["users"])
+	assert.Equal(t, "active", atom.Attributes["status"])
+
+	tensor, err := uf.ATenSpace.GetTensor(ctx, "org-1")
+	require.NoError(t, err)
+	assert.Equal(t, 0.75, tensor.Data[0])
+	assert.Equal(t, 42.0, tensor.Data[11])
+	assert.Equal(t, 0.0, tensor.Data[5], "non-numeric values are not written")
+
+	t.Run("without mapping the tensor is untouched", func(t *testing.T) {
+		require.NoError(t, uf.PropagateState(ctx, "org-1", map[string]interface{}{"load": 0.1}))
+		tensor, err := uf.ATenSpace.GetTensor(ctx, "org-1")
+		require.NoError(t, err)
+		assert.Equal(t, 0.75, tensor.Data[0])
+	})
+
+	t.Run("out of range index", func(t *testing.T) {
+		err := uf.PropagateState(ctx, "org-1", map[string]interface{}{"load": 1}, WithTensorIndices(map[string]int{"load": 100}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "index 100 out of range")
+	})
+}
+
+func TestUnifiedFramework_BulkCreateBoundaryScopes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("builds tree with children listed first", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+
+		err = uf.BulkCreateBoundaryScopes(ctx, []ScopeSpec{
+			{ID: "proj-1", Type: "project", ParentID: "org-1"},
+			{ID: "org-1", Type: "org", ParentID: "global"},
+			{ID: "global", Type: "global"},
+		})
+		require.NoError(t, err)
+
+		for _, id := range []string{"global", "org-1", "proj-1"} {
+			info, err := uf.QueryScope(ctx, id)
+			require.NoError(t, err)
+			assert.NotNil(t, info.TensorVariable, id)
+			assert.NotNil(t, info.DistributedScope, id)
+			assert.NotNil(t, info.Atom, id)
+		}
+
+		scope, err := uf.Hypermind.GetScope(ctx, "proj-1")
+		require.NoError(t, err)
+		assert.Equal(t, "org-1", scope.ParentID)
+
+		links := uf.ATenSpace.GetLinksByType(ctx, atenspace.ScopeLink)
+		pairs := make([]string, 0, len(links))
+		for _, l := range links {
+			pairs = append(pairs, l.Source+"->"+l.Target)
+		}
+		assert.Equal(t, []string{"global->org-1", "org-1->proj-1"}, pairs)
+	})
+
+	t.Run("parent cycle creates nothing", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+
+		err = uf.BulkCreateBoundaryScopes(ctx, []ScopeSpec{
+			{ID: "a", Type: "org", ParentID: "b"},
+			{ID: "b", Type: "org", ParentID: "a"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is its own ancestor")
+
+		scopes, err := uf.ListScopes(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, scopes)
+	})
+
+	t.Run("missing parent rolls back created scopes", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+
+		err = uf.BulkCreateBoundaryScopes(ctx, []ScopeSpec{
+			{ID: "global", Type: "global"},
+			{ID: "org-1", Type: "org", ParentID: "elsewhere"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "parent atom elsewhere not found")
+
+		scopes, err := uf.ListScopes(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, scopes)
+	})
+
+	t.Run("duplicate IDs", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+
+		err = uf.BulkCreateBoundaryScopes(ctx, []ScopeSpec{{ID: "a", Type: "org"}, {ID: "a", Type: "org"}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate scope a")
+	})
+}
+
+func TestUnifiedFramework_CreateBoundaryScopeUnder(t *testing.T) {
+	ctx := context.Background()
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "global", "global"))
+
+	require.NoError(t, uf.CreateBoundaryScopeUnder(ctx, "org-1", "org", "global"))
+
+	scope, err := uf.Hypermind.GetScope(ctx, "org-1")
+	require.NoError(t, err)
+	assert.Equal(t, "global", scope.ParentID)
+
+	children, err := uf.Hypermind.GetChildScopes(ctx, "global")
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+	assert.Equal(t, "org-1", children[0].ID)
+
+	links := uf.ATenSpace.GetOutgoingLinks(ctx, "global")
+	require.Len(t, links, 1)
+	assert.Equal(t, atenspace.ScopeLink, links[0].Type)
+	assert.Equal(t, "org-1", links[0].Target)
+
+	t.Run("missing parent atom", func(t *testing.T) {
+		err := uf.CreateBoundaryScopeUnder(ctx, "org-2", "org", "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "parent atom missing not found")
+		_, err = uf.Hypermind.GetScope(ctx, "org-2")
+		assert.Error(t, err)
+		_, err = uf.TensorLogic.Evaluate(ctx, "org-2")
+		assert.Error(t, err)
+	})
+
+	t.Run("empty parent", func(t *testing.T) {
+		err := uf.CreateBoundaryScopeUnder(ctx, "org-2", "org", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "parent ID is empty")
+	})
+}
+
+func TestUnifiedFramework_QueryScopeBoundaries(t *testing.T) {
+	ctx := context.Background()
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-2", "org"))
+
+	require.NoError(t, uf.DefineDomainBoundary(ctx, "security", string(atenspace.SecurityBoundary), []string{"org-1", "org-2"}))
+	require.NoError(t, uf.DefineDomainBoundary(ctx, "tx", string(atenspace.TransactionalBoundary), []string{"org-1"}))
+	require.NoError(t, uf.DefineDomainBoundary(ctx, "other", string(atenspace.LogicalBoundary), []string{"org-2"}))
+
+	info, err := uf.QueryScope(ctx, "org-1")
+	require.NoError(t, err)
+	ids := make([]string, 0, len(info.Boundaries))
+	for _, b := range info.Boundaries {
+		ids = append(ids, b.ID)
+	}
+	assert.Equal(t, []string{"security", "tx"}, ids)
+
+	info, err = uf.QueryScope(ctx, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, info.Boundaries)
+}
+
+func TestUnifiedFramework_Close(t *testing.T) {
+	ctx := context.Background()
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+
+	ch, _, err := uf.Hypermind.Subscribe(ctx, "org-1")
+	require.NoError(t, err)
+
+	require.NoError(t, uf.Close(ctx))
+	require.NoError(t, uf.Close(ctx), "Close should be idempotent")
+
+	_, ok := <-ch
+	assert.False(t, ok, "subscription channel should be closed")
+
+	calls := map[string]func() error{
+		"CreateBoundaryScope":      func() error { return uf.CreateBoundaryScope(ctx, "org-2", "org") },
+		"CreateBoundaryScopeUnder": func() error { return uf.CreateBoundaryScopeUnder(ctx, "org-2", "org", "org-1") },
+		"BulkCreateBoundaryScopes": func() error {
+			return uf.BulkCreateBoundaryScopes(ctx, []ScopeSpec{{ID: "org-2", Type: "org"}})
+		},
+		"RemoveBoundaryScope":   func() error { return uf.RemoveBoundaryScope(ctx, "org-1") },
+		"DefineDomainBoundary":  func() error { return uf.DefineDomainBoundary(ctx, "b", "logical", []string{"org-1"}) },
+		"PropagateState":        func() error { return uf.PropagateState(ctx, "org-1", map[string]interface{}{"k": 1}) },
+		"IntegrateWithBoundary": func() error { return uf.IntegrateWithBoundary(ctx) },
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			err := call()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "framework is closed")
+		})
+	}
+
+	// Reads still work after Close.
+	info, err := uf.QueryScope(ctx, "org-1")
+	require.NoError(t, err)
+	assert.NotNil(t, info.Atom)
+}
+
+// recordingMetrics is a Metrics that records everything it receives.
+type recordingMetrics struct {
+	mu        sync.Mutex
+	counters  map[string]int64
+	latencies map[string]int
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{counters: make(map[string]int64), latencies: make(map[string]int)}
+}
+
+func (r *recordingMetrics) IncrCounter(name string, delta int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[name] += delta
+}
+
+func (r *recordingMetrics) ObserveLatency(name string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies[name]++
+}
+
+func TestUnifiedFramework_Metrics(t *testing.T) {
+	ctx := context.Background()
+	m := newRecordingMetrics()
+	uf, err := NewUnifiedFramework(ctx, WithMetrics(m))
+	require.NoError(t, err)
+
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "global", "global"))
+	require.NoError(t, uf.CreateBoundaryScopeUnder(ctx, "org-1", "org", "global"))
+	require.NoError(t, uf.BulkCreateBoundaryScopes(ctx, []ScopeSpec{
+		{ID: "proj-1", Type: "project", ParentID: "org-1"},
+		{ID: "proj-2", Type: "project", ParentID: "org-1"},
+	}))
+	require.Error(t, uf.CreateBoundaryScopeUnder(ctx, "org-2", "org", "missing"))
+	assert.Equal(t, int64(4), m.counters[MetricScopesCreated])
+
+	require.NoError(t, uf.PropagateState(ctx, "org-1", map[string]interface{}{"k": 1}))
+	require.NoError(t, uf.PropagateState(ctx, "org-1", map[string]interface{}{"k": 2}))
+	require.Error(t, uf.PropagateState(ctx, "missing", map[string]interface{}{"k": 1}))
+	assert.Equal(t, int64(2), m.counters[MetricStatePropagations])
+
+	require.NoError(t, uf.ConnectPeer(ctx, &hypermind.Peer{ID: "peer-1", Address: "10.0.0.1", ScopeIDs: []string{"org-1"}}))
+	assert.Equal(t, int64(1), m.counters[MetricPeerConnects])
+
+	_, err = uf.QueryScope(ctx, "org-1")
+	require.NoError(t, err)
+	_, err = uf.ListScopes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, m.latencies[MetricQueryLatency])
+}
+
+func TestUnifiedFramework_ComputeAccessMatrix(t *testing.T) {
+	ctx := context.Background()
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+
+	// 3 users, 2 roles, 4 permissions
+	usersRoles := &tensorlogic.Variable{
+		Name:    "users_roles",
+		Indices: []string{"users", "roles"},
+		Shape:   []int{3, 2},
+		Data:    []float64{1, 0, 0, 1, 1, 1},
+	}
+	rolesPerms := &tensorlogic.Variable{
+		Name:    "roles_perms",
+		Indices: []string{"roles", "perms"},
+		Shape:   []int{2, 4},
+		Data:    []float64{1, 1, 0, 0, 0, 0, 1, 1},
+	}
+
+	access, err := uf.ComputeAccessMatrix(ctx, "org-1", usersRoles, rolesPerms)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"users", "perms"}, access.Indices)
+	assert.Equal(t, []int{3, 4}, access.Shape)
+	assert.Equal(t, []float64{1, 1, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1}, access.Data)
+
+	tensor, err := uf.ATenSpace.GetTensor(ctx, "org-1")
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 4}, tensor.Shape)
+	assert.Equal(t, access.Data, tensor.Data)
+
+	t.Run("roles index mismatch", func(t *testing.T) {
+		other := &tensorlogic.Variable{Name: "x", Indices: []string{"groups", "perms"}, Shape: []int{2, 4}, Data: make([]float64, 8)}
+		_, err := uf.ComputeAccessMatrix(ctx, "org-1", usersRoles, other)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "roles index roles of users_roles does not match index groups of x")
+	})
+
+	t.Run("unknown scope", func(t *testing.T) {
+		_, err := uf.ComputeAccessMatrix(ctx, "missing", usersRoles, rolesPerms)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "atom missing not found")
+	})
+}
+
+func TestUnifiedFramework_QueryScopeNotFound(t *testing.T) {
+	ctx := context.Background()
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.Hypermind.RegisterScope(ctx, &hypermind.DistributedScope{ID: "org-1", Type: "org"}))
+
+	// Frameworks reporting NotFound leave their part of the result empty.
+	info, err := uf.QueryScope(ctx, "org-1")
+	require.NoError(t, err)
+	assert.NotNil(t, info.DistributedScope)
+	assert.Nil(t, info.TensorVariable)
+	assert.Nil(t, info.Atom)
+}
+
+func TestUnifiedFramework_CreateBoundaryScopeType(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("valid types", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, uf.CreateBoundaryScope(ctx, "global", "global"))
+		require.NoError(t, uf.CreateBoundaryScopeUnder(ctx, "org-1", "org", "global"))
+		require.NoError(t, uf.CreateBoundaryScopeUnder(ctx, "proj-1", "project", "org-1"))
+		require.NoError(t, uf.CreateBoundaryScopeUnder(ctx, "proj-2", "project", "proj-1"))
+		require.NoError(t, uf.CreateBoundaryScope(ctx, "proj-3", "project"))
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+		err = uf.CreateBoundaryScope(ctx, "org-1", "orgnization")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		assert.Contains(t, err.Error(), `unknown scope type "orgnization"`)
+
+		scopes, err := uf.ListScopes(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, scopes)
+	})
+
+	t.Run("global with parent", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+		err = uf.CreateBoundaryScopeUnder(ctx, "global", "global", "org-1")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		assert.Contains(t, err.Error(), "global scope global cannot have a parent")
+	})
+
+	t.Run("project under global", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, uf.CreateBoundaryScope(ctx, "global", "global"))
+		err = uf.CreateBoundaryScopeUnder(ctx, "proj-1", "project", "global")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		assert.Contains(t, err.Error(), "parent global of project scope proj-1 is not an org or project scope")
+	})
+
+	t.Run("bulk rejects unknown type before creating anything", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+		err = uf.BulkCreateBoundaryScopes(ctx, []ScopeSpec{
+			{ID: "global", Type: "global"},
+			{ID: "org-1", Type: "orgnization", ParentID: "global"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown scope type "orgnization"`)
+
+		scopes, err := uf.ListScopes(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, scopes)
+	})
+}
+
+func TestUnifiedFramework_RenameScope(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("updates every reference", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, uf.CreateBoundaryScope(ctx, "global", "global"))
+		require.NoError(t, uf.CreateBoundaryScopeUnder(ctx, "org-1", "org", "global"))
+		require.NoError(t, uf.CreateBoundaryScopeUnder(ctx, "proj-1", "project", "org-1"))
+		require.NoError(t, uf.DefineDomainBoundary(ctx, "b1", "scope", []string{"org-1", "proj-1"}))
+
+		require.NoError(t, uf.RenameScope(ctx, "org-1", "org-2"))
+
+		_, err = uf.TensorLogic.GetVariable(ctx, "org-1")
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+		v, err := uf.TensorLogic.GetVariable(ctx, "org-2")
+		require.NoError(t, err)
+		assert.Equal(t, "org-2", v.Name)
+
+		_, err = uf.Hypermind.GetScope(ctx, "org-1")
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+		distScope, err := uf.Hypermind.GetScope(ctx, "org-2")
+		require.NoError(t, err)
+		assert.Equal(t, "org-2", distScope.ID)
+		assert.Equal(t, "global", distScope.ParentID)
+		child, err := uf.Hypermind.GetScope(ctx, "proj-1")
+		require.NoError(t, err)
+		assert.Equal(t, "org-2", child.ParentID)
+
+		_, err = uf.ATenSpace.GetAtomCopy(ctx, "org-1")
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+		atom, err := uf.ATenSpace.GetAtomCopy(ctx, "org-2")
+		require.NoError(t, err)
+		assert.Equal(t, "org-2", atom.ID)
+
+		parentLink, err := uf.ATenSpace.GetLink(ctx, scopeLinkID("global", "org-2"))
+		require.NoError(t, err)
+		assert.Equal(t, "org-2", parentLink.Target)
+		childLink, err := uf.ATenSpace.GetLink(ctx, scopeLinkID("org-2", "proj-1"))
+		require.NoError(t, err)
+		assert.Equal(t, "org-2", childLink.Source)
+		_, err = uf.ATenSpace.GetLink(ctx, scopeLinkID("global", "org-1"))
+		assert.Error(t, err)
+		_, err = uf.ATenSpace.GetLink(ctx, scopeLinkID("org-1", "proj-1"))
+		assert.Error(t, err)
+
+		info, err := uf.QueryScope(ctx, "org-2")
+		require.NoError(t, err)
+		require.Len(t, info.Boundaries, 1)
+		assert.Equal(t, []string{"org-2", "proj-1"}, info.Boundaries[0].AtomIDs)
+
+		inconsistencies, err := uf.VerifyConsistency(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, inconsistencies)
+	})
+
+	t.Run("renames the tensor so the old ID can be reused", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+
+		require.NoError(t, uf.RenameScope(ctx, "org-1", "org-2"))
+		renamed, err := uf.ATenSpace.GetTensor(ctx, "org-2")
+		require.NoError(t, err)
+		assert.Equal(t, scopeTensorID("org-2"), renamed.ID)
+		_, err = uf.ATenSpace.GetTensorByID(ctx, scopeTensorID("org-1"))
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+
+		require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+		require.NoError(t, uf.PropagateState(ctx, "org-1", map[string]interface{}{"x": 7}, WithTensorIndices(map[string]int{"x": 3})))
+
+		recreated, err := uf.ATenSpace.GetTensor(ctx, "org-1")
+		require.NoError(t, err)
+		assert.NotSame(t, renamed, recreated)
+		assert.Equal(t, 7.0, recreated.Data[3])
+		assert.Equal(t, 0.0, renamed.Data[3])
+
+		inconsistencies, err := uf.VerifyConsistency(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, inconsistencies)
+	})
+
+	t.Run("rejects an existing new ID", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+		require.NoError(t, uf.ATenSpace.AddAtom(ctx, &atenspace.Atom{ID: "org-2", Type: atenspace.ConceptAtom}))
+
+		err = uf.RenameScope(ctx, "org-1", "org-2")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.NotUnique), err))
+		assert.Contains(t, err.Error(), ATenSpaceFramework)
+
+		// Nothing was renamed.
+		_, err = uf.TensorLogic.GetVariable(ctx, "org-1")
+		assert.NoError(t, err)
+		_, err = uf.TensorLogic.GetVariable(ctx, "org-2")
+		assert.Error(t, err)
+		_, err = uf.Hypermind.GetScope(ctx, "org-1")
+		assert.NoError(t, err)
+		_, err = uf.Hypermind.GetScope(ctx, "org-2")
+		assert.Error(t, err)
+		_, err = uf.ATenSpace.GetAtomCopy(ctx, "org-1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown scope", func(t *testing.T) {
+		uf, err := NewUnifiedFramework(ctx)
+		require.NoError(t, err)
+
+		err = uf.RenameScope(ctx, "missing", "org-2")
+		assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+	})
+}
+
+func TestUnifiedFramework_SyncPeerMembership(t *testing.T) {
+	ctx := context.Background()
+
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+	require.NoError(t, uf.ConnectPeer(ctx, &hypermind.Peer{ID: "node-a", Address: "10.0.0.1", ScopeIDs: []string{"org-1"}}))
+	require.NoError(t, uf.ConnectPeer(ctx, &hypermind.Peer{ID: "node-b", Address: "10.0.0.2", ScopeIDs: []string{"org-1"}}))
+
+	membershipTargets := func() []string {
+		var targets []string
+		for _, link := range uf.ATenSpace.GetLinksByType(ctx, atenspace.MembershipLink) {
+			assert.Equal(t, "org-1", link.Source)
+			targets = append(targets, link.Target)
+		}
+		return targets
+	}
+
+	require.NoError(t, uf.SyncPeerMembership(ctx, "org-1"))
+	require.NoError(t, uf.SyncPeerMembership(ctx, "org-1"))
+
+	assert.ElementsMatch(t, []string{"peer_node-a", "peer_node-b"}, membershipTargets())
+	assert.Len(t, uf.ATenSpace.GetAtomsByType(ctx, atenspace.EntityAtom), 2)
+	atom, err := uf.ATenSpace.GetAtomCopy(ctx, "peer_node-a")
+	require.NoError(t, err)
+	assert.Equal(t, "node-a", atom.Name)
+	assert.Equal(t, "10.0.0.1", atom.Attributes["address"])
+
+	// A peer that leaves loses its membership link but keeps its atom.
+	require.NoError(t, uf.Hypermind.DisconnectPeer(ctx, "node-b"))
+	require.NoError(t, uf.SyncPeerMembership(ctx, "org-1"))
+	assert.Equal(t, []string{"peer_node-a"}, membershipTargets())
+	_, err = uf.ATenSpace.GetAtomCopy(ctx, "peer_node-b")
+	assert.NoError(t, err)
+
+	// Peer atoms are not mistaken for scopes.
+	inconsistencies, err := uf.VerifyConsistency(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, inconsistencies)
+
+	err = uf.SyncPeerMembership(ctx, "missing")
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+}
+
+func TestUnifiedFramework_SyncPeerMembershipAfterRename(t *testing.T) {
+	ctx := context.Background()
+
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+	require.NoError(t, uf.ConnectPeer(ctx, &hypermind.Peer{ID: "node-a", Address: "10.0.0.1", ScopeIDs: []string{"org-1"}}))
+	require.NoError(t, uf.ConnectPeer(ctx, &hypermind.Peer{ID: "node-b", Address: "10.0.0.2", ScopeIDs: []string{"org-1"}}))
+
+	require.NoError(t, uf.SyncPeerMembership(ctx, "org-1"))
+	require.NoError(t, uf.RenameScope(ctx, "org-1", "org-2"))
+	require.NoError(t, uf.SyncPeerMembership(ctx, "org-2"))
+
+	var ids []string
+	for _, link := range uf.ATenSpace.GetLinksByType(ctx, atenspace.MembershipLink) {
+		assert.Equal(t, "org-2", link.Source)
+		ids = append(ids, link.ID)
+	}
+	assert.ElementsMatch(t, []string{membershipLinkID("org-2", "node-a"), membershipLinkID("org-2", "node-b")}, ids)
+}
+
+func TestUnifiedFramework_PropagateStateBulk(t *testing.T) {
+	ctx := context.Background()
+
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-1", "org"))
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "org-2", "org"))
+
+	err = uf.PropagateStateBulk(ctx, []string{"org-1", "missing", "org-2"}, map[string]interface{}{"maintenance": true})
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+	assert.Contains(t, err.Error(), "propagating to scope missing")
+	assert.NotContains(t, err.Error(), "propagating to scope org-")
+
+	for _, id := range []string{"org-1", "org-2"} {
+		distScope, err := uf.Hypermind.GetScope(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, true, distScope.State["maintenance"])
+		atom, err := uf.ATenSpace.GetAtomCopy(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, true, atom.Attributes["maintenance"])
+	}
+}
+
+func TestUnifiedFramework_QueryScopeEffectiveState(t *testing.T) {
+	ctx := context.Background()
+
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "global", "global"))
+	require.NoError(t, uf.CreateBoundaryScopeUnder(ctx, "org-1", "org", "global"))
+	require.NoError(t, uf.CreateBoundaryScopeUnder(ctx, "proj-1", "project", "org-1"))
+	require.NoError(t, uf.PropagateState(ctx, "global", map[string]interface{}{"environment": "production", "audit": true}))
+	require.NoError(t, uf.PropagateState(ctx, "org-1", map[string]interface{}{"region": "eu-west-1"}))
+	require.NoError(t, uf.PropagateState(ctx, "proj-1", map[string]interface{}{"environment": "staging"}))
+
+	info, err := uf.QueryScope(ctx, "proj-1", WithEffectiveState())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"environment": "staging",
+		"region":      "eu-west-1",
+		"audit":       true,
+	}, info.EffectiveState)
+	// The scope's own state is unchanged.
+	assert.Equal(t, map[string]interface{}{"environment": "staging"}, info.DistributedScope.State)
+
+	info, err = uf.QueryScope(ctx, "proj-1")
+	require.NoError(t, err)
+	assert.Nil(t, info.EffectiveState)
+
+	t.Run("cycle", func(t *testing.T) {
+		require.NoError(t, uf.Hypermind.RegisterScope(ctx, &hypermind.DistributedScope{ID: "loop-a", ParentID: "loop-b"}))
+		require.NoError(t, uf.Hypermind.RegisterScope(ctx, &hypermind.DistributedScope{ID: "loop-b", ParentID: "loop-c"}))
+		distScope, err := uf.Hypermind.GetScope(ctx, "loop-b")
+		require.NoError(t, err)
+		// RegisterScope refuses cycles, so close one behind its back.
+		distScope.ParentID = "loop-a"
+
+		_, err = uf.QueryScope(ctx, "loop-a", WithEffectiveState())
+		assert.True(t, errors.Match(errors.T(errors.CycleFound), err))
+	})
+}
+
+func TestUnifiedFramework_Health(t *testing.T) {
+	ctx := context.Background()
+
+	uf, err := NewUnifiedFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uf.CreateBoundaryScope(ctx, "global", "global"))
+	require.NoError(t, uf.CreateBoundaryScopeUnder(ctx, "org-1", "org", "global"))
+	require.NoError(t, uf.DefineDomainBoundary(ctx, "b1", "scope", []string{"global", "org-1"}))
+	require.NoError(t, uf.TensorLogic.DefineEquation(ctx, &tensorlogic.TensorEquation{Left: tensorlogic.Variable{Name: "org-1"}, Right: "A_ij * B_jk"}))
+	require.NoError(t, uf.ConnectPeer(ctx, &hypermind.Peer{ID: "node-a", ScopeIDs: []string{"org-1"}}))
+	require.NoError(t, uf.ConnectPeer(ctx, &hypermind.Peer{ID: "node-b", ScopeIDs: []string{"org-1"}}))
+
+	report := uf.Health(ctx)
+	assert.True(t, report.Healthy)
+	assert.False(t, report.Closed)
+	assert.NoError(t, report.Err)
+	assert.Empty(t, report.Inconsistencies)
+	assert.Equal(t, TensorLogicHealth{Variables: 2, Equations: 1}, report.TensorLogic)
+	assert.Equal(t, HypermindHealth{Scopes: 2, HealthyPeers: 2}, report.Hypermind)
+	assert.Equal(t, ATenSpaceHealth{Atoms: 2, Links: 1, Boundaries: 1}, report.ATenSpace)
+
+	// A scope missing from tensor logic is flagged.
+	require.NoError(t, uf.TensorLogic.DeleteVariable(ctx, "org-1"))
+	report = uf.Health(ctx)
+	assert.False(t, report.Healthy)
+	require.Len(t, report.Inconsistencies, 1)
+	assert.Equal(t, "org-1", report.Inconsistencies[0].ID)
+	assert.Equal(t, MissingFromFramework, report.Inconsistencies[0].Kind)
+	assert.Equal(t, TensorLogicHealth{Variables: 1}, report.TensorLogic)
+
+	require.NoError(t, uf.Close(ctx))
+	report = uf.Health(ctx)
+	assert.True(t, report.Closed)
+	assert.False(t, report.Healthy)
+}
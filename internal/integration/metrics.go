@@ -0,0 +1,38 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package integration
+
+import "time"
+
+// Metric names reported by the UnifiedFramework.
+const (
+	// MetricScopesCreated counts scopes created in all three frameworks
+	MetricScopesCreated = "scopes_created"
+
+	// MetricStatePropagations counts successful PropagateState calls
+	MetricStatePropagations = "state_propagations"
+
+	// MetricPeerConnects counts peers connected through ConnectPeer
+	MetricPeerConnects = "peer_connects"
+
+	// MetricQueryLatency is the latency of QueryScope and ListScopes
+	MetricQueryLatency = "query_latency"
+)
+
+// Metrics receives the framework's counters and latencies so they can be
+// forwarded to a telemetry system. Implementations must be safe for
+// concurrent use.
+type Metrics interface {
+	// IncrCounter adds delta to the named counter
+	IncrCounter(name string, delta int64)
+
+	// ObserveLatency records how long the named operation took
+	ObserveLatency(name string, d time.Duration)
+}
+
+// noopMetrics discards everything; it is used when no Metrics is configured.
+type noopMetrics struct{}
+
+func (noopMetrics) IncrCounter(string, int64)            {}
+func (noopMetrics) ObserveLatency(string, time.Duration) {}
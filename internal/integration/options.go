@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package integration
+
+// getOpts - iterate the inbound Options and return a struct
+func getOpts(opt ...Option) options {
+	opts := getDefaultOptions()
+	for _, o := range opt {
+		o(&opts)
+	}
+	return opts
+}
+
+// Option - how Options are passed as arguments
+type Option func(*options)
+
+// options = how options are represented
+type options struct {
+	withTensorIndices  map[string]int
+	withMetrics        Metrics
+	withEffectiveState bool
+}
+
+func getDefaultOptions() options {
+	return options{
+		withMetrics: noopMetrics{},
+	}
+}
+
+// WithTensorIndices maps state keys to flat positions in the scope atom's
+// tensor. PropagateState writes numeric values of mapped keys into those
+// positions so the tensor stays aligned with the atom's Attributes.
+func WithTensorIndices(indices map[string]int) Option {
+	return func(o *options) {
+		o.withTensorIndices = indices
+	}
+}
+
+// WithMetrics reports the framework's counters and latencies to m. Without
+// this option they are discarded.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		if m != nil {
+			o.withMetrics = m
+		}
+	}
+}
+
+// WithEffectiveState makes QueryScope set ScopeInfo.EffectiveState to the
+// scope's state merged over the state it inherits from its ancestors.
+func WithEffectiveState() Option {
+	return func(o *options) {
+		o.withEffectiveState = true
+	}
+}
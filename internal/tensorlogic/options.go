@@ -0,0 +1,55 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package tensorlogic
+
+// getOpts - iterate the inbound Options and return a struct
+func getOpts(opt ...Option) options {
+	opts := getDefaultOptions()
+	for _, o := range opt {
+		o(&opts)
+	}
+	return opts
+}
+
+// Option - how Options are passed as arguments
+type Option func(*options)
+
+// options = how options are represented
+type options struct {
+	withRandSeed            int64
+	withRandSeedSet         bool
+	withAxis                string
+	withStrictProbabilities bool
+}
+
+func getDefaultOptions() options {
+	return options{}
+}
+
+// WithRandSeed seeds the framework's random source so that randomized
+// operations produce identical data across frameworks created with the same
+// seed. Without this option a time-based seed is used.
+func WithRandSeed(seed int64) Option {
+	return func(o *options) {
+		o.withRandSeed = seed
+		o.withRandSeedSet = true
+	}
+}
+
+// WithAxis restricts an operation to the named index. For Normalize each
+// slice along the axis is rescaled independently.
+func WithAxis(index string) Option {
+	return func(o *options) {
+		o.withAxis = index
+	}
+}
+
+// WithStrictProbabilities makes the framework reject the registration of a
+// ProbabilisticType variable that has a negative entry. By default such
+// variables are accepted.
+func WithStrictProbabilities() Option {
+	return func(o *options) {
+		o.withStrictProbabilities = true
+	}
+}
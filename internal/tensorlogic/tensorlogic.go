@@ -11,6 +11,13 @@ package tensorlogic
 import (
 	"context"
 	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/boundary/internal/errors"
 )
@@ -31,10 +38,51 @@ type Variable struct {
 	// Data holds the actual tensor data (flattened)
 	Data []float64
 
+	// IntData holds the tensor data (flattened) when DType is Int64
+	IntData []int64
+
+	// DType selects the element storage; empty means Float64
+	DType DataType
+
 	// Type specifies the variable type (symbolic, neural, probabilistic)
 	Type VariableType
 }
 
+// DataType defines the element type a variable's data is stored as.
+type DataType string
+
+const (
+	// Float64 stores elements in Data
+	Float64 DataType = "float64"
+
+	// Int64 stores elements in IntData
+	Int64 DataType = "int64"
+)
+
+// dtype returns the variable's data type, defaulting to Float64.
+func (v *Variable) dtype() DataType {
+	if v.DType == "" {
+		return Float64
+	}
+	return v.DType
+}
+
+// Clone returns a deep copy of v that shares no slices with it.
+func (v *Variable) Clone() *Variable {
+	if v == nil {
+		return nil
+	}
+	return &Variable{
+		Name:    v.Name,
+		Indices: slices.Clone(v.Indices),
+		Shape:   slices.Clone(v.Shape),
+		Data:    slices.Clone(v.Data),
+		IntData: slices.Clone(v.IntData),
+		DType:   v.DType,
+		Type:    v.Type,
+	}
+}
+
 // VariableType defines the type of tensor logic variable
 type VariableType string
 
@@ -73,43 +121,288 @@ type Framework struct {
 
 	// Equations stores the tensor equations in the system
 	Equations []*TensorEquation
+
+	// mu protects concurrent access to Variables and Equations
+	mu sync.RWMutex
+
+	// rng is the random source used by all randomized operations
+	rng *rand.Rand
+
+	// rngMu protects concurrent access to rng
+	rngMu sync.Mutex
+
+	// cache holds the snapshots shared by EvaluateCached, keyed by variable
+	// name
+	cache map[string]*Variable
+
+	// cacheMu protects concurrent access to cache
+	cacheMu sync.Mutex
+
+	// strictProbabilities makes registration reject ProbabilisticType
+	// variables with negative entries
+	strictProbabilities bool
 }
 
 // NewFramework creates a new tensor logic framework instance.
-func NewFramework(ctx context.Context) (*Framework, error) {
+// Supported options: WithRandSeed, WithStrictProbabilities
+func NewFramework(ctx context.Context, opt ...Option) (*Framework, error) {
 	const op = "tensorlogic.NewFramework"
-	
+
+	opts := getOpts(opt...)
+	seed := time.Now().UnixNano()
+	if opts.withRandSeedSet {
+		seed = opts.withRandSeed
+	}
+
 	f := &Framework{
-		Variables: make(map[string]*Variable),
-		Equations: make([]*TensorEquation, 0),
+		Variables:           make(map[string]*Variable),
+		Equations:           make([]*TensorEquation, 0),
+		rng:                 rand.New(rand.NewSource(seed)),
+		cache:               make(map[string]*Variable),
+		strictProbabilities: opts.withStrictProbabilities,
 	}
-	
+
 	return f, nil
 }
 
+// NewRandomVariable creates a variable whose Data is filled with values drawn
+// uniformly from [0, 1) using the framework's random source. The variable is
+// not registered.
+func (f *Framework) NewRandomVariable(ctx context.Context, name string, indices []string, shape []int, varType VariableType) (*Variable, error) {
+	const op = "tensorlogic.(Framework).NewRandomVariable"
+
+	if name == "" {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "variable name is empty")
+	}
+	if len(indices) != len(shape) {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "indices and shape lengths differ")
+	}
+	size := 1
+	for _, d := range shape {
+		if d <= 0 {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("invalid dimension %d", d))
+		}
+		size *= d
+	}
+
+	data := make([]float64, size)
+	f.rngMu.Lock()
+	for i := range data {
+		data[i] = f.rng.Float64()
+	}
+	f.rngMu.Unlock()
+
+	v := &Variable{
+		Name:    name,
+		Indices: append([]string(nil), indices...),
+		Shape:   append([]int(nil), shape...),
+		Data:    data,
+		Type:    varType,
+	}
+	return v, nil
+}
+
 // RegisterVariable registers a new variable in the tensor logic framework.
+// With WithStrictProbabilities a ProbabilisticType variable with a negative
+// entry is rejected.
 func (f *Framework) RegisterVariable(ctx context.Context, v *Variable) error {
 	const op = "tensorlogic.(Framework).RegisterVariable"
-	
-	if v == nil {
-		return errors.New(ctx, errors.InvalidParameter, op, "variable is nil")
+
+	if err := f.validateRegistration(v); err != nil {
+		return errors.New(ctx, errors.InvalidParameter, op, err.Error())
 	}
-	if v.Name == "" {
-		return errors.New(ctx, errors.InvalidParameter, op, "variable name is empty")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Variables[v.Name] = v
+	f.invalidate(v.Name)
+	return nil
+}
+
+// RegisterVariableStrict registers a variable like RegisterVariable, but
+// refuses to overwrite an existing variable of the same name that has a
+// different Shape or Type. Re-registering a variable with the same Shape and
+// Type succeeds and replaces the stored value.
+func (f *Framework) RegisterVariableStrict(ctx context.Context, v *Variable) error {
+	const op = "tensorlogic.(Framework).RegisterVariableStrict"
+
+	if err := f.validateRegistration(v); err != nil {
+		return errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if existing, ok := f.Variables[v.Name]; ok {
+		if !equalInts(existing.Shape, v.Shape) || existing.Type != v.Type {
+			return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("variable %s already registered with shape %v and type %s", v.Name, existing.Shape, existing.Type))
+		}
 	}
-	
 	f.Variables[v.Name] = v
+	f.invalidate(v.Name)
+	return nil
+}
+
+// GetVariable returns the registered variable with the given name. Unlike
+// Evaluate it does not copy the variable; the returned pointer is the live
+// stored value, so callers must not mutate it while other goroutines may be
+// using the framework.
+func (f *Framework) GetVariable(ctx context.Context, name string) (*Variable, error) {
+	const op = "tensorlogic.(Framework).GetVariable"
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	v, ok := f.Variables[name]
+	if !ok {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("variable %s not found", name))
+	}
+	return v, nil
+}
+
+// DeleteVariable removes the named variable along with every equation whose
+// Left side defines it.
+func (f *Framework) DeleteVariable(ctx context.Context, name string) error {
+	const op = "tensorlogic.(Framework).DeleteVariable"
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.Variables[name]; !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("variable %s not found", name))
+	}
+	delete(f.Variables, name)
+	f.invalidate(name)
+
+	equations := make([]*TensorEquation, 0, len(f.Equations))
+	for _, eq := range f.Equations {
+		if eq.Left.Name != name {
+			equations = append(equations, eq)
+		}
+	}
+	f.Equations = equations
+	return nil
+}
+
+// RenameVariable renames the variable oldName to newName. Equations
+// defining the variable or using it as an operand are updated to the new
+// name.
+func (f *Framework) RenameVariable(ctx context.Context, oldName, newName string) error {
+	const op = "tensorlogic.(Framework).RenameVariable"
+
+	if newName == "" {
+		return errors.New(ctx, errors.InvalidParameter, op, "new name is empty")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, ok := f.Variables[oldName]
+	if !ok {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("variable %s not found", oldName))
+	}
+	if _, ok := f.Variables[newName]; ok {
+		return errors.New(ctx, errors.NotUnique, op, fmt.Sprintf("variable %s already exists", newName))
+	}
+	delete(f.Variables, oldName)
+	v.Name = newName
+	f.Variables[newName] = v
+	f.invalidate(oldName)
+	f.invalidate(newName)
+
+	for _, eq := range f.Equations {
+		if eq.Left.Name == oldName {
+			eq.Left.Name = newName
+		}
+		eq.Right = renameOperand(eq.Right, oldName, newName)
+	}
+	return nil
+}
+
+// ListVariables returns copies of all registered variables sorted by name.
+// Mutating the returned variables does not affect the framework.
+func (f *Framework) ListVariables(ctx context.Context) []*Variable {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	vars := make([]*Variable, 0, len(f.Variables))
+	for _, v := range f.Variables {
+		vars = append(vars, v.Clone())
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+// ListEquations returns copies of all defined equations in the order they
+// were defined.
+func (f *Framework) ListEquations(ctx context.Context) []*TensorEquation {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	equations := make([]*TensorEquation, 0, len(f.Equations))
+	for _, eq := range f.Equations {
+		equations = append(equations, copyEquation(eq))
+	}
+	return equations
+}
+
+// GetEquations returns copies of the equations whose Left side is the named
+// variable, in the order they were defined.
+func (f *Framework) GetEquations(ctx context.Context, leftName string) []*TensorEquation {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	equations := make([]*TensorEquation, 0)
+	for _, eq := range f.Equations {
+		if eq.Left.Name == leftName {
+			equations = append(equations, copyEquation(eq))
+		}
+	}
+	return equations
+}
+
+// copyEquation returns a copy of eq whose Left shares no slices with it.
+func copyEquation(eq *TensorEquation) *TensorEquation {
+	c := *eq
+	c.Left = *eq.Left.Clone()
+	return &c
+}
+
+// RemoveEquation removes the first defined equation with the same Left
+// name, Right expression and Operation as eq, which may be a copy returned
+// by GetEquations or ListEquations.
+func (f *Framework) RemoveEquation(ctx context.Context, eq *TensorEquation) error {
+	const op = "tensorlogic.(Framework).RemoveEquation"
+
+	if eq == nil {
+		return errors.New(ctx, errors.InvalidParameter, op, "equation is nil")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := slices.IndexFunc(f.Equations, func(e *TensorEquation) bool {
+		return e.Left.Name == eq.Left.Name && e.Right == eq.Right && e.Operation == eq.Operation
+	})
+	if i < 0 {
+		return errors.New(ctx, errors.NotFound, op, fmt.Sprintf("equation %s = %s not found", eq.Left.Name, eq.Right))
+	}
+	f.Equations = slices.Delete(f.Equations, i, i+1)
 	return nil
 }
 
 // DefineEquation defines a new tensor equation in the framework.
 func (f *Framework) DefineEquation(ctx context.Context, eq *TensorEquation) error {
 	const op = "tensorlogic.(Framework).DefineEquation"
-	
+
 	if eq == nil {
 		return errors.New(ctx, errors.InvalidParameter, op, "equation is nil")
 	}
-	
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
 	f.Equations = append(f.Equations, eq)
 	return nil
 }
@@ -118,57 +411,803 @@ func (f *Framework) DefineEquation(ctx context.Context, eq *TensorEquation) erro
 // This implements the core tensor equation evaluation using Einstein summation.
 func (f *Framework) Evaluate(ctx context.Context, varName string) (*Variable, error) {
 	const op = "tensorlogic.(Framework).Evaluate"
-	
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
 	v, ok := f.Variables[varName]
 	if !ok {
-		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("variable %s not found", varName))
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("variable %s not found", varName))
 	}
-	
+
 	// Return a copy of the variable with evaluated data
+	return v.Clone(), nil
+}
+
+// EvaluateCached retrieves a variable like Evaluate, but returns a snapshot
+// shared by every caller until the variable is registered again, deleted or
+// renamed. It avoids copying Data on every call for large tensors that do
+// not change. The snapshot must be treated as immutable; callers that mutate
+// the result should use Evaluate. Changes made directly to Variables or to a
+// variable returned by GetVariable are not seen by the cache.
+func (f *Framework) EvaluateCached(ctx context.Context, varName string) (*Variable, error) {
+	const op = "tensorlogic.(Framework).EvaluateCached"
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	v, ok := f.Variables[varName]
+	if !ok {
+		return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("variable %s not found", varName))
+	}
+
+	f.cacheMu.Lock()
+	defer f.cacheMu.Unlock()
+
+	if snapshot, ok := f.cache[varName]; ok {
+		return snapshot, nil
+	}
+	if f.cache == nil {
+		f.cache = make(map[string]*Variable)
+	}
+	snapshot := v.Clone()
+	f.cache[varName] = snapshot
+	return snapshot, nil
+}
+
+// invalidate drops the cached snapshot of the named variable. The caller
+// must hold f.mu for writing.
+func (f *Framework) invalidate(name string) {
+	f.cacheMu.Lock()
+	defer f.cacheMu.Unlock()
+
+	delete(f.cache, name)
+}
+
+// EvaluateEquation computes the Left variable of eq from its Right
+// expression. The expression uses Einstein notation where each operand is a
+// registered variable name followed by an underscore and one character per
+// index, e.g. "A_ij * B_jk". Indices shared by the operands and absent from
+// eq.Left.Indices are summed over. Only a single binary product is currently
+// supported. The result is returned but not registered.
+func (f *Framework) EvaluateEquation(ctx context.Context, eq *TensorEquation) (*Variable, error) {
+	const op = "tensorlogic.(Framework).EvaluateEquation"
+
+	if eq == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "equation is nil")
+	}
+	if eq.Left.Name == "" {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "equation left variable name is empty")
+	}
+
+	terms, err := parseExpression(eq.Right)
+	if err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+	if len(terms) != 2 {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("expression %q: only binary products are supported", eq.Right))
+	}
+
+	f.mu.RLock()
+	operands := make([]*Variable, len(terms))
+	for i, t := range terms {
+		v, ok := f.Variables[t.name]
+		if !ok {
+			f.mu.RUnlock()
+			return nil, errors.New(ctx, errors.NotFound, op, fmt.Sprintf("operand %s not found", t.name))
+		}
+		operands[i] = v
+	}
+	f.mu.RUnlock()
+
+	for i, t := range terms {
+		if len(t.indices) != len(operands[i].Shape) {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("operand %s is rank %d but expression uses %d indices", t.name, len(operands[i].Shape), len(t.indices)))
+		}
+		if len(operands[i].Data) != numElements(operands[i].Shape) {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("operand %s data does not match its shape", t.name))
+		}
+	}
+
+	out := eq.Left.Indices
+	if len(out) == 0 {
+		out = freeIndices(terms[0].indices, terms[1].indices)
+	}
+	shape, data, err := contract(operands[0].Data, operands[0].Shape, terms[0].indices, operands[1].Data, operands[1].Shape, terms[1].indices, out)
+	if err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+
+	varType := eq.Left.Type
+	if varType == "" {
+		varType = HybridType
+	}
 	result := &Variable{
-		Name:    v.Name,
-		Indices: v.Indices,
-		Shape:   v.Shape,
-		Data:    make([]float64, len(v.Data)),
-		Type:    v.Type,
+		Name:    eq.Left.Name,
+		Indices: append([]string(nil), out...),
+		Shape:   shape,
+		Data:    data,
+		Type:    varType,
 	}
-	copy(result.Data, v.Data)
-	
 	return result, nil
 }
 
+// EvaluateAll evaluates every defined equation in dependency order. An
+// equation depends on another when one of its operands is the other's Left
+// variable. Each result is registered before its dependents are evaluated,
+// and all results are returned keyed by name. Cyclic definitions are
+// rejected with the cycle named in the error.
+func (f *Framework) EvaluateAll(ctx context.Context) (map[string]*Variable, error) {
+	const op = "tensorlogic.(Framework).EvaluateAll"
+
+	equations := f.ListEquations(ctx)
+	defining := make(map[string]*TensorEquation, len(equations))
+	deps := make(map[string][]string, len(equations))
+	for _, eq := range equations {
+		if _, ok := defining[eq.Left.Name]; ok {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("variable %s is defined by more than one equation", eq.Left.Name))
+		}
+		defining[eq.Left.Name] = eq
+		terms, err := parseExpression(eq.Right)
+		if err != nil {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+		}
+		for _, t := range terms {
+			deps[eq.Left.Name] = append(deps[eq.Left.Name], t.name)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(defining))
+	order := make([]*TensorEquation, 0, len(defining))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			start := slices.Index(path, name)
+			cycle := append(slices.Clone(path[start:]), name)
+			return errors.New(ctx, errors.CycleFound, op, fmt.Sprintf("equation cycle detected: %s", strings.Join(cycle, " -> ")))
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range deps[name] {
+			if _, ok := defining[dep]; !ok {
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		order = append(order, defining[name])
+		return nil
+	}
+	for _, eq := range equations {
+		if err := visit(eq.Left.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make(map[string]*Variable, len(order))
+	for _, eq := range order {
+		result, err := f.EvaluateEquation(ctx, eq)
+		if err != nil {
+			return nil, errors.Wrap(ctx, err, op)
+		}
+		if err := f.RegisterVariable(ctx, result); err != nil {
+			return nil, errors.Wrap(ctx, err, op)
+		}
+		results[result.Name] = result.Clone()
+	}
+	return results, nil
+}
+
 // Project performs a tensor projection operation (reduction along indices).
+// The result keeps the requested indices, in the order given, and sums over
+// every other index.
 func (f *Framework) Project(ctx context.Context, v *Variable, indices []string) (*Variable, error) {
 	const op = "tensorlogic.(Framework).Project"
-	
+
 	if v == nil {
 		return nil, errors.New(ctx, errors.InvalidParameter, op, "variable is nil")
 	}
-	
-	// Create projected variable (simplified implementation)
+	if err := validateData(v); err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+
+	keep := make([]int, 0, len(indices))
+	for _, idx := range indices {
+		pos := indexOf(v, idx)
+		if pos < 0 {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("index %s not found in variable %s", idx, v.Name))
+		}
+		keep = append(keep, pos)
+	}
+
+	shape, data := reduce(v, keep, nil)
 	result := &Variable{
 		Name:    v.Name + "_projected",
-		Indices: indices,
+		Indices: append([]string(nil), indices...),
+		Shape:   shape,
+		Data:    data,
+		Type:    v.Type,
+	}
+
+	return result, nil
+}
+
+// Contract sums v over the named indices and returns a variable over the
+// remaining indices. Axes sharing a contracted index name are summed along
+// their diagonal, so contracting "i" on a variable with indices ["i", "i"]
+// yields its trace as a rank-0 variable.
+func (f *Framework) Contract(ctx context.Context, v *Variable, indices []string) (*Variable, error) {
+	const op = "tensorlogic.(Framework).Contract"
+
+	if v == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "variable is nil")
+	}
+	if err := validateData(v); err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+
+	contracted := make(map[string]bool, len(indices))
+	for _, idx := range indices {
+		if !hasIndex(v, idx) {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("index %s not found in variable %s", idx, v.Name))
+		}
+		contracted[idx] = true
+	}
+
+	keep := make([]int, 0, len(v.Indices))
+	groups := make(map[string][]int)
+	for pos, idx := range v.Indices {
+		if contracted[idx] {
+			groups[idx] = append(groups[idx], pos)
+			continue
+		}
+		keep = append(keep, pos)
+	}
+
+	tied := make([][]int, 0, len(groups))
+	for idx, axes := range groups {
+		for _, a := range axes[1:] {
+			if v.Shape[a] != v.Shape[axes[0]] {
+				return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("index %s has mismatched dimensions", idx))
+			}
+		}
+		if len(axes) > 1 {
+			tied = append(tied, axes)
+		}
+	}
+
+	shape, data := reduce(v, keep, tied)
+	resultIndices := make([]string, 0, len(keep))
+	for _, pos := range keep {
+		resultIndices = append(resultIndices, v.Indices[pos])
+	}
+
+	result := &Variable{
+		Name:    v.Name + "_contracted",
+		Indices: resultIndices,
+		Shape:   shape,
+		Data:    data,
+		Type:    v.Type,
+	}
+	return result, nil
+}
+
+// Transpose returns a copy of v with its indices reordered to match order,
+// which must be a permutation of v.Indices. Shape and Data are rearranged
+// accordingly.
+func (f *Framework) Transpose(ctx context.Context, v *Variable, order []string) (*Variable, error) {
+	const op = "tensorlogic.(Framework).Transpose"
+
+	if v == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "variable is nil")
+	}
+	if err := validateData(v); err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+	if len(order) != len(v.Indices) {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("order %v is not a permutation of %v", order, v.Indices))
+	}
+
+	used := make([]bool, len(v.Indices))
+	perm := make([]int, 0, len(order))
+	for _, idx := range order {
+		pos := -1
+		for i, name := range v.Indices {
+			if name == idx && !used[i] {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("order %v is not a permutation of %v", order, v.Indices))
+		}
+		used[pos] = true
+		perm = append(perm, pos)
+	}
+
+	shape, data := reduce(v, perm, nil)
+	result := &Variable{
+		Name:    v.Name + "_transposed",
+		Indices: append([]string(nil), order...),
+		Shape:   shape,
+		Data:    data,
+		Type:    v.Type,
+	}
+	return result, nil
+}
+
+// Reshape returns a copy of v with a new shape and index names. The data is
+// carried over unchanged in row-major order, so newShape must describe the
+// same number of elements.
+func (f *Framework) Reshape(ctx context.Context, v *Variable, newShape []int, newIndices []string) (*Variable, error) {
+	const op = "tensorlogic.(Framework).Reshape"
+
+	if v == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "variable is nil")
+	}
+	if v.Data == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("variable %s has no data", v.Name))
+	}
+	if len(newIndices) != len(newShape) {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("%d indices given for shape %v", len(newIndices), newShape))
+	}
+	if numElements(newShape) != len(v.Data) {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("cannot reshape %d elements into shape %v", len(v.Data), newShape))
+	}
+
+	result := &Variable{
+		Name:    v.Name + "_reshaped",
+		Indices: append([]string(nil), newIndices...),
+		Shape:   append([]int(nil), newShape...),
+		Data:    append([]float64(nil), v.Data...),
+		Type:    v.Type,
+	}
+	return result, nil
+}
+
+// Slice returns the sub-tensor of v covering positions [start, end) along the
+// named index. All other indices keep their full dimensions.
+func (f *Framework) Slice(ctx context.Context, v *Variable, index string, start, end int) (*Variable, error) {
+	const op = "tensorlogic.(Framework).Slice"
+
+	if v == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "variable is nil")
+	}
+	if err := validateData(v); err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+	axis := indexOf(v, index)
+	if axis < 0 {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("index %s not found in variable %s", index, v.Name))
+	}
+	dim := v.Shape[axis]
+	if start < 0 || start >= end || end > dim {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("slice [%d, %d) out of bounds for index %s with dimension %d", start, end, index, dim))
+	}
+
+	shape := append([]int(nil), v.Shape...)
+	shape[axis] = end - start
+	inner := strides(v.Shape)[axis]
+	data := make([]float64, 0, numElements(shape))
+	for block := 0; block < len(v.Data); block += dim * inner {
+		data = append(data, v.Data[block+start*inner:block+end*inner]...)
+	}
+
+	result := &Variable{
+		Name:    v.Name + "_slice",
+		Indices: append([]string(nil), v.Indices...),
+		Shape:   shape,
+		Data:    data,
+		Type:    v.Type,
+	}
+	return result, nil
+}
+
+// Normalize returns a copy of v rescaled so that its Data sums to 1.0, as
+// expected of a ProbabilisticType distribution. By default the whole tensor
+// is normalized; WithAxis normalizes each slice along the named index
+// independently. An all-zero tensor or slice cannot be normalized.
+// Supported options: WithAxis
+func (f *Framework) Normalize(ctx context.Context, v *Variable, opt ...Option) (*Variable, error) {
+	const op = "tensorlogic.(Framework).Normalize"
+
+	if v == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "variable is nil")
+	}
+	if err := validateData(v); err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+
+	opts := getOpts(opt...)
+	data := append([]float64(nil), v.Data...)
+	if opts.withAxis == "" {
+		if err := normalizeSlice(data, 0, 1, len(data)); err != nil {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+		}
+	} else {
+		axis := indexOf(v, opts.withAxis)
+		if axis < 0 {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("index %s not found in variable %s", opts.withAxis, v.Name))
+		}
+		stride := strides(v.Shape)[axis]
+		for _, start := range sliceStarts(v.Shape, axis) {
+			if err := normalizeSlice(data, start, stride, v.Shape[axis]); err != nil {
+				return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+			}
+		}
+	}
+
+	result := &Variable{
+		Name:    v.Name + "_normalized",
+		Indices: append([]string(nil), v.Indices...),
+		Shape:   append([]int(nil), v.Shape...),
+		Data:    data,
+		Type:    v.Type,
+	}
+	return result, nil
+}
+
+// Norm returns the L2 norm of v's Data, the square root of the sum of its
+// squared elements.
+func (f *Framework) Norm(ctx context.Context, v *Variable) (float64, error) {
+	const op = "tensorlogic.(Framework).Norm"
+
+	if v == nil {
+		return 0, errors.New(ctx, errors.InvalidParameter, op, "variable is nil")
+	}
+	if err := validateData(v); err != nil {
+		return 0, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+	return l2Norm(v.Data), nil
+}
+
+// NormalizeL2 returns a copy of v rescaled so that the L2 norm of its Data
+// is 1.0, as used for embedding vectors. A zero tensor cannot be normalized.
+func (f *Framework) NormalizeL2(ctx context.Context, v *Variable) (*Variable, error) {
+	const op = "tensorlogic.(Framework).NormalizeL2"
+
+	if v == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "variable is nil")
+	}
+	if err := validateData(v); err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+	norm := l2Norm(v.Data)
+	if norm == 0 {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("cannot normalize zero tensor %s", v.Name))
+	}
+
+	data := make([]float64, len(v.Data))
+	for i, x := range v.Data {
+		data[i] = x / norm
+	}
+	result := &Variable{
+		Name:    v.Name + "_l2normalized",
+		Indices: append([]string(nil), v.Indices...),
+		Shape:   append([]int(nil), v.Shape...),
+		Data:    data,
+		Type:    v.Type,
+	}
+	return result, nil
+}
+
+// Softmax returns a copy of v with the softmax function applied to each
+// slice along the named axis, so that every slice is positive and sums to
+// 1.0. The maximum of each slice is subtracted before exponentiating to keep
+// large inputs from overflowing.
+func (f *Framework) Softmax(ctx context.Context, v *Variable, axis string) (*Variable, error) {
+	const op = "tensorlogic.(Framework).Softmax"
+
+	if v == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "variable is nil")
+	}
+	if err := validateData(v); err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+	pos := indexOf(v, axis)
+	if pos < 0 {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("index %s not found in variable %s", axis, v.Name))
+	}
+
+	data := append([]float64(nil), v.Data...)
+	stride := strides(v.Shape)[pos]
+	for _, start := range sliceStarts(v.Shape, pos) {
+		softmaxSlice(data, start, stride, v.Shape[pos])
+	}
+
+	result := &Variable{
+		Name:    v.Name + "_softmax",
+		Indices: append([]string(nil), v.Indices...),
+		Shape:   append([]int(nil), v.Shape...),
+		Data:    data,
 		Type:    v.Type,
 	}
-	
 	return result, nil
 }
 
 // Join performs a tensor join operation (generalized Einstein summation).
+// Indices shared by v1 and v2 are summed over and the result keeps the
+// remaining indices of v1 followed by those of v2. Only Float64 variables
+// can be joined. When either operand has neither Data nor IntData the join
+// is symbolic: the resulting Indices and Shape are computed without Data. A
+// symbolic join errors listing the remaining indices whose dimension is not
+// given by either operand's Shape.
 func (f *Framework) Join(ctx context.Context, v1, v2 *Variable) (*Variable, error) {
 	const op = "tensorlogic.(Framework).Join"
-	
+
 	if v1 == nil || v2 == nil {
 		return nil, errors.New(ctx, errors.InvalidParameter, op, "one or both variables are nil")
 	}
-	
-	// Create joined variable (simplified implementation)
+
 	result := &Variable{
-		Name: v1.Name + "_join_" + v2.Name,
-		Type: HybridType,
+		Name:    v1.Name + "_join_" + v2.Name,
+		Indices: freeIndices(v1.Indices, v2.Indices),
+		Type:    HybridType,
+	}
+	for _, v := range []*Variable{v1, v2} {
+		if v.dtype() != Float64 {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("variable %s has unsupported dtype %s", v.Name, v.dtype()))
+		}
+	}
+	if (v1.Data == nil && v1.IntData == nil) || (v2.Data == nil && v2.IntData == nil) {
+		shape, err := inferShape(v1, v2, result.Indices)
+		if err != nil {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+		}
+		result.Shape = shape
+		return result, nil
+	}
+
+	for _, v := range []*Variable{v1, v2} {
+		if err := validateData(v); err != nil {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+		}
+	}
+	shape, data, err := contract(v1.Data, v1.Shape, v1.Indices, v2.Data, v2.Shape, v2.Indices, result.Indices)
+	if err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+	result.Shape = shape
+	result.Data = data
+
+	return result, nil
+}
+
+// MatMul returns the matrix product of the rank-2 variables a and b, which
+// requires a.Shape[1] == b.Shape[0]. The result has indices ["i", "k"]
+// whatever the index names of a and b; it is the Join of a over ["i", "j"]
+// with b over ["j", "k"].
+func (f *Framework) MatMul(ctx context.Context, a, b *Variable) (*Variable, error) {
+	const op = "tensorlogic.(Framework).MatMul"
+
+	for _, v := range []*Variable{a, b} {
+		if err := validateMatrix(v); err != nil {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+		}
+	}
+	if a.Shape[1] != b.Shape[0] {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("cannot multiply %s of shape %v by %s of shape %v", a.Name, a.Shape, b.Name, b.Shape))
+	}
+
+	indices := []string{"i", "k"}
+	shape, data, err := contract(a.Data, a.Shape, []string{"i", "j"}, b.Data, b.Shape, []string{"j", "k"}, indices)
+	if err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+	return &Variable{
+		Name:    a.Name + "_matmul_" + b.Name,
+		Indices: indices,
+		Shape:   shape,
+		Data:    data,
+		Type:    combinedType(a.Type, b.Type),
+	}, nil
+}
+
+// Trace returns the sum of the diagonal of the square rank-2 variable v as
+// a rank-0 variable with a single Data entry. It is Contract over both axes
+// of v tied together, whatever their index names.
+func (f *Framework) Trace(ctx context.Context, v *Variable) (*Variable, error) {
+	const op = "tensorlogic.(Framework).Trace"
+
+	if err := validateMatrix(v); err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+	if v.Shape[0] != v.Shape[1] {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("variable %s is not square: shape %v", v.Name, v.Shape))
+	}
+
+	shape, data := reduce(v, nil, [][]int{{0, 1}})
+	return &Variable{
+		Name:    v.Name + "_trace",
+		Indices: []string{},
+		Shape:   shape,
+		Data:    data,
+		Type:    v.Type,
+	}, nil
+}
+
+// Invert reverses a rank-2 access tensor, swapping its two indices and
+// transposing its data. Given a user-to-resource matrix it returns the
+// resource-to-user mapping.
+func (f *Framework) Invert(ctx context.Context, v *Variable) (*Variable, error) {
+	const op = "tensorlogic.(Framework).Invert"
+
+	if v == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "variable is nil")
+	}
+	if len(v.Shape) != 2 || len(v.Indices) != 2 {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("variable %s is not rank-2", v.Name))
+	}
+	rows, cols := v.Shape[0], v.Shape[1]
+	if len(v.Data) != rows*cols {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("variable %s data length %d does not match shape %v", v.Name, len(v.Data), v.Shape))
+	}
+
+	data := make([]float64, len(v.Data))
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			data[j*rows+i] = v.Data[i*cols+j]
+		}
+	}
+
+	result := &Variable{
+		Name:    v.Name + "_inverted",
+		Indices: []string{v.Indices[1], v.Indices[0]},
+		Shape:   []int{cols, rows},
+		Data:    data,
+		Type:    v.Type,
+	}
+	return result, nil
+}
+
+// Add returns the element-wise sum of v1 and v2, which must have identical
+// Indices, Shape and DType.
+func (f *Framework) Add(ctx context.Context, v1, v2 *Variable) (*Variable, error) {
+	const op = "tensorlogic.(Framework).Add"
+
+	result, err := elementwise(v1, v2, "add",
+		func(a, b float64) float64 { return a + b },
+		func(a, b int64) int64 { return a + b })
+	if err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+	return result, nil
+}
+
+// AddBroadcast returns the element-wise sum of v1 and v2 with NumPy-style
+// broadcasting. Indices are aligned by name rather than position: an index
+// missing from one operand, or present with dimension 1, is broadcast
+// against the other. The result has v1's indices followed by any indices
+// only v2 has.
+func (f *Framework) AddBroadcast(ctx context.Context, v1, v2 *Variable) (*Variable, error) {
+	const op = "tensorlogic.(Framework).AddBroadcast"
+
+	if v1 == nil || v2 == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "one or both variables are nil")
+	}
+	for _, v := range []*Variable{v1, v2} {
+		if err := validateData(v); err != nil {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+		}
+	}
+
+	indices := append([]string(nil), v1.Indices...)
+	for _, idx := range v2.Indices {
+		if !hasIndex(v1, idx) {
+			indices = append(indices, idx)
+		}
+	}
+
+	shape := make([]int, len(indices))
+	for k, idx := range indices {
+		d1, d2 := 1, 1
+		if pos := indexOf(v1, idx); pos >= 0 {
+			d1 = v1.Shape[pos]
+		}
+		if pos := indexOf(v2, idx); pos >= 0 {
+			d2 = v2.Shape[pos]
+		}
+		switch {
+		case d1 == d2, d2 == 1:
+			shape[k] = d1
+		case d1 == 1:
+			shape[k] = d2
+		default:
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("index %s has incompatible dimensions %d and %d", idx, d1, d2))
+		}
+	}
+
+	w1, w2 := broadcastWeights(v1, indices), broadcastWeights(v2, indices)
+	data := make([]float64, numElements(shape))
+	coords := make([]int, len(shape))
+	for i := range data {
+		o1, o2 := 0, 0
+		for k, c := range coords {
+			o1 += c * w1[k]
+			o2 += c * w2[k]
+		}
+		data[i] = v1.Data[o1] + v2.Data[o2]
+
+		for a := len(coords) - 1; a >= 0; a-- {
+			coords[a]++
+			if coords[a] < shape[a] {
+				break
+			}
+			coords[a] = 0
+		}
+	}
+
+	result := &Variable{
+		Name:    v1.Name + "_add_" + v2.Name,
+		Indices: indices,
+		Shape:   shape,
+		Data:    data,
+		Type:    combinedType(v1.Type, v2.Type),
+	}
+	return result, nil
+}
+
+// Multiply returns the element-wise (Hadamard) product of v1 and v2, which
+// must have identical Indices, Shape and DType.
+func (f *Framework) Multiply(ctx context.Context, v1, v2 *Variable) (*Variable, error) {
+	const op = "tensorlogic.(Framework).Multiply"
+
+	result, err := elementwise(v1, v2, "mul",
+		func(a, b float64) float64 { return a * b },
+		func(a, b int64) int64 { return a * b })
+	if err != nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+	}
+	return result, nil
+}
+
+// Outer returns the outer product of two rank-1 variables. The result has
+// v1's index followed by v2's, with Data[i*len(v2)+j] = v1[i] * v2[j].
+func (f *Framework) Outer(ctx context.Context, v1, v2 *Variable) (*Variable, error) {
+	const op = "tensorlogic.(Framework).Outer"
+
+	if v1 == nil || v2 == nil {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, "one or both variables are nil")
+	}
+	for _, v := range []*Variable{v1, v2} {
+		if len(v.Shape) != 1 || len(v.Indices) != 1 {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("variable %s is not rank-1", v.Name))
+		}
+		if err := validateData(v); err != nil {
+			return nil, errors.New(ctx, errors.InvalidParameter, op, err.Error())
+		}
+	}
+	if v1.Indices[0] == v2.Indices[0] {
+		return nil, errors.New(ctx, errors.InvalidParameter, op, fmt.Sprintf("both variables use index %s", v1.Indices[0]))
+	}
+
+	rows, cols := v1.Shape[0], v2.Shape[0]
+	data := make([]float64, rows*cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			data[i*cols+j] = v1.Data[i] * v2.Data[j]
+		}
+	}
+
+	result := &Variable{
+		Name:    v1.Name + "_outer_" + v2.Name,
+		Indices: []string{v1.Indices[0], v2.Indices[0]},
+		Shape:   []int{rows, cols},
+		Data:    data,
+		Type:    combinedType(v1.Type, v2.Type),
 	}
-	
 	return result, nil
 }
 
@@ -181,3 +1220,517 @@ func (f *Framework) IntegrateWithBoundary(ctx context.Context) error {
 	// All Boundary variables can now be expressed as tensor equations
 	return nil
 }
+
+// numElements returns the number of elements described by shape.
+func numElements(shape []int) int {
+	n := 1
+	for _, d := range shape {
+		n *= d
+	}
+	return n
+}
+
+// validateData checks that v has non-nil Data consistent with its Indices and
+// Shape.
+func validateData(v *Variable) error {
+	if v.Data == nil {
+		return fmt.Errorf("variable %s has no data", v.Name)
+	}
+	if len(v.Indices) != len(v.Shape) {
+		return fmt.Errorf("variable %s has %d indices but shape %v", v.Name, len(v.Indices), v.Shape)
+	}
+	if len(v.Data) != numElements(v.Shape) {
+		return fmt.Errorf("variable %s data length %d does not match shape %v", v.Name, len(v.Data), v.Shape)
+	}
+	return nil
+}
+
+// validateVariable checks that v is fit to be registered.
+func validateVariable(v *Variable) error {
+	if v == nil {
+		return fmt.Errorf("variable is nil")
+	}
+	if v.Name == "" {
+		return fmt.Errorf("variable name is empty")
+	}
+	// Either may be empty for scalars and unshaped placeholders, but an
+	// index is needed for every dimension once both are given.
+	if len(v.Indices) > 0 && len(v.Shape) > 0 && len(v.Indices) != len(v.Shape) {
+		return fmt.Errorf("variable %s has %d indices %v but shape %v of rank %d", v.Name, len(v.Indices), v.Indices, v.Shape, len(v.Shape))
+	}
+	// Data may be nil for lazily allocated variables, but when present it
+	// must agree with the shape.
+	switch v.dtype() {
+	case Float64:
+		if len(v.Shape) > 0 && v.Data != nil && len(v.Data) != numElements(v.Shape) {
+			return fmt.Errorf("variable %s data length %d does not match shape %v", v.Name, len(v.Data), v.Shape)
+		}
+	case Int64:
+		if len(v.Shape) > 0 && v.IntData != nil && len(v.IntData) != numElements(v.Shape) {
+			return fmt.Errorf("variable %s data length %d does not match shape %v", v.Name, len(v.IntData), v.Shape)
+		}
+	default:
+		return fmt.Errorf("variable %s has unsupported dtype %s", v.Name, v.DType)
+	}
+	return nil
+}
+
+// validateRegistration checks that v is fit to be registered in f, which
+// with WithStrictProbabilities also requires a ProbabilisticType variable to
+// have no negative entries.
+func (f *Framework) validateRegistration(v *Variable) error {
+	if err := validateVariable(v); err != nil {
+		return err
+	}
+	if f.strictProbabilities && v.Type == ProbabilisticType {
+		for _, p := range v.Data {
+			if p < 0 {
+				return fmt.Errorf("probabilistic variable %s has negative entry %v", v.Name, p)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMatrix checks that v is a rank-2 Float64 variable with data
+// consistent with its Shape. Its index names are not checked.
+func validateMatrix(v *Variable) error {
+	if v == nil {
+		return fmt.Errorf("variable is nil")
+	}
+	if v.dtype() != Float64 {
+		return fmt.Errorf("variable %s has unsupported dtype %s", v.Name, v.dtype())
+	}
+	if len(v.Shape) != 2 {
+		return fmt.Errorf("variable %s has rank %d, want 2", v.Name, len(v.Shape))
+	}
+	if v.Data == nil {
+		return fmt.Errorf("variable %s has no data", v.Name)
+	}
+	if len(v.Data) != numElements(v.Shape) {
+		return fmt.Errorf("variable %s data length %d does not match shape %v", v.Name, len(v.Data), v.Shape)
+	}
+	return nil
+}
+
+// hasIndex reports whether v has an index with the given name.
+func hasIndex(v *Variable, idx string) bool {
+	return indexOf(v, idx) >= 0
+}
+
+// indexOf returns the position of the first index with the given name, or -1
+// if v has no such index.
+func indexOf(v *Variable, idx string) int {
+	for pos, i := range v.Indices {
+		if i == idx {
+			return pos
+		}
+	}
+	return -1
+}
+
+// reduce sums v's data into a tensor over the axes in keep, in the order
+// given. Every other axis is summed over. Each group in tied lists axes whose
+// coordinates must be equal for an element to contribute, which restricts
+// the sum to the diagonal of those axes.
+func reduce(v *Variable, keep []int, tied [][]int) ([]int, []float64) {
+	shape := make([]int, len(keep))
+	for k, pos := range keep {
+		shape[k] = v.Shape[pos]
+	}
+	outStrides := strides(shape)
+	data := make([]float64, numElements(shape))
+
+	coords := make([]int, len(v.Shape))
+	for _, val := range v.Data {
+		onDiagonal := true
+		for _, axes := range tied {
+			for _, a := range axes[1:] {
+				if coords[a] != coords[axes[0]] {
+					onDiagonal = false
+				}
+			}
+		}
+		if onDiagonal {
+			offset := 0
+			for k, pos := range keep {
+				offset += coords[pos] * outStrides[k]
+			}
+			data[offset] += val
+		}
+		// advance the row-major coordinate counter
+		for a := len(coords) - 1; a >= 0; a-- {
+			coords[a]++
+			if coords[a] < v.Shape[a] {
+				break
+			}
+			coords[a] = 0
+		}
+	}
+
+	return shape, data
+}
+
+// strides returns the row-major strides for shape.
+func strides(shape []int) []int {
+	st := make([]int, len(shape))
+	acc := 1
+	for i := len(shape) - 1; i >= 0; i-- {
+		st[i] = acc
+		acc *= shape[i]
+	}
+	return st
+}
+
+// term is a single operand of a parsed Einstein-notation expression.
+type term struct {
+	name    string
+	indices []string
+}
+
+// parseExpression parses a product of operands such as "A_ij * B_jk" into
+// its terms. The text after an operand's last underscore lists its indices,
+// one character each.
+func parseExpression(expr string) ([]term, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	parts := strings.Split(expr, "*")
+	terms := make([]term, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		sep := strings.LastIndex(p, "_")
+		if sep <= 0 || sep == len(p)-1 {
+			return nil, fmt.Errorf("malformed operand %q in expression %q", p, expr)
+		}
+		indices := make([]string, 0, len(p)-sep-1)
+		for _, r := range p[sep+1:] {
+			indices = append(indices, string(r))
+		}
+		terms = append(terms, term{name: p[:sep], indices: indices})
+	}
+	return terms, nil
+}
+
+// renameOperand returns expr with every operand named oldName renamed to
+// newName. An expression that does not parse or does not use oldName is
+// returned unchanged.
+func renameOperand(expr, oldName, newName string) string {
+	terms, err := parseExpression(expr)
+	if err != nil || !slices.ContainsFunc(terms, func(t term) bool { return t.name == oldName }) {
+		return expr
+	}
+	parts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		name := t.name
+		if name == oldName {
+			name = newName
+		}
+		parts = append(parts, name+"_"+strings.Join(t.indices, ""))
+	}
+	return strings.Join(parts, " * ")
+}
+
+// freeIndices returns the indices that appear in exactly one of a and b, in
+// order of first appearance.
+func freeIndices(a, b []string) []string {
+	count := make(map[string]int)
+	for _, i := range append(append([]string(nil), a...), b...) {
+		count[i]++
+	}
+	free := make([]string, 0)
+	for _, i := range append(append([]string(nil), a...), b...) {
+		if count[i] == 1 {
+			free = append(free, i)
+		}
+	}
+	return free
+}
+
+// inferShape returns the dimensions of the out indices of a join of a and b,
+// taking each from whichever operand has a Shape. It errors listing the out
+// indices whose dimensions neither Shape gives; a fully contracted join,
+// with no out indices, has an empty shape.
+func inferShape(a, b *Variable, out []string) ([]int, error) {
+	dims := make(map[string]int)
+	for _, v := range []*Variable{a, b} {
+		if len(v.Shape) == 0 {
+			continue
+		}
+		if len(v.Shape) != len(v.Indices) {
+			return nil, fmt.Errorf("variable %s has %d indices %v but shape %v of rank %d", v.Name, len(v.Indices), v.Indices, v.Shape, len(v.Shape))
+		}
+		for pos, idx := range v.Indices {
+			if d, ok := dims[idx]; ok && d != v.Shape[pos] {
+				return nil, fmt.Errorf("index %s has mismatched dimensions %d and %d", idx, d, v.Shape[pos])
+			}
+			dims[idx] = v.Shape[pos]
+		}
+	}
+
+	shape := make([]int, 0, len(out))
+	var unknown []string
+	for _, idx := range out {
+		d, ok := dims[idx]
+		if !ok {
+			unknown = append(unknown, idx)
+			continue
+		}
+		shape = append(shape, d)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("cannot infer dimensions of indices %v: neither %s nor %s has a shape for them", unknown, a.Name, b.Name)
+	}
+	return shape, nil
+}
+
+// contract computes the generalized Einstein product of two tensors:
+// out[o...] = sum over all other indices of a[ai...] * b[bi...]. Indices in
+// out must appear in at least one operand, and an index shared by both
+// operands must have the same dimension in each.
+func contract(aData []float64, aShape []int, aIdx []string, bData []float64, bShape []int, bIdx []string, out []string) ([]int, []float64, error) {
+	dims := make(map[string]int)
+	order := make([]string, 0, len(aIdx)+len(bIdx))
+	for _, operand := range []struct {
+		idx   []string
+		shape []int
+	}{{aIdx, aShape}, {bIdx, bShape}} {
+		for pos, idx := range operand.idx {
+			d, ok := dims[idx]
+			if !ok {
+				dims[idx] = operand.shape[pos]
+				order = append(order, idx)
+				continue
+			}
+			if d != operand.shape[pos] {
+				return nil, nil, fmt.Errorf("index %s has mismatched dimensions %d and %d", idx, d, operand.shape[pos])
+			}
+		}
+	}
+
+	shape := make([]int, len(out))
+	for k, idx := range out {
+		d, ok := dims[idx]
+		if !ok {
+			return nil, nil, fmt.Errorf("output index %s does not appear in any operand", idx)
+		}
+		shape[k] = d
+	}
+
+	// position of every index in the iteration order
+	slot := make(map[string]int, len(order))
+	for i, idx := range order {
+		slot[idx] = i
+	}
+	offsets := func(idx []string, shape []int) []int {
+		st := strides(shape)
+		w := make([]int, len(order))
+		for pos, i := range idx {
+			w[slot[i]] += st[pos]
+		}
+		return w
+	}
+	aw, bw, ow := offsets(aIdx, aShape), offsets(bIdx, bShape), offsets(out, shape)
+
+	iterShape := make([]int, len(order))
+	for i, idx := range order {
+		iterShape[i] = dims[idx]
+	}
+	data := make([]float64, numElements(shape))
+	if numElements(iterShape) == 0 {
+		return shape, data, nil
+	}
+
+	coords := make([]int, len(order))
+	for {
+		ao, bo, oo := 0, 0, 0
+		for i, c := range coords {
+			ao += c * aw[i]
+			bo += c * bw[i]
+			oo += c * ow[i]
+		}
+		data[oo] += aData[ao] * bData[bo]
+
+		a := len(coords) - 1
+		for ; a >= 0; a-- {
+			coords[a]++
+			if coords[a] < iterShape[a] {
+				break
+			}
+			coords[a] = 0
+		}
+		if a < 0 {
+			break
+		}
+	}
+
+	return shape, data, nil
+}
+
+// elementwise applies fn, or ifn for Int64 variables, to each pair of
+// elements of v1 and v2, which must share a dtype. The result keeps the
+// inputs' type when they agree and is HybridType otherwise.
+func elementwise(v1, v2 *Variable, name string, fn func(a, b float64) float64, ifn func(a, b int64) int64) (*Variable, error) {
+	if v1 == nil || v2 == nil {
+		return nil, fmt.Errorf("one or both variables are nil")
+	}
+	if v1.dtype() != v2.dtype() {
+		return nil, fmt.Errorf("dtype mismatch: %s and %s", v1.dtype(), v2.dtype())
+	}
+	if !equalInts(v1.Shape, v2.Shape) {
+		return nil, fmt.Errorf("shape mismatch: %v and %v", v1.Shape, v2.Shape)
+	}
+	if !equalStrings(v1.Indices, v2.Indices) {
+		return nil, fmt.Errorf("index mismatch: %v and %v", v1.Indices, v2.Indices)
+	}
+
+	result := &Variable{
+		Name:    v1.Name + "_" + name + "_" + v2.Name,
+		Indices: append([]string(nil), v1.Indices...),
+		Shape:   append([]int(nil), v1.Shape...),
+		DType:   v1.DType,
+		Type:    combinedType(v1.Type, v2.Type),
+	}
+
+	switch v1.dtype() {
+	case Int64:
+		if v1.IntData == nil || v2.IntData == nil {
+			return nil, fmt.Errorf("one or both variables have no data")
+		}
+		if len(v1.IntData) != len(v2.IntData) {
+			return nil, fmt.Errorf("data length mismatch: %d and %d", len(v1.IntData), len(v2.IntData))
+		}
+		result.IntData = make([]int64, len(v1.IntData))
+		for i := range result.IntData {
+			result.IntData[i] = ifn(v1.IntData[i], v2.IntData[i])
+		}
+	case Float64:
+		if v1.Data == nil || v2.Data == nil {
+			return nil, fmt.Errorf("one or both variables have no data")
+		}
+		if len(v1.Data) != len(v2.Data) {
+			return nil, fmt.Errorf("data length mismatch: %d and %d", len(v1.Data), len(v2.Data))
+		}
+		result.Data = make([]float64, len(v1.Data))
+		for i := range result.Data {
+			result.Data[i] = fn(v1.Data[i], v2.Data[i])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported dtype %s", v1.DType)
+	}
+
+	return result, nil
+}
+
+// combinedType returns the shared type of two variables, or HybridType when
+// they differ.
+func combinedType(a, b VariableType) VariableType {
+	if a == b {
+		return a
+	}
+	return HybridType
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// broadcastWeights returns, for each of the given indices, the stride to
+// apply to v's data. Indices that v lacks or holds with dimension 1 get a
+// stride of zero so the same element is reused along them.
+func broadcastWeights(v *Variable, indices []string) []int {
+	st := strides(v.Shape)
+	w := make([]int, len(indices))
+	for k, idx := range indices {
+		if pos := indexOf(v, idx); pos >= 0 && v.Shape[pos] != 1 {
+			w[k] = st[pos]
+		}
+	}
+	return w
+}
+
+// sliceStarts returns the offset of the first element of every 1-D slice of
+// a tensor with the given shape that runs along axis.
+func sliceStarts(shape []int, axis int) []int {
+	starts := make([]int, 0, numElements(shape)/max(shape[axis], 1))
+	coords := make([]int, len(shape))
+	for offset := 0; offset < numElements(shape); offset++ {
+		if coords[axis] == 0 {
+			starts = append(starts, offset)
+		}
+		for a := len(coords) - 1; a >= 0; a-- {
+			coords[a]++
+			if coords[a] < shape[a] {
+				break
+			}
+			coords[a] = 0
+		}
+	}
+	return starts
+}
+
+// normalizeSlice rescales the n elements of data starting at start and
+// spaced by stride so that they sum to 1.
+func normalizeSlice(data []float64, start, stride, n int) error {
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		sum += data[start+i*stride]
+	}
+	if sum == 0 {
+		return fmt.Errorf("cannot normalize a slice that sums to zero")
+	}
+	for i := 0; i < n; i++ {
+		data[start+i*stride] /= sum
+	}
+	return nil
+}
+
+// l2Norm returns the square root of the sum of the squares of data.
+func l2Norm(data []float64) float64 {
+	sum := 0.0
+	for _, x := range data {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
+
+// softmaxSlice applies a numerically stable softmax to the n elements of data
+// starting at start and spaced stride apart.
+func softmaxSlice(data []float64, start, stride, n int) {
+	if n == 0 {
+		return
+	}
+	maxVal := math.Inf(-1)
+	for i := 0; i < n; i++ {
+		maxVal = math.Max(maxVal, data[start+i*stride])
+	}
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		e := math.Exp(data[start+i*stride] - maxVal)
+		data[start+i*stride] = e
+		sum += e
+	}
+	for i := 0; i < n; i++ {
+		data[start+i*stride] /= sum
+	}
+}
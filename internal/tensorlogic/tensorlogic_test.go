@@ -5,8 +5,12 @@ package tensorlogic
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"sync"
 	"testing"
 
+	"github.com/hashicorp/boundary/internal/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -77,6 +81,22 @@ func TestFramework_RegisterVariable(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "error on data length not matching shape",
+			setup: func() (*Framework, *Variable) {
+				f, _ := NewFramework(ctx)
+				v := &Variable{
+					Name:    "bad",
+					Indices: []string{"i", "j"},
+					Shape:   []int{3, 3},
+					Data:    []float64{1, 2, 3, 4},
+					Type:    SymbolicType,
+				}
+				return f, v
+			},
+			wantErr: true,
+			errMsg:  "data length 4 does not match shape [3 3]",
+		},
 		{
 			name: "error on nil variable",
 			setup: func() (*Framework, *Variable) {
@@ -242,12 +262,43 @@ func TestFramework_Project(t *testing.T) {
 					Name:    "matrix",
 					Indices: []string{"i", "j"},
 					Shape:   []int{3, 3},
+					Data:    []float64{1, 2, 3, 4, 5, 6, 7, 8, 9},
 					Type:    SymbolicType,
 				}
 				return f, v, []string{"i"}
 			},
 			wantErr: false,
 		},
+		{
+			name: "error on unknown index",
+			setup: func() (*Framework, *Variable, []string) {
+				f, _ := NewFramework(ctx)
+				v := &Variable{
+					Name:    "matrix",
+					Indices: []string{"i", "j"},
+					Shape:   []int{2, 2},
+					Data:    []float64{1, 2, 3, 4},
+				}
+				return f, v, []string{"k"}
+			},
+			wantErr: true,
+			errMsg:  "index k not found",
+		},
+		{
+			name: "error on data not matching shape",
+			setup: func() (*Framework, *Variable, []string) {
+				f, _ := NewFramework(ctx)
+				v := &Variable{
+					Name:    "matrix",
+					Indices: []string{"i", "j"},
+					Shape:   []int{3, 3},
+					Data:    []float64{1, 2, 3},
+				}
+				return f, v, []string{"i"}
+			},
+			wantErr: true,
+			errMsg:  "does not match shape",
+		},
 		{
 			name: "error on nil variable",
 			setup: func() (*Framework, *Variable, []string) {
@@ -277,6 +328,41 @@ func TestFramework_Project(t *testing.T) {
 	}
 }
 
+func TestFramework_Project_Values(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	v := &Variable{
+		Name:    "matrix",
+		Indices: []string{"i", "j"},
+		Shape:   []int{3, 3},
+		Data:    []float64{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		Type:    ProbabilisticType,
+	}
+
+	t.Run("project onto row index sums columns", func(t *testing.T) {
+		result, err := f.Project(ctx, v, []string{"i"})
+		require.NoError(t, err)
+		assert.Equal(t, []int{3}, result.Shape)
+		assert.Equal(t, []float64{6, 15, 24}, result.Data)
+		assert.Equal(t, ProbabilisticType, result.Type)
+	})
+
+	t.Run("project onto column index sums rows", func(t *testing.T) {
+		result, err := f.Project(ctx, v, []string{"j"})
+		require.NoError(t, err)
+		assert.Equal(t, []float64{12, 15, 18}, result.Data)
+	})
+
+	t.Run("project onto reordered indices transposes", func(t *testing.T) {
+		result, err := f.Project(ctx, v, []string{"j", "i"})
+		require.NoError(t, err)
+		assert.Equal(t, []int{3, 3}, result.Shape)
+		assert.Equal(t, []float64{1, 4, 7, 2, 5, 8, 3, 6, 9}, result.Data)
+	})
+}
+
 func TestFramework_Join(t *testing.T) {
 	ctx := context.Background()
 
@@ -293,11 +379,13 @@ func TestFramework_Join(t *testing.T) {
 				v1 := &Variable{
 					Name:    "A",
 					Indices: []string{"i", "j"},
+					Shape:   []int{2, 3},
 					Type:    SymbolicType,
 				}
 				v2 := &Variable{
 					Name:    "B",
 					Indices: []string{"j", "k"},
+					Shape:   []int{3, 4},
 					Type:    SymbolicType,
 				}
 				return f, v1, v2
@@ -454,3 +542,1323 @@ func TestFramework_MultipleEquations(t *testing.T) {
 
 	assert.Equal(t, 2, len(f.Equations))
 }
+
+func TestFramework_NewRandomVariable(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("same seed produces identical data", func(t *testing.T) {
+		f1, err := NewFramework(ctx, WithRandSeed(42))
+		require.NoError(t, err)
+		f2, err := NewFramework(ctx, WithRandSeed(42))
+		require.NoError(t, err)
+
+		v1, err := f1.NewRandomVariable(ctx, "w", []string{"i", "j"}, []int{4, 5}, NeuralType)
+		require.NoError(t, err)
+		v2, err := f2.NewRandomVariable(ctx, "w", []string{"i", "j"}, []int{4, 5}, NeuralType)
+		require.NoError(t, err)
+
+		require.Len(t, v1.Data, 20)
+		assert.Equal(t, v1.Data, v2.Data)
+	})
+
+	t.Run("different seeds produce different data", func(t *testing.T) {
+		f1, _ := NewFramework(ctx, WithRandSeed(1))
+		f2, _ := NewFramework(ctx, WithRandSeed(2))
+
+		v1, err := f1.NewRandomVariable(ctx, "w", []string{"i"}, []int{8}, NeuralType)
+		require.NoError(t, err)
+		v2, err := f2.NewRandomVariable(ctx, "w", []string{"i"}, []int{8}, NeuralType)
+		require.NoError(t, err)
+		assert.NotEqual(t, v1.Data, v2.Data)
+	})
+
+	t.Run("error on indices and shape mismatch", func(t *testing.T) {
+		f, _ := NewFramework(ctx)
+		_, err := f.NewRandomVariable(ctx, "w", []string{"i"}, []int{2, 2}, NeuralType)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "indices and shape lengths differ")
+	})
+}
+
+func TestFramework_Invert(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	t.Run("transposes a user to resource matrix", func(t *testing.T) {
+		v := &Variable{
+			Name:    "access",
+			Indices: []string{"user", "resource"},
+			Shape:   []int{2, 3},
+			Data:    []float64{1, 0, 1, 0, 1, 1},
+			Type:    SymbolicType,
+		}
+		result, err := f.Invert(ctx, v)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"resource", "user"}, result.Indices)
+		assert.Equal(t, []int{3, 2}, result.Shape)
+		assert.Equal(t, []float64{1, 0, 0, 1, 1, 1}, result.Data)
+		assert.Equal(t, SymbolicType, result.Type)
+		assert.Equal(t, []float64{1, 0, 1, 0, 1, 1}, v.Data)
+	})
+
+	t.Run("error on non rank-2 variable", func(t *testing.T) {
+		v := &Variable{Name: "vec", Indices: []string{"i"}, Shape: []int{3}, Data: []float64{1, 2, 3}}
+		_, err := f.Invert(ctx, v)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not rank-2")
+	})
+
+	t.Run("error on nil variable", func(t *testing.T) {
+		_, err := f.Invert(ctx, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "variable is nil")
+	})
+}
+
+func TestFramework_Contract(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	t.Run("contract 3x3 matrix to its trace", func(t *testing.T) {
+		v := &Variable{
+			Name:    "m",
+			Indices: []string{"i", "i"},
+			Shape:   []int{3, 3},
+			Data:    []float64{1, 2, 3, 4, 5, 6, 7, 8, 9},
+			Type:    SymbolicType,
+		}
+		result, err := f.Contract(ctx, v, []string{"i"})
+		require.NoError(t, err)
+		assert.Empty(t, result.Indices)
+		assert.Empty(t, result.Shape)
+		assert.Equal(t, []float64{15}, result.Data)
+		assert.Equal(t, SymbolicType, result.Type)
+	})
+
+	t.Run("contract one axis of a 2x3x4 tensor", func(t *testing.T) {
+		data := make([]float64, 24)
+		for i := range data {
+			data[i] = float64(i)
+		}
+		v := &Variable{
+			Name:    "t",
+			Indices: []string{"i", "j", "k"},
+			Shape:   []int{2, 3, 4},
+			Data:    data,
+			Type:    NeuralType,
+		}
+		result, err := f.Contract(ctx, v, []string{"j"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"i", "k"}, result.Indices)
+		assert.Equal(t, []int{2, 4}, result.Shape)
+		// result[i][k] = sum_j data[i*12 + j*4 + k]
+		expected := make([]float64, 8)
+		for i := 0; i < 2; i++ {
+			for k := 0; k < 4; k++ {
+				for j := 0; j < 3; j++ {
+					expected[i*4+k] += data[i*12+j*4+k]
+				}
+			}
+		}
+		assert.Equal(t, expected, result.Data)
+	})
+
+	t.Run("error on unknown index", func(t *testing.T) {
+		v := &Variable{Name: "m", Indices: []string{"i", "j"}, Shape: []int{2, 2}, Data: []float64{1, 2, 3, 4}}
+		_, err := f.Contract(ctx, v, []string{"k"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "index k not found")
+	})
+
+	t.Run("error on nil variable", func(t *testing.T) {
+		_, err := f.Contract(ctx, nil, []string{"i"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "variable is nil")
+	})
+}
+
+func TestFramework_Concurrency(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, f.RegisterVariable(ctx, &Variable{
+		Name:    "shared",
+		Indices: []string{"i"},
+		Shape:   []int{3},
+		Data:    []float64{1, 2, 3},
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, f.RegisterVariable(ctx, &Variable{
+				Name:    fmt.Sprintf("v%d", i),
+				Indices: []string{"i"},
+				Shape:   []int{1},
+				Data:    []float64{float64(i)},
+			}))
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := f.Evaluate(ctx, "shared")
+			assert.NoError(t, err)
+		}()
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, f.DefineEquation(ctx, &TensorEquation{
+				Left:  Variable{Name: fmt.Sprintf("e%d", i)},
+				Right: "shared_i",
+			}))
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, f.Variables, 21)
+	assert.Len(t, f.Equations, 20)
+}
+
+func TestFramework_GetVariable(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	v := &Variable{Name: "x", Indices: []string{"i"}, Shape: []int{2}, Data: []float64{1, 2}}
+	require.NoError(t, f.RegisterVariable(ctx, v))
+
+	t.Run("returns the stored pointer", func(t *testing.T) {
+		got, err := f.GetVariable(ctx, "x")
+		require.NoError(t, err)
+		assert.Same(t, v, got)
+	})
+
+	t.Run("error on non-existent variable", func(t *testing.T) {
+		got, err := f.GetVariable(ctx, "nonexistent")
+		require.Error(t, err)
+		assert.Nil(t, got)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+func TestFramework_DeleteVariable(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("delete cascades to defining equations", func(t *testing.T) {
+		f, err := NewFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "C"}))
+		require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "D"}))
+		require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "C"}, Right: "A_ij * B_jk"}))
+		require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "D"}, Right: "C_ik * E_kl"}))
+		require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "C"}, Right: "X_ij * Y_jk"}))
+
+		require.NoError(t, f.DeleteVariable(ctx, "C"))
+
+		assert.NotContains(t, f.Variables, "C")
+		assert.Contains(t, f.Variables, "D")
+		require.Len(t, f.Equations, 1)
+		assert.Equal(t, "D", f.Equations[0].Left.Name)
+	})
+
+	t.Run("error on non-existent variable", func(t *testing.T) {
+		f, err := NewFramework(ctx)
+		require.NoError(t, err)
+
+		err = f.DeleteVariable(ctx, "nonexistent")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+func TestFramework_EvaluateEquation(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *Framework {
+		f, err := NewFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, f.RegisterVariable(ctx, &Variable{
+			Name:    "A",
+			Indices: []string{"i", "j"},
+			Shape:   []int{2, 3},
+			Data:    []float64{1, 2, 3, 4, 5, 6},
+		}))
+		require.NoError(t, f.RegisterVariable(ctx, &Variable{
+			Name:    "B",
+			Indices: []string{"j", "k"},
+			Shape:   []int{3, 2},
+			Data:    []float64{7, 8, 9, 10, 11, 12},
+		}))
+		return f
+	}
+
+	t.Run("binary product matches matmul", func(t *testing.T) {
+		f := setup(t)
+		eq := &TensorEquation{
+			Left:      Variable{Name: "C", Indices: []string{"i", "k"}},
+			Right:     "A_ij * B_jk",
+			Operation: "join",
+		}
+		result, err := f.EvaluateEquation(ctx, eq)
+		require.NoError(t, err)
+		assert.Equal(t, "C", result.Name)
+		assert.Equal(t, []string{"i", "k"}, result.Indices)
+		assert.Equal(t, []int{2, 2}, result.Shape)
+
+		// manual matmul
+		a, b := f.Variables["A"].Data, f.Variables["B"].Data
+		expected := make([]float64, 4)
+		for i := 0; i < 2; i++ {
+			for k := 0; k < 2; k++ {
+				for j := 0; j < 3; j++ {
+					expected[i*2+k] += a[i*3+j] * b[j*2+k]
+				}
+			}
+		}
+		assert.Equal(t, expected, result.Data)
+		assert.Equal(t, []float64{58, 64, 139, 154}, result.Data)
+	})
+
+	t.Run("left indices default to free indices", func(t *testing.T) {
+		f := setup(t)
+		result, err := f.EvaluateEquation(ctx, &TensorEquation{Left: Variable{Name: "C"}, Right: "A_ij*B_jk"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"i", "k"}, result.Indices)
+	})
+
+	t.Run("error on unknown operand", func(t *testing.T) {
+		f := setup(t)
+		_, err := f.EvaluateEquation(ctx, &TensorEquation{Left: Variable{Name: "C"}, Right: "A_ij * X_jk"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "operand X not found")
+	})
+
+	t.Run("error on malformed expression", func(t *testing.T) {
+		f := setup(t)
+		_, err := f.EvaluateEquation(ctx, &TensorEquation{Left: Variable{Name: "C"}, Right: "A * B_jk"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "malformed operand")
+	})
+
+	t.Run("error on mismatched shared dimension", func(t *testing.T) {
+		f := setup(t)
+		_, err := f.EvaluateEquation(ctx, &TensorEquation{Left: Variable{Name: "C"}, Right: "A_ij * B_kj"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "mismatched dimensions")
+	})
+}
+
+func TestFramework_AddMultiply(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	a := &Variable{Name: "a", Indices: []string{"i", "j"}, Shape: []int{2, 2}, Data: []float64{1, 2, 3, 4}, Type: NeuralType}
+	b := &Variable{Name: "b", Indices: []string{"i", "j"}, Shape: []int{2, 2}, Data: []float64{5, 6, 7, 8}, Type: NeuralType}
+
+	t.Run("add matching shapes", func(t *testing.T) {
+		result, err := f.Add(ctx, a, b)
+		require.NoError(t, err)
+		assert.Equal(t, []float64{6, 8, 10, 12}, result.Data)
+		assert.Equal(t, []int{2, 2}, result.Shape)
+		assert.Equal(t, NeuralType, result.Type)
+	})
+
+	t.Run("multiply matching shapes", func(t *testing.T) {
+		result, err := f.Multiply(ctx, a, b)
+		require.NoError(t, err)
+		assert.Equal(t, []float64{5, 12, 21, 32}, result.Data)
+	})
+
+	t.Run("mixed types produce hybrid", func(t *testing.T) {
+		c := &Variable{Name: "c", Indices: []string{"i", "j"}, Shape: []int{2, 2}, Data: []float64{1, 1, 1, 1}, Type: SymbolicType}
+		result, err := f.Add(ctx, a, c)
+		require.NoError(t, err)
+		assert.Equal(t, HybridType, result.Type)
+	})
+
+	t.Run("error on shape mismatch", func(t *testing.T) {
+		c := &Variable{Name: "c", Indices: []string{"i", "j"}, Shape: []int{1, 4}, Data: []float64{1, 2, 3, 4}}
+		_, err := f.Add(ctx, a, c)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "shape mismatch")
+	})
+
+	t.Run("error on index mismatch", func(t *testing.T) {
+		c := &Variable{Name: "c", Indices: []string{"i", "k"}, Shape: []int{2, 2}, Data: []float64{1, 2, 3, 4}}
+		_, err := f.Multiply(ctx, a, c)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "index mismatch")
+	})
+
+	t.Run("error on nil data", func(t *testing.T) {
+		c := &Variable{Name: "c", Indices: []string{"i", "j"}, Shape: []int{2, 2}}
+		_, err := f.Add(ctx, a, c)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no data")
+	})
+}
+
+func TestFramework_Transpose(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	v := &Variable{
+		Name:    "m",
+		Indices: []string{"i", "j"},
+		Shape:   []int{2, 3},
+		Data:    []float64{1, 2, 3, 4, 5, 6},
+		Type:    SymbolicType,
+	}
+
+	t.Run("transpose 2x3 matrix", func(t *testing.T) {
+		result, err := f.Transpose(ctx, v, []string{"j", "i"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"j", "i"}, result.Indices)
+		assert.Equal(t, []int{3, 2}, result.Shape)
+		assert.Equal(t, []float64{1, 4, 2, 5, 3, 6}, result.Data)
+		// element (i=1, j=2) moves to (j=2, i=1)
+		assert.Equal(t, v.Data[1*3+2], result.Data[2*2+1])
+	})
+
+	t.Run("identity order copies data", func(t *testing.T) {
+		result, err := f.Transpose(ctx, v, []string{"i", "j"})
+		require.NoError(t, err)
+		assert.Equal(t, v.Data, result.Data)
+	})
+
+	t.Run("error on bad permutation", func(t *testing.T) {
+		_, err := f.Transpose(ctx, v, []string{"i", "i"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a permutation")
+
+		_, err = f.Transpose(ctx, v, []string{"j"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a permutation")
+	})
+
+	t.Run("error on data not matching shape", func(t *testing.T) {
+		bad := &Variable{Name: "bad", Indices: []string{"i", "j"}, Shape: []int{2, 3}, Data: []float64{1}}
+		_, err := f.Transpose(ctx, bad, []string{"j", "i"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match shape")
+	})
+}
+
+func TestFramework_Reshape(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	v := &Variable{
+		Name:    "m",
+		Indices: []string{"i", "j"},
+		Shape:   []int{2, 3},
+		Data:    []float64{1, 2, 3, 4, 5, 6},
+		Type:    NeuralType,
+	}
+
+	t.Run("reshape 2x3 to 6x1", func(t *testing.T) {
+		result, err := f.Reshape(ctx, v, []int{6, 1}, []string{"n", "one"})
+		require.NoError(t, err)
+		assert.Equal(t, []int{6, 1}, result.Shape)
+		assert.Equal(t, []string{"n", "one"}, result.Indices)
+		assert.Equal(t, v.Data, result.Data)
+		assert.Equal(t, NeuralType, result.Type)
+	})
+
+	t.Run("reshape 2x3 to 3x2", func(t *testing.T) {
+		result, err := f.Reshape(ctx, v, []int{3, 2}, []string{"a", "b"})
+		require.NoError(t, err)
+		assert.Equal(t, []int{3, 2}, result.Shape)
+		assert.Equal(t, v.Data, result.Data)
+
+		result.Data[0] = 100
+		assert.Equal(t, float64(1), v.Data[0])
+	})
+
+	t.Run("error on element count mismatch", func(t *testing.T) {
+		_, err := f.Reshape(ctx, v, []int{4, 2}, []string{"a", "b"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot reshape 6 elements")
+	})
+
+	t.Run("error on index and shape length mismatch", func(t *testing.T) {
+		_, err := f.Reshape(ctx, v, []int{6}, []string{"a", "b"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2 indices given")
+	})
+}
+
+func TestFramework_AddBroadcast(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	m := &Variable{
+		Name:    "m",
+		Indices: []string{"i", "j"},
+		Shape:   []int{3, 4},
+		Data:    []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+		Type:    NeuralType,
+	}
+
+	t.Run("broadcast 3x1 bias over 3x4 matrix", func(t *testing.T) {
+		bias := &Variable{Name: "bias", Indices: []string{"i", "j"}, Shape: []int{3, 1}, Data: []float64{100, 200, 300}, Type: NeuralType}
+		result, err := f.AddBroadcast(ctx, m, bias)
+		require.NoError(t, err)
+		assert.Equal(t, []int{3, 4}, result.Shape)
+		assert.Equal(t, []float64{
+			100, 101, 102, 103,
+			204, 205, 206, 207,
+			308, 309, 310, 311,
+		}, result.Data)
+
+		// operand order does not matter for the values
+		reversed, err := f.AddBroadcast(ctx, bias, m)
+		require.NoError(t, err)
+		assert.Equal(t, result.Data, reversed.Data)
+	})
+
+	t.Run("aligns by index name not position", func(t *testing.T) {
+		col := &Variable{Name: "col", Indices: []string{"j"}, Shape: []int{4}, Data: []float64{1, 2, 3, 4}}
+		result, err := f.AddBroadcast(ctx, m, col)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"i", "j"}, result.Indices)
+		assert.Equal(t, []float64{1, 3, 5, 7, 5, 7, 9, 11, 9, 11, 13, 15}, result.Data)
+	})
+
+	t.Run("error on incompatible shapes", func(t *testing.T) {
+		bad := &Variable{Name: "bad", Indices: []string{"i", "j"}, Shape: []int{3, 2}, Data: make([]float64, 6)}
+		_, err := f.AddBroadcast(ctx, m, bad)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "incompatible dimensions 4 and 2")
+	})
+}
+
+func TestFramework_Normalize(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	t.Run("normalize full tensor", func(t *testing.T) {
+		v := &Variable{Name: "p", Indices: []string{"s"}, Shape: []int{3}, Data: []float64{1, 1, 2}, Type: ProbabilisticType}
+		result, err := f.Normalize(ctx, v)
+		require.NoError(t, err)
+		assert.Equal(t, []float64{0.25, 0.25, 0.5}, result.Data)
+		assert.Equal(t, []float64{1, 1, 2}, v.Data)
+	})
+
+	t.Run("normalize along a named axis", func(t *testing.T) {
+		v := &Variable{Name: "p", Indices: []string{"i", "j"}, Shape: []int{2, 2}, Data: []float64{1, 3, 2, 2}, Type: ProbabilisticType}
+		result, err := f.Normalize(ctx, v, WithAxis("j"))
+		require.NoError(t, err)
+		assert.Equal(t, []float64{0.25, 0.75, 0.5, 0.5}, result.Data)
+
+		result, err = f.Normalize(ctx, v, WithAxis("i"))
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, []float64{1.0 / 3, 0.6, 2.0 / 3, 0.4}, result.Data, 1e-9)
+	})
+
+	t.Run("error on all-zero data", func(t *testing.T) {
+		v := &Variable{Name: "p", Indices: []string{"s"}, Shape: []int{3}, Data: []float64{0, 0, 0}, Type: ProbabilisticType}
+		_, err := f.Normalize(ctx, v)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sums to zero")
+	})
+
+	t.Run("error on unknown axis", func(t *testing.T) {
+		v := &Variable{Name: "p", Indices: []string{"s"}, Shape: []int{2}, Data: []float64{1, 1}}
+		_, err := f.Normalize(ctx, v, WithAxis("x"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "index x not found")
+	})
+
+	t.Run("register accepts negative probabilities by default", func(t *testing.T) {
+		v := &Variable{Name: "p", Indices: []string{"s"}, Shape: []int{2}, Data: []float64{0.5, -0.5}, Type: ProbabilisticType}
+		require.NoError(t, f.RegisterVariable(ctx, v))
+	})
+
+	t.Run("strict register rejects negative probabilities", func(t *testing.T) {
+		strict, err := NewFramework(ctx, WithStrictProbabilities())
+		require.NoError(t, err)
+		v := &Variable{Name: "p", Indices: []string{"s"}, Shape: []int{2}, Data: []float64{0.5, -0.5}, Type: ProbabilisticType}
+		err = strict.RegisterVariable(ctx, v)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		assert.Contains(t, err.Error(), "negative entry")
+		err = strict.RegisterVariableStrict(ctx, v)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "negative entry")
+
+		v.Type = NeuralType
+		require.NoError(t, strict.RegisterVariable(ctx, v))
+	})
+}
+
+func TestFramework_Softmax(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	t.Run("sums to one along axis", func(t *testing.T) {
+		v := &Variable{Name: "logits", Indices: []string{"i", "j"}, Shape: []int{2, 3}, Data: []float64{1, 2, 3, -1, 0, 1}, Type: NeuralType}
+		for axis, other := range map[string]string{"i": "j", "j": "i"} {
+			result, err := f.Softmax(ctx, v, axis)
+			require.NoError(t, err)
+			assert.Equal(t, v.Shape, result.Shape)
+
+			summed, err := f.Project(ctx, result, []string{other})
+			require.NoError(t, err)
+			for _, s := range summed.Data {
+				assert.InDelta(t, 1.0, s, 1e-12)
+			}
+		}
+		assert.Equal(t, []float64{1, 2, 3, -1, 0, 1}, v.Data)
+	})
+
+	t.Run("large values do not overflow", func(t *testing.T) {
+		v := &Variable{Name: "logits", Indices: []string{"i"}, Shape: []int{3}, Data: []float64{1000, 1000, 1001}, Type: NeuralType}
+		result, err := f.Softmax(ctx, v, "i")
+		require.NoError(t, err)
+		sum := 0.0
+		for _, x := range result.Data {
+			assert.False(t, math.IsNaN(x) || math.IsInf(x, 0))
+			sum += x
+		}
+		assert.InDelta(t, 1.0, sum, 1e-12)
+		assert.InDelta(t, result.Data[0], result.Data[1], 1e-12)
+		assert.Greater(t, result.Data[2], result.Data[0])
+	})
+
+	t.Run("unknown axis", func(t *testing.T) {
+		v := &Variable{Name: "logits", Indices: []string{"i"}, Shape: []int{2}, Data: []float64{1, 2}}
+		_, err := f.Softmax(ctx, v, "k")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "index k not found")
+	})
+
+	t.Run("nil data", func(t *testing.T) {
+		v := &Variable{Name: "logits", Indices: []string{"i"}, Shape: []int{2}}
+		_, err := f.Softmax(ctx, v, "i")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has no data")
+	})
+}
+
+func TestFramework_RegisterVariableStrict(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		second    *Variable
+		wantErr   bool
+		wantIsErr errors.Code
+	}{
+		{
+			name:   "identical re-register",
+			second: &Variable{Name: "w", Indices: []string{"i"}, Shape: []int{2}, Data: []float64{3, 4}, Type: NeuralType},
+		},
+		{
+			name:      "different shape",
+			second:    &Variable{Name: "w", Indices: []string{"i"}, Shape: []int{3}, Type: NeuralType},
+			wantErr:   true,
+			wantIsErr: errors.NotUnique,
+		},
+		{
+			name:      "different type",
+			second:    &Variable{Name: "w", Indices: []string{"i"}, Shape: []int{2}, Type: SymbolicType},
+			wantErr:   true,
+			wantIsErr: errors.NotUnique,
+		},
+		{
+			name:      "invalid variable",
+			second:    &Variable{Indices: []string{"i"}, Shape: []int{2}},
+			wantErr:   true,
+			wantIsErr: errors.InvalidParameter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFramework(ctx)
+			require.NoError(t, err)
+			first := &Variable{Name: "w", Indices: []string{"i"}, Shape: []int{2}, Data: []float64{1, 2}, Type: NeuralType}
+			require.NoError(t, f.RegisterVariableStrict(ctx, first))
+
+			err = f.RegisterVariableStrict(ctx, tt.second)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Truef(t, errors.Match(errors.T(tt.wantIsErr), err), "Unexpected error %s", err)
+				stored, err := f.GetVariable(ctx, "w")
+				require.NoError(t, err)
+				assert.Same(t, first, stored)
+				return
+			}
+			require.NoError(t, err)
+			stored, err := f.GetVariable(ctx, "w")
+			require.NoError(t, err)
+			assert.Same(t, tt.second, stored)
+		})
+	}
+
+	t.Run("lenient register still overwrites", func(t *testing.T) {
+		f, err := NewFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, f.RegisterVariableStrict(ctx, &Variable{Name: "w", Shape: []int{2}, Indices: []string{"i"}}))
+		require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "w", Shape: []int{3}, Indices: []string{"i"}}))
+		stored, err := f.GetVariable(ctx, "w")
+		require.NoError(t, err)
+		assert.Equal(t, []int{3}, stored.Shape)
+	})
+}
+
+func TestFramework_ListVariablesAndEquations(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	assert.Empty(t, f.ListVariables(ctx))
+	assert.Empty(t, f.ListEquations(ctx))
+
+	for _, name := range []string{"c", "a", "b"} {
+		require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: name, Indices: []string{"i"}, Shape: []int{2}, Data: []float64{1, 2}}))
+	}
+	for _, right := range []string{"a_i * b_i", "b_i * c_i", "a_i * c_i"} {
+		require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "d", Indices: []string{"i"}, Shape: []int{2}}, Right: right}))
+	}
+
+	vars := f.ListVariables(ctx)
+	require.Len(t, vars, 3)
+	assert.Equal(t, "a", vars[0].Name)
+	assert.Equal(t, "b", vars[1].Name)
+	assert.Equal(t, "c", vars[2].Name)
+
+	vars[0].Data[0] = 100
+	stored, err := f.GetVariable(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 2}, stored.Data)
+
+	eqs := f.ListEquations(ctx)
+	require.Len(t, eqs, 3)
+	assert.Equal(t, "a_i * b_i", eqs[0].Right)
+	assert.Equal(t, "b_i * c_i", eqs[1].Right)
+	assert.Equal(t, "a_i * c_i", eqs[2].Right)
+
+	eqs[0].Left.Indices[0] = "x"
+	eqs[0].Left.Shape[0] = 100
+	eqs = f.GetEquations(ctx, "d")
+	require.Len(t, eqs, 3)
+	eqs[1].Left.Indices[0] = "y"
+	for _, eq := range f.ListEquations(ctx) {
+		assert.Equal(t, []string{"i"}, eq.Left.Indices)
+		assert.Equal(t, []int{2}, eq.Left.Shape)
+	}
+}
+
+func TestFramework_Outer(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	a := &Variable{Name: "a", Indices: []string{"i"}, Shape: []int{2}, Data: []float64{1, 2}, Type: NeuralType}
+	b := &Variable{Name: "b", Indices: []string{"j"}, Shape: []int{3}, Data: []float64{3, 4, 5}, Type: NeuralType}
+
+	t.Run("vector outer product", func(t *testing.T) {
+		result, err := f.Outer(ctx, a, b)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"i", "j"}, result.Indices)
+		assert.Equal(t, []int{2, 3}, result.Shape)
+		assert.Equal(t, []float64{3, 4, 5, 6, 8, 10}, result.Data)
+		assert.Equal(t, NeuralType, result.Type)
+	})
+
+	tests := []struct {
+		name    string
+		v1, v2  *Variable
+		wantErr string
+	}{
+		{
+			name:    "nil variable",
+			v1:      a,
+			wantErr: "one or both variables are nil",
+		},
+		{
+			name:    "not rank-1",
+			v1:      &Variable{Name: "m", Indices: []string{"i", "k"}, Shape: []int{1, 2}, Data: []float64{1, 2}},
+			v2:      b,
+			wantErr: "variable m is not rank-1",
+		},
+		{
+			name:    "nil data",
+			v1:      a,
+			v2:      &Variable{Name: "e", Indices: []string{"j"}, Shape: []int{3}},
+			wantErr: "variable e has no data",
+		},
+		{
+			name:    "shared index",
+			v1:      a,
+			v2:      &Variable{Name: "c", Indices: []string{"i"}, Shape: []int{1}, Data: []float64{1}},
+			wantErr: "both variables use index i",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.Outer(ctx, tt.v1, tt.v2)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestFramework_Slice(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	m := &Variable{Name: "m", Indices: []string{"i", "j"}, Shape: []int{4, 2}, Data: []float64{0, 1, 2, 3, 4, 5, 6, 7}}
+
+	t.Run("rows", func(t *testing.T) {
+		result, err := f.Slice(ctx, m, "i", 1, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"i", "j"}, result.Indices)
+		assert.Equal(t, []int{2, 2}, result.Shape)
+		assert.Equal(t, []float64{2, 3, 4, 5}, result.Data)
+	})
+
+	t.Run("columns", func(t *testing.T) {
+		result, err := f.Slice(ctx, m, "j", 1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []int{4, 1}, result.Shape)
+		assert.Equal(t, []float64{1, 3, 5, 7}, result.Data)
+	})
+
+	tests := []struct {
+		name       string
+		index      string
+		start, end int
+		wantErr    string
+	}{
+		{name: "end past dimension", index: "i", start: 2, end: 5, wantErr: "out of bounds"},
+		{name: "negative start", index: "i", start: -1, end: 2, wantErr: "out of bounds"},
+		{name: "empty range", index: "j", start: 1, end: 1, wantErr: "out of bounds"},
+		{name: "unknown index", index: "k", start: 0, end: 1, wantErr: "index k not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.Slice(ctx, m, tt.index, tt.start, tt.end)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestVariable_Clone(t *testing.T) {
+	orig := &Variable{
+		Name:    "w",
+		Indices: []string{"i", "j"},
+		Shape:   []int{1, 2},
+		Data:    []float64{1, 2},
+		Type:    NeuralType,
+	}
+
+	clone := orig.Clone()
+	require.Equal(t, orig, clone)
+
+	clone.Name = "other"
+	clone.Indices[0] = "k"
+	clone.Shape[1] = 3
+	clone.Data[0] = 9
+	clone.Type = SymbolicType
+
+	assert.Equal(t, &Variable{
+		Name:    "w",
+		Indices: []string{"i", "j"},
+		Shape:   []int{1, 2},
+		Data:    []float64{1, 2},
+		Type:    NeuralType,
+	}, orig)
+
+	var nilVar *Variable
+	assert.Nil(t, nilVar.Clone())
+}
+
+func TestFramework_Int64DType(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	big := int64(1) << 60
+	a := &Variable{Name: "a", Indices: []string{"i"}, Shape: []int{3}, IntData: []int64{big, 2, 3}, DType: Int64, Type: SymbolicType}
+	b := &Variable{Name: "b", Indices: []string{"i"}, Shape: []int{3}, IntData: []int64{1, 4, 5}, DType: Int64, Type: SymbolicType}
+
+	t.Run("add", func(t *testing.T) {
+		result, err := f.Add(ctx, a, b)
+		require.NoError(t, err)
+		assert.Equal(t, Int64, result.DType)
+		assert.Equal(t, []int64{big + 1, 6, 8}, result.IntData)
+		assert.Nil(t, result.Data)
+	})
+
+	t.Run("multiply", func(t *testing.T) {
+		result, err := f.Multiply(ctx, a, b)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{big, 8, 15}, result.IntData)
+	})
+
+	t.Run("evaluate", func(t *testing.T) {
+		require.NoError(t, f.RegisterVariable(ctx, a))
+		result, err := f.Evaluate(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, Int64, result.DType)
+		assert.Equal(t, a.IntData, result.IntData)
+		result.IntData[0] = 0
+		assert.Equal(t, big, a.IntData[0])
+	})
+
+	t.Run("join", func(t *testing.T) {
+		c := &Variable{Name: "c", Indices: []string{"i"}, Shape: []int{3}, Data: []float64{1, 2, 3}}
+		for _, pair := range [][2]*Variable{{a, b}, {a, c}, {c, a}} {
+			_, err := f.Join(ctx, pair[0], pair[1])
+			require.Error(t, err)
+			assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+			assert.Contains(t, err.Error(), "has unsupported dtype int64")
+		}
+
+		// a symbolic int64 operand is rejected too
+		_, err := f.Join(ctx, &Variable{Name: "s", Indices: []string{"i"}, DType: Int64}, c)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "variable s has unsupported dtype int64")
+	})
+
+	t.Run("mixed dtypes", func(t *testing.T) {
+		c := &Variable{Name: "c", Indices: []string{"i"}, Shape: []int{3}, Data: []float64{1, 2, 3}}
+		_, err := f.Add(ctx, a, c)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "dtype mismatch: int64 and float64")
+	})
+
+	t.Run("register validates int data", func(t *testing.T) {
+		err := f.RegisterVariable(ctx, &Variable{Name: "d", Shape: []int{2}, Indices: []string{"i"}, IntData: []int64{1}, DType: Int64})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "data length 1 does not match shape [2]")
+
+		err = f.RegisterVariable(ctx, &Variable{Name: "d", DType: "int8"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported dtype int8")
+	})
+}
+
+func TestFramework_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("two-stage chain", func(t *testing.T) {
+		f, err := NewFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "A", Indices: []string{"i", "j"}, Shape: []int{2, 2}, Data: []float64{1, 2, 3, 4}}))
+		require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "B", Indices: []string{"j", "k"}, Shape: []int{2, 2}, Data: []float64{1, 0, 0, 1}}))
+		require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "D", Indices: []string{"k", "l"}, Shape: []int{2, 1}, Data: []float64{1, 1}}))
+
+		// define the dependent equation first to exercise ordering
+		require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "E", Indices: []string{"i", "l"}}, Right: "C_ik * D_kl"}))
+		require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "C", Indices: []string{"i", "k"}}, Right: "A_ij * B_jk"}))
+
+		results, err := f.EvaluateAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, []float64{1, 2, 3, 4}, results["C"].Data)
+		assert.Equal(t, []int{2, 1}, results["E"].Shape)
+		assert.Equal(t, []float64{3, 7}, results["E"].Data)
+
+		stored, err := f.GetVariable(ctx, "E")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{3, 7}, stored.Data)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		f, err := NewFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "X"}, Right: "Y_ij * Z_jk"}))
+		require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "Y"}, Right: "X_ij * Z_jk"}))
+
+		_, err = f.EvaluateAll(ctx)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.CycleFound), err))
+		assert.Contains(t, err.Error(), "X -> Y -> X")
+	})
+
+	t.Run("duplicate definition", func(t *testing.T) {
+		f, err := NewFramework(ctx)
+		require.NoError(t, err)
+		require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "X"}, Right: "A_ij * B_jk"}))
+		require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "X"}, Right: "C_ij * D_jk"}))
+
+		_, err = f.EvaluateAll(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "defined by more than one equation")
+	})
+}
+
+func TestFramework_JoinContracts(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	a := &Variable{Name: "A", Indices: []string{"i", "j"}, Shape: []int{2, 3}, Data: []float64{1, 2, 3, 4, 5, 6}}
+	b := &Variable{Name: "B", Indices: []string{"j", "k"}, Shape: []int{3, 2}, Data: []float64{1, 0, 0, 1, 1, 1}}
+
+	result, err := f.Join(ctx, a, b)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i", "k"}, result.Indices)
+	assert.Equal(t, []int{2, 2}, result.Shape)
+	assert.Equal(t, []float64{4, 5, 10, 11}, result.Data)
+
+	t.Run("unshaped operands", func(t *testing.T) {
+		_, err := f.Join(ctx, &Variable{Name: "A", Indices: []string{"i", "j"}}, &Variable{Name: "B", Indices: []string{"j", "k"}})
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		assert.Contains(t, err.Error(), "cannot infer dimensions of indices [i k]: neither A nor B has a shape for them")
+
+		// a fully contracted join has no dimensions to infer
+		result, err := f.Join(ctx, &Variable{Name: "A", Indices: []string{"i", "j"}}, &Variable{Name: "B", Indices: []string{"j", "i"}})
+		require.NoError(t, err)
+		assert.Empty(t, result.Indices)
+		assert.Equal(t, []int{}, result.Shape)
+		assert.Nil(t, result.Data)
+	})
+
+	t.Run("infers shape from the shaped operand", func(t *testing.T) {
+		shapeless := &Variable{Name: "S", Indices: []string{"j"}}
+		result, err := f.Join(ctx, shapeless, b)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"k"}, result.Indices)
+		assert.Equal(t, []int{2}, result.Shape)
+		assert.Nil(t, result.Data)
+
+		shapeless = &Variable{Name: "S", Indices: []string{"i"}}
+		result, err = f.Join(ctx, a, shapeless)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"j"}, result.Indices)
+		assert.Equal(t, []int{3}, result.Shape)
+	})
+
+	t.Run("unknown dimensions", func(t *testing.T) {
+		shapeless := &Variable{Name: "S", Indices: []string{"h", "i", "j"}}
+		_, err := f.Join(ctx, shapeless, b)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		assert.Contains(t, err.Error(), "cannot infer dimensions of indices [h i]: neither S nor B has a shape for them")
+	})
+
+	t.Run("shaped operand without data checks shared dimensions", func(t *testing.T) {
+		shaped := &Variable{Name: "S", Indices: []string{"j"}, Shape: []int{4}}
+		_, err := f.Join(ctx, shaped, b)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "index j has mismatched dimensions 4 and 3")
+	})
+
+	t.Run("mismatched shared dimension", func(t *testing.T) {
+		c := &Variable{Name: "C", Indices: []string{"j", "k"}, Shape: []int{2, 1}, Data: []float64{1, 1}}
+		_, err := f.Join(ctx, a, c)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "index j has mismatched dimensions 3 and 2")
+	})
+}
+
+func TestFramework_ErrorCodes(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	_, err = f.Evaluate(ctx, "missing")
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+
+	_, err = f.EvaluateEquation(ctx, &TensorEquation{Left: Variable{Name: "C", Indices: []string{"i"}}, Right: "A_ij * B_j"})
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+
+	err = f.RegisterVariable(ctx, nil)
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+}
+
+func TestFramework_Trace(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	identity := &Variable{
+		Name:    "I",
+		Indices: []string{"i", "j"},
+		Shape:   []int{3, 3},
+		Data:    []float64{1, 0, 0, 0, 1, 0, 0, 0, 1},
+	}
+	result, err := f.Trace(ctx, identity)
+	require.NoError(t, err)
+	assert.Empty(t, result.Indices)
+	assert.Empty(t, result.Shape)
+	assert.Equal(t, []float64{3}, result.Data)
+
+	m := &Variable{Name: "M", Indices: []string{"i", "j"}, Shape: []int{2, 2}, Data: []float64{1, 2, 3, 4}}
+	result, err = f.Trace(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{5}, result.Data)
+
+	tests := []struct {
+		name    string
+		v       *Variable
+		wantErr string
+	}{
+		{
+			name:    "nil",
+			wantErr: "variable is nil",
+		},
+		{
+			name:    "non-square",
+			v:       &Variable{Name: "R", Indices: []string{"i", "j"}, Shape: []int{2, 3}, Data: make([]float64, 6)},
+			wantErr: "variable R is not square: shape [2 3]",
+		},
+		{
+			name:    "rank 1",
+			v:       &Variable{Name: "V", Indices: []string{"i"}, Shape: []int{3}, Data: make([]float64, 3)},
+			wantErr: "variable V has rank 1, want 2",
+		},
+		{
+			name:    "no data",
+			v:       &Variable{Name: "S", Indices: []string{"i", "j"}, Shape: []int{2, 2}},
+			wantErr: "variable S has no data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.Trace(ctx, tt.v)
+			require.Error(t, err)
+			assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestFramework_MatMul(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	a := &Variable{
+		Name:    "A",
+		Indices: []string{"user", "role"},
+		Shape:   []int{2, 3},
+		Data:    []float64{1, 2, 3, 4, 5, 6},
+		Type:    SymbolicType,
+	}
+	b := &Variable{
+		Name:    "B",
+		Indices: []string{"role", "perm"},
+		Shape:   []int{3, 2},
+		Data:    []float64{7, 8, 9, 10, 11, 12},
+		Type:    SymbolicType,
+	}
+	result, err := f.MatMul(ctx, a, b)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i", "k"}, result.Indices)
+	assert.Equal(t, []int{2, 2}, result.Shape)
+	assert.Equal(t, []float64{58, 64, 139, 154}, result.Data)
+	assert.Equal(t, SymbolicType, result.Type)
+
+	// agrees with Join when the index names line up
+	joined, err := f.Join(ctx, a, b)
+	require.NoError(t, err)
+	assert.Equal(t, joined.Data, result.Data)
+
+	tests := []struct {
+		name    string
+		a, b    *Variable
+		wantErr string
+	}{
+		{
+			name:    "dimension mismatch",
+			a:       a,
+			b:       a,
+			wantErr: "cannot multiply A of shape [2 3] by A of shape [2 3]",
+		},
+		{
+			name:    "rank 1",
+			a:       a,
+			b:       &Variable{Name: "V", Indices: []string{"j"}, Shape: []int{3}, Data: make([]float64, 3)},
+			wantErr: "variable V has rank 1, want 2",
+		},
+		{
+			name:    "nil",
+			b:       b,
+			wantErr: "variable is nil",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.MatMul(ctx, tt.a, tt.b)
+			require.Error(t, err)
+			assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestFramework_NormL2(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	v := &Variable{Name: "v", Indices: []string{"i"}, Shape: []int{2}, Data: []float64{3, 4}, Type: NeuralType}
+	norm, err := f.Norm(ctx, v)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, norm)
+
+	unit, err := f.NormalizeL2(ctx, v)
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.6, 0.8}, unit.Data, 1e-12)
+	assert.Equal(t, v.Indices, unit.Indices)
+	assert.Equal(t, v.Shape, unit.Shape)
+	assert.Equal(t, NeuralType, unit.Type)
+	assert.Equal(t, []float64{3, 4}, v.Data)
+	norm, err = f.Norm(ctx, unit)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, norm, 1e-12)
+
+	m := &Variable{Name: "m", Indices: []string{"i", "j"}, Shape: []int{2, 2}, Data: []float64{1, -1, 1, -1}}
+	norm, err = f.Norm(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, norm)
+
+	t.Run("nil data", func(t *testing.T) {
+		empty := &Variable{Name: "e", Indices: []string{"i"}, Shape: []int{2}}
+		_, err := f.Norm(ctx, empty)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "variable e has no data")
+		_, err = f.NormalizeL2(ctx, empty)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "variable e has no data")
+		_, err = f.Norm(ctx, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("zero vector", func(t *testing.T) {
+		zero := &Variable{Name: "z", Indices: []string{"i"}, Shape: []int{3}, Data: make([]float64, 3)}
+		norm, err := f.Norm(ctx, zero)
+		require.NoError(t, err)
+		assert.Zero(t, norm)
+		_, err = f.NormalizeL2(ctx, zero)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		assert.Contains(t, err.Error(), "cannot normalize zero tensor z")
+	})
+}
+
+func TestFramework_RenameVariable(t *testing.T) {
+	ctx := context.Background()
+
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "A"}))
+	require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "B"}))
+	require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "C"}))
+	require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "C"}, Right: "A_ij * B_jk"}))
+
+	require.NoError(t, f.RenameVariable(ctx, "A", "X"))
+	require.NoError(t, f.RenameVariable(ctx, "C", "Z"))
+
+	assert.NotContains(t, f.Variables, "A")
+	assert.Equal(t, "X", f.Variables["X"].Name)
+	require.Len(t, f.Equations, 1)
+	assert.Equal(t, "Z", f.Equations[0].Left.Name)
+	assert.Equal(t, "X_ij * B_jk", f.Equations[0].Right)
+
+	err = f.RenameVariable(ctx, "X", "B")
+	assert.True(t, errors.Match(errors.T(errors.NotUnique), err))
+	err = f.RenameVariable(ctx, "missing", "Y")
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+}
+
+func TestFramework_RegisterVariableIndicesShape(t *testing.T) {
+	ctx := context.Background()
+
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "M", Indices: []string{"i", "j"}, Shape: []int{2, 3}, Data: make([]float64, 6)}))
+	require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "Unshaped", Indices: []string{"i", "j"}}))
+	require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "Unindexed", Shape: []int{5}}))
+
+	err = f.RegisterVariable(ctx, &Variable{Name: "Bad", Indices: []string{"i", "j"}, Shape: []int{5}})
+	require.Error(t, err)
+	assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	assert.Contains(t, err.Error(), "has 2 indices")
+	assert.NotContains(t, f.Variables, "Bad")
+
+	err = f.RegisterVariableStrict(ctx, &Variable{Name: "Bad", Indices: []string{"i"}, Shape: []int{2, 2}})
+	assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+}
+
+func TestFramework_EvaluateCached(t *testing.T) {
+	ctx := context.Background()
+
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "A", Indices: []string{"i"}, Shape: []int{2}, Data: []float64{1, 2}}))
+
+	first, err := f.EvaluateCached(ctx, "A")
+	require.NoError(t, err)
+	second, err := f.EvaluateCached(ctx, "A")
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, []float64{1, 2}, first.Data)
+
+	// The snapshot is not the stored variable, and Evaluate still copies.
+	assert.NotSame(t, f.Variables["A"], first)
+	copied, err := f.Evaluate(ctx, "A")
+	require.NoError(t, err)
+	assert.NotSame(t, first, copied)
+
+	// Re-registering invalidates the snapshot without changing the old one.
+	require.NoError(t, f.RegisterVariable(ctx, &Variable{Name: "A", Indices: []string{"i"}, Shape: []int{2}, Data: []float64{3, 4}}))
+	third, err := f.EvaluateCached(ctx, "A")
+	require.NoError(t, err)
+	assert.NotSame(t, first, third)
+	assert.Equal(t, []float64{3, 4}, third.Data)
+	assert.Equal(t, []float64{1, 2}, first.Data)
+
+	require.NoError(t, f.DeleteVariable(ctx, "A"))
+	_, err = f.EvaluateCached(ctx, "A")
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+}
+
+func TestFramework_GetAndRemoveEquations(t *testing.T) {
+	ctx := context.Background()
+
+	f, err := NewFramework(ctx)
+	require.NoError(t, err)
+	require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "C"}, Right: "A_ij * B_jk"}))
+	require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "D"}, Right: "C_ik * E_kl"}))
+	require.NoError(t, f.DefineEquation(ctx, &TensorEquation{Left: Variable{Name: "C"}, Right: "X_ij * Y_jk"}))
+
+	equations := f.GetEquations(ctx, "C")
+	require.Len(t, equations, 2)
+	assert.Equal(t, "A_ij * B_jk", equations[0].Right)
+	assert.Equal(t, "X_ij * Y_jk", equations[1].Right)
+	assert.Empty(t, f.GetEquations(ctx, "missing"))
+
+	// A returned copy identifies the equation to remove.
+	require.NoError(t, f.RemoveEquation(ctx, equations[0]))
+	remaining := f.GetEquations(ctx, "C")
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "X_ij * Y_jk", remaining[0].Right)
+	assert.Len(t, f.ListEquations(ctx), 2)
+
+	err = f.RemoveEquation(ctx, equations[0])
+	assert.True(t, errors.Match(errors.T(errors.NotFound), err))
+	err = f.RemoveEquation(ctx, nil)
+	assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+}